@@ -0,0 +1,55 @@
+package gadb
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// abis64 are the 64-bit ABIs Android supports.
+var abis64 = []string{"arm64-v8a", "x86_64", "riscv64", "mips64"}
+
+// ABIs returns the native code ABIs the device supports, most preferred first, such as
+// ["arm64-v8a", "armeabi-v7a", "armeabi"], for picking the native binary or split APK to push.
+func (d Device) ABIs() ([]string, error) {
+	var err error
+	abis := abisFromProps(func(key string) string {
+		if err != nil {
+			return ""
+		}
+		var value string
+		value, err = d.GetProp(key)
+		return value
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(abis) == 0 {
+		return nil, errors.New("adb: can't read ro.product.cpu.abilist")
+	}
+	return abis, nil
+}
+
+// Is64Bit reports whether the device supports a 64-bit ABI.
+func (d Device) Is64Bit() (bool, error) {
+	abis, err := d.ABIs()
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(abis, func(abi string) bool { return slices.Contains(abis64, abi) }), nil
+}
+
+// abisFromProps returns ro.product.cpu.abilist, which Android 5.0 introduced, or before it
+// ro.product.cpu.abi followed by ro.product.cpu.abi2.
+func abisFromProps(getProp func(key string) string) []string {
+	if abilist := strings.TrimSpace(getProp("ro.product.cpu.abilist")); abilist != "" {
+		return strings.Split(abilist, ",")
+	}
+	var abis []string
+	for _, key := range []string{"ro.product.cpu.abi", "ro.product.cpu.abi2"} {
+		if abi := strings.TrimSpace(getProp(key)); abi != "" {
+			abis = append(abis, abi)
+		}
+	}
+	return abis
+}
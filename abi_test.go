@@ -0,0 +1,22 @@
+package gadb
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_abisFromProps(t *testing.T) {
+	for _, tt := range []struct {
+		props map[string]string
+		want  []string
+	}{
+		{map[string]string{"ro.product.cpu.abilist": "arm64-v8a,armeabi-v7a,armeabi", "ro.product.cpu.abi": "arm64-v8a"}, []string{"arm64-v8a", "armeabi-v7a", "armeabi"}},
+		{map[string]string{"ro.product.cpu.abi": "armeabi-v7a", "ro.product.cpu.abi2": "armeabi"}, []string{"armeabi-v7a", "armeabi"}},
+		{map[string]string{"ro.product.cpu.abi": "x86"}, []string{"x86"}},
+		{map[string]string{}, nil},
+	} {
+		if got := abisFromProps(func(key string) string { return tt.props[key] }); !slices.Equal(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.props, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gadb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sdkCmdAirplaneMode is the first API level (Android 10) with `cmd connectivity airplane-mode`.
+const sdkCmdAirplaneMode = 29
+
+// airplaneModeAction is the broadcast that makes the radios follow airplane_mode_on.
+const airplaneModeAction = "android.intent.action.AIRPLANE_MODE"
+
+// AirplaneMode reports whether airplane mode is on, from the airplane_mode_on global setting.
+func (d Device) AirplaneMode() (bool, error) {
+	return d.Settings(SettingsGlobal).GetBool("airplane_mode_on", false)
+}
+
+// SetAirplaneMode turns airplane mode on or off and reads the setting back to verify it.
+// Since Android 10 it uses `cmd connectivity airplane-mode`; before, it writes the
+// airplane_mode_on setting and broadcasts the change, which the shell user may only do up to
+// Android 6, so Android 7 to 9 need root.
+func (d Device) SetAirplaneMode(on bool) error {
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return err
+	}
+	if cmd := airplaneModeCommand(sdk, on); cmd != "" {
+		output, err := d.RunShellCommand(cmd)
+		if err != nil {
+			return err
+		}
+		if output = strings.TrimSpace(output); output != "" {
+			return fmt.Errorf("adb airplane mode: %s", output)
+		}
+	} else {
+		if err = d.Settings(SettingsGlobal).PutBool("airplane_mode_on", on); err != nil {
+			return fmt.Errorf("adb airplane mode: %w", err)
+		}
+		if _, err = d.SendBroadcast(airplaneModeIntent(on)); err != nil {
+			return fmt.Errorf("adb airplane mode: %w", err)
+		}
+	}
+
+	got, err := d.AirplaneMode()
+	if err != nil {
+		return err
+	}
+	if got != on {
+		return fmt.Errorf("adb airplane mode: airplane_mode_on is %t after setting it to %t", got, on)
+	}
+	return nil
+}
+
+// airplaneModeCommand returns the cmd connectivity command setting airplane mode, or "" on
+// API levels without it.
+func airplaneModeCommand(sdk int, on bool) string {
+	if sdk < sdkCmdAirplaneMode {
+		return ""
+	}
+	if on {
+		return "cmd connectivity airplane-mode enable"
+	}
+	return "cmd connectivity airplane-mode disable"
+}
+
+// airplaneModeIntent is the broadcast announcing a change of airplane_mode_on.
+func airplaneModeIntent(on bool) Intent {
+	return Intent{Action: airplaneModeAction, BoolExtras: map[string]bool{"state": on}}
+}
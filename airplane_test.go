@@ -0,0 +1,29 @@
+package gadb
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_airplaneModeCommand(t *testing.T) {
+	for _, tt := range []struct {
+		sdk  int
+		on   bool
+		want string
+	}{
+		{23, true, ""},
+		{28, false, ""},
+		{29, true, "cmd connectivity airplane-mode enable"},
+		{34, false, "cmd connectivity airplane-mode disable"},
+	} {
+		if cmd := airplaneModeCommand(tt.sdk, tt.on); cmd != tt.want {
+			t.Errorf("API %d, %t: got %q, want %q", tt.sdk, tt.on, cmd, tt.want)
+		}
+	}
+}
+
+func Test_airplaneModeIntent(t *testing.T) {
+	if got := strings.Join(airplaneModeIntent(true).Args(), " "); got != "-a 'android.intent.action.AIRPLANE_MODE' --ez 'state' 'true'" {
+		t.Fatalf("unexpected arguments %s", got)
+	}
+}
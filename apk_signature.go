@@ -0,0 +1,228 @@
+package gadb
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNoAPKSignature is returned when an APK carries no signature gadb can read.
+var ErrNoAPKSignature = errors.New("apk: no signing certificate found")
+
+const (
+	apkSigBlockMagic = "APK Sig Block 42"
+	apkSigV2BlockID  = 0x7109871a
+	apkSigV3BlockID  = 0xf05368c0
+	zipEOCDSignature = 0x06054b50
+	zipEOCDSize      = 22
+)
+
+// PackageSignature returns the lowercase hex SHA-256 digest of the certificate that signed
+// the installed package pkg, the same value apksigner and the Play Console show. It reads the
+// signature straight from the base APK on the device, fetching only the bytes it needs. If the
+// APK can't be read in full, the error wraps io.ErrUnexpectedEOF or the read failure.
+func (d Device) PackageSignature(pkg string) (string, error) {
+	paths, err := d.APKPaths(pkg)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := d.OpenFile(paths[0])
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	digest, err := APKSignerDigest(f, f.Size())
+	if err != nil {
+		return "", fmt.Errorf("adb package signature %s: %w", pkg, err)
+	}
+	return digest, nil
+}
+
+// APKSignerDigest returns the hex SHA-256 digest of the first signer's certificate of the APK
+// read from r. It understands APK signature schemes v3 and v2 and falls back to the v1 (JAR)
+// signature.
+func APKSignerDigest(r io.ReaderAt, size int64) (string, error) {
+	cert, err := apkSigningBlockCert(r, size)
+	if errors.Is(err, ErrNoAPKSignature) {
+		cert, err = jarSigningCert(r, size)
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(cert)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// apkSigningBlockCert returns the first certificate of the v3 or v2 scheme in the APK
+// Signing Block, which sits immediately before the zip central directory.
+func apkSigningBlockCert(r io.ReaderAt, size int64) ([]byte, error) {
+	cdOffset, err := zipCentralDirectoryOffset(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if cdOffset < 32 {
+		return nil, ErrNoAPKSignature
+	}
+
+	footer := make([]byte, 24)
+	if _, err = r.ReadAt(footer, cdOffset-24); err != nil {
+		return nil, err
+	}
+	if string(footer[8:]) != apkSigBlockMagic {
+		return nil, ErrNoAPKSignature
+	}
+	blockSize := int64(binary.LittleEndian.Uint64(footer))
+	if blockSize < 24 || blockSize > cdOffset-8 {
+		return nil, errors.New("apk: malformed signing block size")
+	}
+
+	pairs := make([]byte, blockSize-24)
+	if _, err = r.ReadAt(pairs, cdOffset-blockSize); err != nil {
+		return nil, err
+	}
+
+	blocks := map[uint32][]byte{}
+	for len(pairs) > 0 {
+		if len(pairs) < 12 {
+			return nil, errors.New("apk: truncated signing block entry")
+		}
+		n := binary.LittleEndian.Uint64(pairs)
+		if n < 4 || n > uint64(len(pairs)-8) {
+			return nil, errors.New("apk: malformed signing block entry")
+		}
+		blocks[binary.LittleEndian.Uint32(pairs[8:])] = pairs[12 : 8+n]
+		pairs = pairs[8+n:]
+	}
+
+	for _, id := range []uint32{apkSigV3BlockID, apkSigV2BlockID} {
+		if value, ok := blocks[id]; ok {
+			return firstSignerCert(value)
+		}
+	}
+	return nil, ErrNoAPKSignature
+}
+
+// firstSignerCert walks signers -> signer -> signed data -> certificates, all of them
+// uint32-length-prefixed, and returns the first certificate.
+func firstSignerCert(value []byte) (cert []byte, err error) {
+	var signers, signer, signedData, certs []byte
+	if signers, _, err = lengthPrefixed(value); err != nil {
+		return nil, err
+	}
+	if signer, _, err = lengthPrefixed(signers); err != nil {
+		return nil, err
+	}
+	if signedData, _, err = lengthPrefixed(signer); err != nil {
+		return nil, err
+	}
+	// Signed data starts with the digests, followed by the certificates.
+	_, rest, err := lengthPrefixed(signedData)
+	if err != nil {
+		return nil, err
+	}
+	if certs, _, err = lengthPrefixed(rest); err != nil {
+		return nil, err
+	}
+	if cert, _, err = lengthPrefixed(certs); err != nil {
+		return nil, ErrNoAPKSignature
+	}
+	return cert, nil
+}
+
+func lengthPrefixed(b []byte) (value, rest []byte, err error) {
+	if len(b) < 4 {
+		return nil, nil, errors.New("apk: truncated signature data")
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, errors.New("apk: malformed signature data")
+	}
+	return b[4 : 4+n], b[4+n:], nil
+}
+
+// zipCentralDirectoryOffset finds the End of Central Directory record, which may be followed
+// by a comment of up to 64 KiB, and returns the central directory offset it records.
+func zipCentralDirectoryOffset(r io.ReaderAt, size int64) (int64, error) {
+	if size < zipEOCDSize {
+		return 0, errors.New("apk: not a zip file")
+	}
+	tailSize := min(size, zipEOCDSize+0xffff)
+	tail := make([]byte, tailSize)
+	if _, err := r.ReadAt(tail, size-tailSize); err != nil {
+		return 0, err
+	}
+	for i := len(tail) - zipEOCDSize; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == zipEOCDSignature {
+			return int64(binary.LittleEndian.Uint32(tail[i+16:])), nil
+		}
+	}
+	return 0, errors.New("apk: zip end of central directory not found")
+}
+
+// jarSigningCert returns the first certificate of the PKCS#7 signature in META-INF.
+func jarSigningCert(r io.ReaderAt, size int64) ([]byte, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("apk: %w", err)
+	}
+	for _, file := range zr.File {
+		dir, name := path.Split(file.Name)
+		ext := strings.ToUpper(path.Ext(name))
+		if dir != "META-INF/" || (ext != ".RSA" && ext != ".DSA" && ext != ".EC") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		return pkcs7FirstCert(raw)
+	}
+	return nil, ErrNoAPKSignature
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+func pkcs7FirstCert(raw []byte) ([]byte, error) {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("apk: pkcs7: %w", err)
+	}
+	var sd pkcs7SignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("apk: pkcs7: %w", err)
+	}
+	if len(sd.Certificates.Bytes) == 0 {
+		return nil, ErrNoAPKSignature
+	}
+	var cert asn1.RawValue
+	if _, err := asn1.Unmarshal(sd.Certificates.Bytes, &cert); err != nil {
+		return nil, fmt.Errorf("apk: pkcs7: %w", err)
+	}
+	return cert.FullBytes, nil
+}
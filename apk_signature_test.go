@@ -0,0 +1,145 @@
+package gadb
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeCert is a DER SEQUENCE standing in for an X.509 certificate.
+var fakeCert = []byte{0x30, 0x03, 0x02, 0x01, 0x2a}
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withSigningBlock inserts block before the central directory and fixes up the EOCD offset.
+func withSigningBlock(t *testing.T, apk, block []byte) []byte {
+	t.Helper()
+	eocd := bytes.LastIndex(apk, []byte{0x50, 0x4b, 0x05, 0x06})
+	cd := binary.LittleEndian.Uint32(apk[eocd+16:])
+
+	out := append([]byte{}, apk[:cd]...)
+	out = append(out, block...)
+	out = append(out, apk[cd:]...)
+	binary.LittleEndian.PutUint32(out[eocd+len(block)+16:], cd+uint32(len(block)))
+	return out
+}
+
+func prefixed(parts ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, p := range parts {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(p)))
+		buf.Write(p)
+	}
+	return buf.Bytes()
+}
+
+func TestAPKSignerDigest_v2(t *testing.T) {
+	signedData := append(prefixed([]byte{}), prefixed(prefixed(fakeCert))...)
+	signer := prefixed(signedData)
+	value := prefixed(prefixed(signer))
+	apk := withSigningBlock(t, testZip(t, map[string][]byte{"AndroidManifest.xml": {1, 2, 3}}), apkSigningBlock(apkSigV2BlockID, value))
+
+	digest, err := APKSignerDigest(bytes.NewReader(apk), int64(len(apk)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(fakeCert)
+	if digest != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected digest %s", digest)
+	}
+}
+
+func TestAPKSignerDigest_shortRead(t *testing.T) {
+	signedData := append(prefixed([]byte{}), prefixed(prefixed(fakeCert))...)
+	value := prefixed(prefixed(prefixed(signedData)))
+	apk := withSigningBlock(t, testZip(t, map[string][]byte{"AndroidManifest.xml": {1, 2, 3}}), apkSigningBlock(apkSigV2BlockID, value))
+
+	// A device file whose reads stop short of its size, as when dd fails on the device, is
+	// an error rather than a missing signature.
+	for _, readable := range []int{0, len(apk) / 2, len(apk) - 1} {
+		f := &DeviceFile{path: "/data/app/base.apk", size: int64(len(apk)), readRange: func(offset, count int64) ([]byte, error) {
+			return bytes.Clone(apk[min(offset, int64(readable)):min(offset+count, int64(readable))]), nil
+		}}
+		if _, err := APKSignerDigest(f, f.Size()); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%d of %d bytes readable: unexpected error %v", readable, len(apk), err)
+		}
+	}
+}
+
+func TestAPKSignerDigest_v1(t *testing.T) {
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: fakeCert},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs7, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apk := testZip(t, map[string][]byte{"META-INF/CERT.RSA": pkcs7, "classes.dex": {0}})
+
+	digest, err := APKSignerDigest(bytes.NewReader(apk), int64(len(apk)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(fakeCert)
+	if digest != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected digest %s", digest)
+	}
+}
+
+func TestAPKSignerDigest_unsigned(t *testing.T) {
+	apk := testZip(t, map[string][]byte{"classes.dex": {0}})
+	if _, err := APKSignerDigest(bytes.NewReader(apk), int64(len(apk))); !errors.Is(err, ErrNoAPKSignature) {
+		t.Fatalf("expected ErrNoAPKSignature, got %v", err)
+	}
+}
+
+// apkSigningBlock builds an APK Signing Block holding a single id-value pair; used by tests.
+func apkSigningBlock(id uint32, value []byte) []byte {
+	var buf bytes.Buffer
+	pairLen := uint64(4 + len(value))
+	blockSize := 8 + pairLen + 8 + 16
+	_ = binary.Write(&buf, binary.LittleEndian, blockSize)
+	_ = binary.Write(&buf, binary.LittleEndian, pairLen)
+	_ = binary.Write(&buf, binary.LittleEndian, id)
+	buf.Write(value)
+	_ = binary.Write(&buf, binary.LittleEndian, blockSize)
+	buf.WriteString(apkSigBlockMagic)
+	return buf.Bytes()
+}
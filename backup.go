@@ -0,0 +1,121 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrBackupDeclined is returned by Backup when the device sent no data: nobody confirmed
+// the backup on the device's screen within its timeout of about a minute, or it was
+// declined.
+var ErrBackupDeclined = errors.New("backup not confirmed on the device")
+
+// BackupOptions selects what Backup saves, as the flags of `adb backup`.
+type BackupOptions struct {
+	// Packages are the apps to back up; All backs up every app instead.
+	Packages []string
+	All      bool
+	// APK and OBB include the apps' APKs and expansion files (-apk, -obb).
+	APK bool
+	OBB bool
+	// Shared includes the shared storage, /sdcard (-shared).
+	Shared bool
+	// NoSystem leaves system apps out of All (-nosystem).
+	NoSystem bool
+	// KeyValue includes apps that use key/value backup (-keyvalue).
+	KeyValue bool
+	// NoCompress writes the archive uncompressed (-nocompress).
+	NoCompress bool
+}
+
+// args returns the options as arguments of the backup: service.
+func (opts BackupOptions) args() ([]string, error) {
+	if !opts.All && !opts.Shared && len(opts.Packages) == 0 {
+		return nil, errors.New("adb backup: no packages to back up")
+	}
+	var args []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{opts.APK, "-apk"},
+		{opts.OBB, "-obb"},
+		{opts.Shared, "-shared"},
+		{opts.All, "-all"},
+		{opts.NoSystem, "-nosystem"},
+		{opts.KeyValue, "-keyvalue"},
+		{opts.NoCompress, "-nocompress"},
+	} {
+		if flag.set {
+			args = append(args, flag.name)
+		}
+	}
+	for _, pkg := range opts.Packages {
+		if pkg == "" || strings.HasPrefix(pkg, "-") {
+			return nil, fmt.Errorf("adb backup: invalid package name %q", pkg)
+		}
+		args = append(args, shellQuote(pkg))
+	}
+	return args, nil
+}
+
+// Backup writes an Android backup archive of the apps selected by opts to dst, as
+// `adb backup` does. Someone must confirm the backup on the device's screen, optionally
+// setting a password, before any data flows; otherwise Backup returns ErrBackupDeclined.
+// Since Android 12 apps targeting it are only backed up if they are debuggable, and apps
+// can opt out of backups altogether, so an archive may hold less than asked for.
+func (d Device) Backup(opts BackupOptions, dst io.Writer) (err error) {
+	var args []string
+	if args, err = opts.args(); err != nil {
+		return err
+	}
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return err
+	}
+	defer func() { _ = tp.Close() }()
+	if err = tp.Send("backup:" + strings.Join(args, " ")); err != nil {
+		return err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		return fmt.Errorf("adb backup: %w", err)
+	}
+	// Nothing is sent until the backup is confirmed on the device, and it then may pause
+	// while apps back up.
+	_ = tp.sock.SetReadDeadline(time.Time{})
+
+	n, err := io.Copy(dst, tp.sock)
+	if err != nil {
+		return fmt.Errorf("adb backup: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("adb backup: %w", ErrBackupDeclined)
+	}
+	return nil
+}
+
+// Restore writes the Android backup archive src, as made by Backup, to the device, as
+// `adb restore` does. Someone must confirm the restore on the device's screen, entering the
+// backup's password if it has one, before the device reads the archive; until then Restore
+// blocks. As with adb, the device doesn't report how the restore went.
+func (d Device) Restore(src io.Reader) (err error) {
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return err
+	}
+	defer func() { _ = tp.Close() }()
+	if err = tp.Send("restore:"); err != nil {
+		return err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		return fmt.Errorf("adb restore: %w", err)
+	}
+	if _, err = io.Copy(tp.sock, src); err != nil {
+		return fmt.Errorf("adb restore: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package gadb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBackupOptions_args(t *testing.T) {
+	args, err := BackupOptions{Packages: []string{"com.example", "com.example.two"}, APK: true, KeyValue: true}.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"-apk", "-keyvalue", "'com.example'", "'com.example.two'"}; !slices.Equal(args, want) {
+		t.Fatalf("got %q, want %q", args, want)
+	}
+
+	args, err = BackupOptions{All: true, NoSystem: true, Shared: true}.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"-shared", "-all", "-nosystem"}; !slices.Equal(args, want) {
+		t.Fatalf("got %q, want %q", args, want)
+	}
+
+	for _, opts := range []BackupOptions{{}, {APK: true}, {Packages: []string{"-all"}}} {
+		if _, err := opts.args(); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
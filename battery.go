@@ -0,0 +1,135 @@
+package gadb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// BatteryStatus is the charging status, as in android.os.BatteryManager.
+type BatteryStatus int
+
+const (
+	BatteryStatusUnknown     BatteryStatus = 1
+	BatteryStatusCharging    BatteryStatus = 2
+	BatteryStatusDischarging BatteryStatus = 3
+	BatteryStatusNotCharging BatteryStatus = 4
+	BatteryStatusFull        BatteryStatus = 5
+)
+
+var batteryStatusNames = map[BatteryStatus]string{
+	BatteryStatusUnknown:     "unknown",
+	BatteryStatusCharging:    "charging",
+	BatteryStatusDischarging: "discharging",
+	BatteryStatusNotCharging: "not charging",
+	BatteryStatusFull:        "full",
+}
+
+// String returns a name such as "charging".
+func (s BatteryStatus) String() string {
+	if name, ok := batteryStatusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// BatteryHealth is the battery health, as in android.os.BatteryManager.
+type BatteryHealth int
+
+const (
+	BatteryHealthUnknown     BatteryHealth = 1
+	BatteryHealthGood        BatteryHealth = 2
+	BatteryHealthOverheat    BatteryHealth = 3
+	BatteryHealthDead        BatteryHealth = 4
+	BatteryHealthOverVoltage BatteryHealth = 5
+	BatteryHealthFailure     BatteryHealth = 6
+	BatteryHealthCold        BatteryHealth = 7
+)
+
+var batteryHealthNames = map[BatteryHealth]string{
+	BatteryHealthUnknown:     "unknown",
+	BatteryHealthGood:        "good",
+	BatteryHealthOverheat:    "overheat",
+	BatteryHealthDead:        "dead",
+	BatteryHealthOverVoltage: "over voltage",
+	BatteryHealthFailure:     "failure",
+	BatteryHealthCold:        "cold",
+}
+
+// String returns a name such as "good".
+func (h BatteryHealth) String() string {
+	if name, ok := batteryHealthNames[h]; ok {
+		return name
+	}
+	return strconv.Itoa(int(h))
+}
+
+// BatteryInfo is the battery state reported by `dumpsys battery`.
+type BatteryInfo struct {
+	// Level is the charge in percent.
+	Level   int
+	Status  BatteryStatus
+	Health  BatteryHealth
+	Present bool
+	// Temperature is in degrees Celsius, and Voltage in millivolts.
+	Temperature float64
+	Voltage     int
+	// Plugged is the power source the device is plugged into: "ac", "usb", "wireless" or
+	// "dock", or "" when it runs on battery.
+	Plugged    string
+	Technology string
+}
+
+// BatteryInfo returns the current battery state. Values faked with `dumpsys battery set`
+// are reported as set.
+func (d Device) BatteryInfo() (BatteryInfo, error) {
+	output, err := d.Dumpsys("battery")
+	if err != nil {
+		return BatteryInfo{}, err
+	}
+	return parseBatteryInfo(output)
+}
+
+// parseBatteryInfo parses the "key: value" lines of dumpsys battery:
+//
+//	Current Battery Service state:
+//	  AC powered: false
+//	  USB powered: true
+//	  status: 2
+//	  level: 85
+//	  scale: 100
+//	  temperature: 280
+func parseBatteryInfo(output string) (info BatteryInfo, err error) {
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ": "); ok {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	level, err := strconv.Atoi(values["level"])
+	if err != nil {
+		return BatteryInfo{}, errors.New("adb dumpsys battery: level not found")
+	}
+
+	info.Level = level
+	if scale, _ := strconv.Atoi(values["scale"]); scale > 0 && scale != 100 {
+		info.Level = level * 100 / scale
+	}
+	status, _ := strconv.Atoi(values["status"])
+	health, _ := strconv.Atoi(values["health"])
+	info.Status, info.Health = BatteryStatus(status), BatteryHealth(health)
+	info.Present = values["present"] == "true"
+	// temperature is in tenths of a degree.
+	if tenths, err := strconv.Atoi(values["temperature"]); err == nil {
+		info.Temperature = float64(tenths) / 10
+	}
+	info.Voltage, _ = strconv.Atoi(values["voltage"])
+	info.Technology = values["technology"]
+	for _, source := range []string{"AC", "USB", "Wireless", "Dock"} {
+		if values[source+" powered"] == "true" {
+			info.Plugged = strings.ToLower(source)
+			break
+		}
+	}
+	return info, nil
+}
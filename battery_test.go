@@ -0,0 +1,46 @@
+package gadb
+
+import "testing"
+
+func Test_parseBatteryInfo(t *testing.T) {
+	output := `Current Battery Service state:
+  AC powered: false
+  USB powered: true
+  Wireless powered: false
+  Dock powered: false
+  Max charging current: 500000
+  Charge counter: 3000000
+  status: 2
+  health: 2
+  present: true
+  level: 170
+  scale: 200
+  voltage: 4213
+  temperature: 287
+  technology: Li-ion
+`
+	info, err := parseBatteryInfo(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BatteryInfo{
+		Level:       85,
+		Status:      BatteryStatusCharging,
+		Health:      BatteryHealthGood,
+		Present:     true,
+		Temperature: 28.7,
+		Voltage:     4213,
+		Plugged:     "usb",
+		Technology:  "Li-ion",
+	}
+	if info != want {
+		t.Fatalf("got %+v, want %+v", info, want)
+	}
+	if info.Status.String() != "charging" || BatteryHealth(9).String() != "9" {
+		t.Fatalf("unexpected names %v, %v", info.Status, BatteryHealth(9))
+	}
+
+	if _, err = parseBatteryInfo("Can't find service: battery\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,112 @@
+package gadb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Uptime returns how long the device has been running since it booted, from /proc/uptime.
+// It includes time spent in suspend.
+func (d Device) Uptime() (time.Duration, error) {
+	output, err := d.RunShellCommand("cat /proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	return parseUptime(output)
+}
+
+// parseUptime parses /proc/uptime, "<uptime> <idle>" in seconds such as "3512.45 13411.02".
+func parseUptime(output string) (time.Duration, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, errors.New("adb uptime: empty /proc/uptime")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, errors.New("adb uptime: unexpected /proc/uptime " + strings.TrimSpace(output))
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// BootReason returns why the device last booted, as passed by the bootloader in
+// ro.boot.bootreason, such as "reboot", "kernel_panic" or "watchdog". Where the bootloader
+// passes none it returns sys.boot.reason, which bootstat derives since Android 9 in the
+// canonical "<type>,<detail>" form such as "reboot,userrequested". It returns "" if neither
+// is set.
+func (d Device) BootReason() (string, error) {
+	reason, err := d.GetProp("ro.boot.bootreason")
+	if err != nil || reason != "" {
+		return reason, err
+	}
+	return d.GetProp("sys.boot.reason")
+}
+
+// RebootKind classifies the last boot of a device.
+type RebootKind int
+
+const (
+	// RebootUnknown is a boot reason that isn't recognized, or none at all.
+	RebootUnknown RebootKind = iota
+	// RebootNormal is a power on or a requested reboot or shutdown.
+	RebootNormal
+	// RebootCrash is a kernel panic, watchdog bite or hardware reset.
+	RebootCrash
+)
+
+// String returns "unknown", "normal" or "crash".
+func (k RebootKind) String() string {
+	switch k {
+	case RebootNormal:
+		return "normal"
+	case RebootCrash:
+		return "crash"
+	}
+	return "unknown"
+}
+
+// LastReboot classifies BootReason, so that fleets can count devices that recently crashed.
+func (d Device) LastReboot() (RebootKind, error) {
+	reason, err := d.BootReason()
+	if err != nil {
+		return RebootUnknown, err
+	}
+	return classifyBootReason(reason), nil
+}
+
+// crashBootMarkers are substrings of the boot reasons, canonical or passed by bootloaders,
+// that follow a crash.
+var crashBootMarkers = []string{"panic", "watchdog", "wdog", "wdt", "hw_reset", "oops", "crash"}
+
+// normalBootTypes are the boot reason types, canonical or passed by bootloaders, of a power
+// on, or of a reboot or shutdown that someone asked for.
+var normalBootTypes = []string{
+	"reboot", "shutdown", "cold", "recovery", "bootloader", "fastboot", "ota",
+	"powerkey", "power_key", "usb", "charger", "rtc", "alarm",
+}
+
+// classifyBootReason classifies a boot reason such as "reboot,userrequested" or
+// "kernel_panic,sysrq".
+func classifyBootReason(reason string) RebootKind {
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if reason == "" {
+		return RebootUnknown
+	}
+	for _, marker := range crashBootMarkers {
+		if strings.Contains(reason, marker) {
+			return RebootCrash
+		}
+	}
+	kind, _, _ := strings.Cut(reason, ",")
+	// "hard" is a hardware reset, such as "hard,hw_reset".
+	if kind == "hard" {
+		return RebootCrash
+	}
+	for _, normal := range normalBootTypes {
+		if kind == normal {
+			return RebootNormal
+		}
+	}
+	return RebootUnknown
+}
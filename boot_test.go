@@ -0,0 +1,42 @@
+package gadb
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseUptime(t *testing.T) {
+	uptime, err := parseUptime("3512.45 13411.02\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3512*time.Second + 450*time.Millisecond; uptime.Round(time.Millisecond) != want {
+		t.Fatalf("got %v, want %v", uptime, want)
+	}
+	for _, output := range []string{"", "cat: /proc/uptime: Permission denied"} {
+		if _, err := parseUptime(output); err == nil {
+			t.Fatalf("%q: expected an error", output)
+		}
+	}
+}
+
+func Test_classifyBootReason(t *testing.T) {
+	for reason, want := range map[string]RebootKind{
+		"reboot,userrequested": RebootNormal,
+		"shutdown,battery":     RebootNormal,
+		"cold,powerkey":        RebootNormal,
+		"PowerKey":             RebootNormal,
+		"reboot":               RebootNormal,
+		"kernel_panic,sysrq":   RebootCrash,
+		"watchdog":             RebootCrash,
+		"reboot,watchdog":      RebootCrash,
+		"hard,hw_reset":        RebootCrash,
+		"hard":                 RebootCrash,
+		"":                     RebootUnknown,
+		"oem_something":        RebootUnknown,
+	} {
+		if got := classifyBootReason(reason); got != want {
+			t.Errorf("%q: got %v, want %v", reason, got, want)
+		}
+	}
+}
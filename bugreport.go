@@ -0,0 +1,121 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BugreportProgress reports how far a bug report has got, in units of dumpstate's choosing.
+type BugreportProgress struct {
+	Done  int
+	Total int
+}
+
+// Percent returns the share of the report done, from 0 to 100.
+func (p BugreportProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(float64(p.Done)*100/float64(p.Total), 100)
+}
+
+// bugreportzVersionRe matches the version bugreportz -v prints, such as "1.1".
+var bugreportzVersionRe = regexp.MustCompile(`^\d+\.\d+$`)
+
+// Bugreport captures a bug report with bugreportz and pulls the zip to dstZipPath, as
+// `adb bugreport` does. Reports take minutes; progress, if not nil, is called as dumpstate
+// reports progress, which bugreportz 1.0 of Android 7.0 doesn't. Devices before Android 7.0
+// have no bugreportz, so dstZipPath receives their flat text bug report instead. The zip is
+// left on the device, in /bugreports or /data/user_de/0/com.android.shell/files/bugreports.
+func (d Device) Bugreport(ctx context.Context, dstZipPath string, progress func(BugreportProgress)) error {
+	output, err := d.RunShellCommand("bugreportz -v 2>&1")
+	if err != nil {
+		return err
+	}
+	version := strings.TrimSpace(output)
+	if !bugreportzVersionRe.MatchString(version) {
+		return d.flatBugreport(ctx, dstZipPath)
+	}
+
+	cmd := "bugreportz -p"
+	if version == "1.0" {
+		cmd = "bugreportz"
+	}
+	conn, err := d.openExec(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	remote, err := readBugreportz(conn, progress)
+	_ = conn.Close()
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("adb bugreport: %w", ctx.Err())
+		}
+		return err
+	}
+	return d.pullToFile(remote, dstZipPath)
+}
+
+// flatBugreport writes the plain text report of `bugreport` to dst.
+func (d Device) flatBugreport(ctx context.Context, dst string) error {
+	conn, err := d.openExec(ctx, "bugreport")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, conn); err != nil {
+		_ = f.Close()
+		_ = os.Remove(dst)
+		return fmt.Errorf("adb bugreport: %w", err)
+	}
+	return f.Close()
+}
+
+// readBugreportz follows the output of bugreportz until it reports the path of the zip:
+//
+//	BEGIN:/bugreports/bugreport-2024-05-05-12-00-00.zip
+//	PROGRESS:1250/5000
+//	OK:/bugreports/bugreport-2024-05-05-12-00-00.zip
+//
+// or "FAIL:<reason>" instead of OK.
+func readBugreportz(r io.Reader, progress func(BugreportProgress)) (string, error) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		kind, value, _ := strings.Cut(strings.TrimSpace(sc.Text()), ":")
+		switch kind {
+		case "OK":
+			return value, nil
+		case "FAIL":
+			return "", errors.New("adb bugreportz: " + value)
+		case "PROGRESS":
+			done, total, _ := strings.Cut(value, "/")
+			p := BugreportProgress{}
+			var err error
+			if p.Done, err = strconv.Atoi(done); err != nil {
+				continue
+			}
+			if p.Total, err = strconv.Atoi(total); err != nil {
+				continue
+			}
+			if progress != nil {
+				progress(p)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", fmt.Errorf("adb bugreportz: %w", err)
+	}
+	return "", errors.New("adb bugreportz: ended without a report")
+}
@@ -0,0 +1,40 @@
+package gadb
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_readBugreportz(t *testing.T) {
+	var updates []BugreportProgress
+	remote, err := readBugreportz(strings.NewReader(`BEGIN:/bugreports/bugreport-x.zip
+PROGRESS:0/5000
+PROGRESS:2500/5000
+PROGRESS:bad
+OK:/bugreports/bugreport-x.zip
+`), func(p BugreportProgress) { updates = append(updates, p) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote != "/bugreports/bugreport-x.zip" {
+		t.Fatalf("got %q", remote)
+	}
+	if len(updates) != 2 || updates[1].Percent() != 50 {
+		t.Fatalf("unexpected progress %+v", updates)
+	}
+
+	if _, err = readBugreportz(strings.NewReader("BEGIN:/bugreports/x.zip\nFAIL:dumpstate failed\n"), nil); err == nil || !strings.Contains(err.Error(), "dumpstate failed") {
+		t.Fatalf("got %v, want the failure reason", err)
+	}
+	if _, err = readBugreportz(strings.NewReader("BEGIN:/bugreports/x.zip\n"), nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func Test_bugreportzVersionRe(t *testing.T) {
+	for output, want := range map[string]bool{"1.1": true, "1.0": true, "/system/bin/sh: bugreportz: not found": false, "": false} {
+		if got := bugreportzVersionRe.MatchString(output); got != want {
+			t.Errorf("%q: got %t, want %t", output, got, want)
+		}
+	}
+}
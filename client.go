@@ -12,6 +12,8 @@ const AdbDaemonPort = 5555
 type Client struct {
 	host string
 	port int
+	// transcript, if set, records every request sent through this client.
+	transcript *transcript
 }
 
 func NewClient() (Client, error) {
@@ -100,7 +102,8 @@ func (c Client) DeviceList() (devices []Device, err error) {
 			key, val := split[0], split[1]
 			mapAttrs[key] = val
 		}
-		devices = append(devices, Device{adbClient: c, serial: fields[0], attrs: mapAttrs})
+		state, _ := splitDeviceState(fields[1:])
+		devices = append(devices, Device{adbClient: c, serial: fields[0], attrs: mapAttrs, listedState: deviceStateConv(state)})
 	}
 
 	return
@@ -187,7 +190,10 @@ func (c Client) KillServer() (err error) {
 }
 
 func (c Client) createTransport() (tp transport, err error) {
-	return newTransport(fmt.Sprintf("%s:%d", c.host, c.port))
+	if tp, err = newTransport(fmt.Sprintf("%s:%d", c.host, c.port)); err == nil {
+		tp.transcript = c.transcript
+	}
+	return
 }
 
 func (c Client) executeCommand(command string, onlyVerifyResponse ...bool) (resp string, err error) {
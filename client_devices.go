@@ -0,0 +1,115 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Devices lists the devices known to the adb server, including their state and attributes,
+// for use with range-over-func:
+//
+//	for dev, err := range client.Devices(ctx) {
+//		if err != nil {
+//			log.Println(err) // a malformed line; iteration continues
+//			continue
+//		}
+//		fmt.Println(dev.Serial(), dev.ListedState())
+//	}
+//
+// Lines the parser cannot make sense of, as produced by some third-party adb servers, are
+// reported as errors alongside a zero Device rather than aborting the listing. A failure to
+// query the server, or ctx being done, is yielded once and ends the iteration. Unlike
+// DeviceList, which skips lines with fewer than four fields, Devices also lists devices
+// without attributes, such as offline ones.
+func (c Client) Devices(ctx context.Context) iter.Seq2[Device, error] {
+	return func(yield func(Device, error) bool) {
+		resp, err := c.executeCommandContext(ctx, "host:devices-l")
+		if err != nil {
+			yield(Device{}, err)
+			return
+		}
+		for _, line := range strings.Split(resp, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if err = ctx.Err(); err != nil {
+				yield(Device{}, err)
+				return
+			}
+			if !yield(c.parseDeviceLine(line)) {
+				return
+			}
+		}
+	}
+}
+
+// parseDeviceLine parses a line of host:devices-l output, e.g.
+//
+//	emulator-5554  device product:sdk_gphone64 model:sdk_gphone64 device:emu64a transport_id:1
+//	0123456789ABCDEF no permissions (user in plugdev group; are your udev rules wrong?); see [http://...] usb:1-1 transport_id:2
+func (c Client) parseDeviceLine(line string) (Device, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields[0]) == 0 {
+		return Device{}, fmt.Errorf("adb devices: can't parse: %s", line)
+	}
+
+	state, rest := splitDeviceState(fields[1:])
+	attrs := map[string]string{}
+	for _, field := range rest {
+		key, val, ok := strings.Cut(field, ":")
+		if !ok || !isAttributeKey(key) {
+			continue
+		}
+		attrs[key] = val
+	}
+	return Device{adbClient: c, serial: fields[0], attrs: attrs, listedState: deviceStateConv(state)}, nil
+}
+
+// splitDeviceState splits the state off the fields following the serial, joining the two
+// words of "no permissions".
+func splitDeviceState(fields []string) (state string, rest []string) {
+	state, rest = fields[0], fields[1:]
+	if state == "no" && len(rest) > 0 && rest[0] == "permissions" {
+		state, rest = "no permissions", rest[1:]
+	}
+	return state, rest
+}
+
+func isAttributeKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// executeCommandContext is executeCommand, aborting the exchange when ctx is done.
+func (c Client) executeCommandContext(ctx context.Context, command string) (resp string, err error) {
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var tp transport
+	if tp, err = c.createTransport(); err != nil {
+		return "", err
+	}
+	defer func() { _ = tp.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = tp.Close() })
+	defer stop()
+
+	if err = tp.Send(command); err == nil {
+		if err = tp.VerifyResponse(); err == nil {
+			resp, err = tp.UnpackString()
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return
+}
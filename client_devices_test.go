@@ -0,0 +1,54 @@
+package gadb
+
+import (
+	"testing"
+)
+
+func TestClient_parseDeviceLine(t *testing.T) {
+	var c Client
+
+	dev, err := c.parseDeviceLine("emulator-5554          device product:sdk_gphone64_x86_64 model:sdk_gphone64_x86_64 device:emu64xa transport_id:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.Serial() != "emulator-5554" || dev.ListedState() != StateOnline || dev.attrs["model"] != "sdk_gphone64_x86_64" {
+		t.Fatalf("unexpected device: %+v", dev)
+	}
+
+	dev, err = c.parseDeviceLine("0123456789ABCDEF       no permissions (user in plugdev group; are your udev rules wrong?); see [http://developer.android.com/tools/device.html] usb:1-1 transport_id:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.ListedState() != StateNoPermissions || dev.attrs["usb"] != "1-1" || dev.HasAttribute("[http") {
+		t.Fatalf("unexpected device: %+v", dev)
+	}
+
+	dev, err = c.parseDeviceLine("192.168.1.28:5555 offline")
+	if err != nil || dev.ListedState() != StateOffline {
+		t.Fatalf("unexpected device: %+v, %v", dev, err)
+	}
+
+	if _, err = c.parseDeviceLine("garbage"); err == nil {
+		t.Fatal("expected error for malformed line")
+	}
+}
+
+func Test_splitDeviceState(t *testing.T) {
+	for _, tt := range []struct {
+		fields []string
+		state  string
+		rest   int
+	}{
+		{[]string{"device", "product:sdk", "transport_id:1"}, "device", 2},
+		{[]string{"no", "permissions", "(user", "in", "plugdev", "group;", "usb:1-1"}, "no permissions", 5},
+		{[]string{"no", "device"}, "no", 1},
+		{[]string{"offline"}, "offline", 0},
+	} {
+		if state, rest := splitDeviceState(tt.fields); state != tt.state || len(rest) != tt.rest {
+			t.Errorf("%q: got %q, %q", tt.fields, state, rest)
+		}
+	}
+	if deviceStateConv("no permissions") != StateNoPermissions {
+		t.Fatal("expected the joined state to be recognised")
+	}
+}
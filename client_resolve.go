@@ -0,0 +1,123 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNoDevice is wrapped by ResolveDevice when no device matches the selector.
+	ErrNoDevice = errors.New("no device found")
+	// ErrMoreThanOneDevice is wrapped by ResolveDevice when the selector is ambiguous.
+	ErrMoreThanOneDevice = errors.New("more than one device")
+)
+
+// DeviceSelectionError carries the exact message the adb CLI prints for a failed
+// device selection, and unwraps to ErrNoDevice or ErrMoreThanOneDevice.
+type DeviceSelectionError struct {
+	msg string
+	Err error
+}
+
+func (e *DeviceSelectionError) Error() string {
+	return e.msg
+}
+
+func (e *DeviceSelectionError) Unwrap() error {
+	return e.Err
+}
+
+// ResolveDevice picks a single device the same way the adb CLI does. The selector is one of:
+//
+//	""            ANDROID_SERIAL if set, otherwise the only attached device
+//	"-d"          the only USB device
+//	"-e"          the only emulator
+//	"-s <serial>" the device with that serial
+//	"-t <id>"     the device with that transport id
+//	"<serial>"    same as "-s <serial>"
+//
+// Like adb -s, a serial may also be a qualifier such as "usb:1-1", "product:x", "model:x" or "device:x".
+func (c Client) ResolveDevice(selector string) (Device, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		selector = os.Getenv("ANDROID_SERIAL")
+	}
+
+	devices, err := c.DeviceList()
+	if err != nil {
+		return Device{}, err
+	}
+
+	switch flag, arg, _ := strings.Cut(selector, " "); flag {
+	case "":
+		return pickOne(devices, func(Device) bool { return true }, "device/emulator", "devices/emulators")
+	case "-d":
+		return pickOne(devices, func(d Device) bool {
+			usb, _ := d.IsUsb()
+			return usb
+		}, "device", "devices")
+	case "-e":
+		return pickOne(devices, func(d Device) bool {
+			return strings.HasPrefix(d.serial, "emulator-")
+		}, "emulator", "emulators")
+	case "-t":
+		id := strings.TrimSpace(arg)
+		for _, d := range devices {
+			if tid, _ := d.transportId(); tid == id {
+				return d, nil
+			}
+		}
+		return Device{}, &DeviceSelectionError{msg: fmt.Sprintf("adb: no device with transport id '%s'", id), Err: ErrNoDevice}
+	case "-s":
+		selector = strings.TrimSpace(arg)
+	}
+
+	var matches []Device
+	for _, d := range devices {
+		if d.matchesSerial(selector) {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Device{}, &DeviceSelectionError{msg: fmt.Sprintf("adb: device '%s' not found", selector), Err: ErrNoDevice}
+	case 1:
+		return matches[0], nil
+	default:
+		return Device{}, &DeviceSelectionError{msg: fmt.Sprintf("adb: more than one device matches '%s'", selector), Err: ErrMoreThanOneDevice}
+	}
+}
+
+func (d Device) matchesSerial(selector string) bool {
+	if d.serial == selector {
+		return true
+	}
+	key, value, ok := strings.Cut(selector, ":")
+	if !ok {
+		return false
+	}
+	switch key {
+	case "usb", "product", "model", "device":
+		return d.HasAttribute(key) && d.attrs[key] == value
+	}
+	return false
+}
+
+func pickOne(devices []Device, match func(Device) bool, singular, plural string) (Device, error) {
+	var found []Device
+	for _, d := range devices {
+		if match(d) {
+			found = append(found, d)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Device{}, &DeviceSelectionError{msg: fmt.Sprintf("adb: no %s found", plural), Err: ErrNoDevice}
+	case 1:
+		return found[0], nil
+	default:
+		return Device{}, &DeviceSelectionError{msg: fmt.Sprintf("adb: more than one %s", singular), Err: ErrMoreThanOneDevice}
+	}
+}
@@ -0,0 +1,27 @@
+package gadb
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_pickOne(t *testing.T) {
+	devices := []Device{
+		{serial: "emulator-5554", attrs: map[string]string{"product": "sdk_gphone64"}},
+		{serial: "R58M12345", attrs: map[string]string{"usb": "1-1", "model": "SM_G973F"}},
+	}
+
+	_, err := pickOne(devices, func(Device) bool { return true }, "device/emulator", "devices/emulators")
+	if !errors.Is(err, ErrMoreThanOneDevice) || err.Error() != "adb: more than one device/emulator" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = pickOne(nil, func(Device) bool { return true }, "emulator", "emulators")
+	if !errors.Is(err, ErrNoDevice) || err.Error() != "adb: no emulators found" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !devices[1].matchesSerial("usb:1-1") || !devices[1].matchesSerial("model:SM_G973F") || devices[0].matchesSerial("usb:1-1") {
+		t.Fatal("qualifier matching failed")
+	}
+}
@@ -0,0 +1,214 @@
+package gadb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrClipboardUnsupported is returned when the device offers no clipboard access to the shell.
+var ErrClipboardUnsupported = errors.New("clipboard not accessible from the shell")
+
+// SetClipboard sets the primary clip to text, using the clipboard service's shell command.
+func (d Device) SetClipboard(text string) error {
+	output, err := d.RunShellCommand("cmd clipboard set-primary-clip", shellQuote(text))
+	if err != nil {
+		return err
+	}
+	return clipboardError(output)
+}
+
+// GetClipboard returns the text of the primary clip, or "" if the clipboard is empty.
+//
+// Releases without the clipboard shell command (before Android 12) are read through
+// `service call clipboard`, whose transaction codes clipboardCall knows per API level.
+// Since Android 10 the service only hands the clip to the focused app or the input method,
+// so there the clipboard usually reads as empty.
+func (d Device) GetClipboard() (string, error) {
+	output, err := d.RunShellCommand("cmd clipboard get-primary-clip")
+	if err != nil {
+		return "", err
+	}
+	if err = clipboardError(output); errors.Is(err, ErrClipboardUnsupported) {
+		return d.serviceCallClipboard()
+	} else if err != nil {
+		return "", err
+	}
+	output = strings.TrimSuffix(strings.TrimSuffix(output, "\n"), "\r")
+	if output == "null" {
+		return "", nil
+	}
+	return output, nil
+}
+
+func (d Device) serviceCallClipboard() (string, error) {
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return "", err
+	}
+	call, ok := clipboardCall(sdk)
+	if !ok {
+		return "", fmt.Errorf("adb clipboard: %w", ErrClipboardUnsupported)
+	}
+	output, err := d.RunShellCommand("service call clipboard " + call)
+	if err != nil {
+		return "", err
+	}
+	parcel, err := parseParcel(output)
+	if err != nil {
+		return "", fmt.Errorf("adb clipboard: %w", err)
+	}
+	return parseClipData(parcel)
+}
+
+// clipboardCall returns the arguments of `service call clipboard` that call
+// IClipboard.getPrimaryClip on a release of API level sdk:
+//
+//	API 11-27  2 getPrimaryClip(String pkg)
+//	API 28     3 getPrimaryClip(String pkg), after clearPrimaryClip was added
+//	API 29     3 getPrimaryClip(String pkg, int userId)
+//	API 30     4 getPrimaryClip(String pkg, int userId), after setPrimaryClipAsPackage
+func clipboardCall(sdk int) (string, bool) {
+	switch {
+	case sdk < 11:
+		return "", false
+	case sdk < 28:
+		return "2 s16 com.android.shell", true
+	case sdk == 28:
+		return "3 s16 com.android.shell", true
+	case sdk == 29:
+		return "3 s16 com.android.shell i32 0", true
+	case sdk == 30:
+		return "4 s16 com.android.shell i32 0", true
+	default:
+		return "", false
+	}
+}
+
+// parseParcel decodes the reply printed by `service call`:
+//
+//	Result: Parcel(
+//	  0x00000000: 00000000 00000001 00000001 00000005 '................'
+//	  0x00000010: 0061006c 00650062 0000006c 00000001 'l.a.b.e.l.......'
+//	  0x00000020: 0000000a                            '....            ')
+//
+// or, for short ones, `Result: Parcel(00000000 00000000   '........')`, whose words are
+// the parcel's native-endian int32s.
+func parseParcel(output string) ([]byte, error) {
+	if !strings.HasPrefix(strings.TrimSpace(output), "Result: Parcel(") {
+		return nil, fmt.Errorf("unexpected service call output %q", strings.TrimSpace(output))
+	}
+	var parcel []byte
+	for _, line := range strings.Split(output, "\n") {
+		words := strings.TrimPrefix(strings.TrimSpace(line), "Result: Parcel(")
+		if strings.HasPrefix(words, "0x") {
+			_, words, _ = strings.Cut(words, ": ")
+		}
+		words, _, _ = strings.Cut(words, "'")
+		for _, word := range strings.Fields(words) {
+			n, err := strconv.ParseUint(word, 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected service call output %q", line)
+			}
+			parcel = binary.LittleEndian.AppendUint32(parcel, uint32(n))
+		}
+	}
+	return parcel, nil
+}
+
+// parseClipData returns the text of the first item of a parcelled ClipData reply: an
+// exception code, a non-null marker, the ClipDescription, the icon, the item count and the
+// items, each starting with its text as a CharSequence. What the description holds past its
+// label and MIME types changed between releases, so the items are found by their layout.
+func parseClipData(parcel []byte) (string, error) {
+	r := parcelReader{b: parcel}
+	exception, nonNull := r.int32(), r.int32()
+	switch {
+	case r.err != nil:
+		return "", errors.New("adb clipboard: unexpected clip data")
+	case exception != 0:
+		return "", fmt.Errorf("adb clipboard: service call failed with exception %d", exception)
+	case nonNull == 0:
+		return "", nil
+	}
+	r.int32() // CharSequence kind of the label
+	r.string16()
+	for n := r.int32(); n > 0 && r.err == nil; n-- {
+		r.string16()
+	}
+	if r.err != nil {
+		return "", errors.New("adb clipboard: unexpected clip data")
+	}
+
+	for off := r.off; off+12 <= len(parcel); off += 4 {
+		item := parcelReader{b: parcel, off: off}
+		icon, items, kind := item.int32(), item.int32(), item.int32()
+		if icon != 0 || items < 1 || kind != 0 && kind != 1 {
+			continue
+		}
+		if text, null := item.string16(); item.err == nil {
+			if null {
+				return "", nil
+			}
+			return text, nil
+		}
+	}
+	return "", errors.New("adb clipboard: unexpected clip data")
+}
+
+// parcelReader reads the values of a parcel.
+type parcelReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *parcelReader) int32() int32 {
+	if r.err != nil || r.off+4 > len(r.b) {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	n := int32(binary.LittleEndian.Uint32(r.b[r.off:]))
+	r.off += 4
+	return n
+}
+
+// string16 reads a UTF-16 string: its length in code units, or -1 for null, then the code
+// units with a terminating zero, padded to four bytes.
+func (r *parcelReader) string16() (s string, null bool) {
+	n := int(r.int32())
+	if r.err != nil || n == -1 {
+		return "", r.err == nil
+	}
+	size := ((n+1)*2 + 3) &^ 3
+	if n < 0 || r.off+size > len(r.b) || binary.LittleEndian.Uint16(r.b[r.off+n*2:]) != 0 {
+		r.err = errors.New("invalid string in parcel")
+		return "", false
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(r.b[r.off+i*2:])
+	}
+	r.off += size
+	return string(utf16.Decode(units)), false
+}
+
+// clipboardError maps the output of a clipboard command that isn't supported to
+// ErrClipboardUnsupported and other failures to an error.
+func clipboardError(output string) error {
+	trimmed := strings.TrimSpace(output)
+	switch {
+	case strings.HasPrefix(trimmed, "Can't find service"),
+		strings.HasPrefix(trimmed, "No shell command implementation"),
+		strings.HasPrefix(trimmed, "Unknown command"),
+		strings.Contains(trimmed, "cmd: not found"):
+		return fmt.Errorf("adb clipboard: %w", ErrClipboardUnsupported)
+	case strings.HasPrefix(trimmed, "Error"), strings.HasPrefix(trimmed, "Exception occurred"):
+		return fmt.Errorf("adb clipboard: %s", trimmed)
+	}
+	return nil
+}
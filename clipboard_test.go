@@ -0,0 +1,70 @@
+package gadb
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_clipboardError(t *testing.T) {
+	for _, output := range []string{
+		"Can't find service: clipboard\n",
+		"No shell command implementation.\n",
+		"/system/bin/sh: cmd: not found\n",
+	} {
+		if err := clipboardError(output); !errors.Is(err, ErrClipboardUnsupported) {
+			t.Errorf("%q: expected ErrClipboardUnsupported, got %v", output, err)
+		}
+	}
+	if err := clipboardError("Error: bad argument\n"); err == nil || errors.Is(err, ErrClipboardUnsupported) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := clipboardError("hello world\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_clipboardCall(t *testing.T) {
+	for sdk, want := range map[int]string{
+		19: "2 s16 com.android.shell",
+		28: "3 s16 com.android.shell",
+		29: "3 s16 com.android.shell i32 0",
+		30: "4 s16 com.android.shell i32 0",
+	} {
+		if call, ok := clipboardCall(sdk); !ok || call != want {
+			t.Errorf("API %d: got %q, want %q", sdk, call, want)
+		}
+	}
+	if _, ok := clipboardCall(31); ok {
+		t.Error("expected no service call for API 31")
+	}
+}
+
+func Test_parseClipData(t *testing.T) {
+	// Android 9: the description also holds null extras and a timestamp.
+	parcel, err := parseParcel(`Result: Parcel(
+  0x00000000: 00000000 00000001 00000001 00000005 '................'
+  0x00000010: 0061006c 00650062 0000006c 00000001 'l.a.b.e.l.......'
+  0x00000020: 0000000a 00650074 00740078 0070002f '....t.e.x.t./.p.'
+  0x00000030: 0061006c 006e0069 00000000 ffffffff 'l.a.i.n.........'
+  0x00000040: cfe5687b 0000018b 00000000 00000001 '{h..............'
+  0x00000050: 00000001 0000000b 00e90068 006c006c '........h...l.l.'
+  0x00000060: 0020006f 006f0077 006c0072 00000064 'o. .w.o.r.l.d...'
+  0x00000070: ffffffff 00000000 00000000          '............    ')
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := parseClipData(parcel); err != nil || text != "héllo world" {
+		t.Fatalf("got %q, %v", text, err)
+	}
+
+	// No clip.
+	parcel, _ = parseParcel("Result: Parcel(00000000 00000000   '........')\n")
+	if text, err := parseClipData(parcel); err != nil || text != "" {
+		t.Fatalf("got %q, %v", text, err)
+	}
+
+	if _, err = parseParcel("service: Service clipboard does not exist\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
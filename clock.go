@@ -0,0 +1,118 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sdkToyboxDate is the first API level (Android 6.0) whose date is toybox's, which takes
+// MMDDhhmmCCYY.ss; toolbox's takes -s YYYYMMDD.hhmmss in local time.
+const sdkToyboxDate = 23
+
+// sdkTimeZoneDetector is the first API level (Android 12) with `cmd time_zone_detector`.
+const sdkTimeZoneDetector = 31
+
+// SetTime sets the device clock to t, to the second. It needs root, and sticks only with
+// automatic time off; see SetAutoTime.
+func (d Device) SetTime(t time.Time) error {
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return err
+	}
+	output, err := d.RunShellCommand(setTimeCommand(t, sdk, d.location()))
+	if err != nil {
+		return err
+	}
+	// date prints the new date on success, and "date: ..." complaints otherwise.
+	if output = strings.TrimSpace(output); strings.HasPrefix(output, "date: ") {
+		return errors.New("adb " + output)
+	}
+	return nil
+}
+
+// setTimeCommand returns the date command setting the clock to t on a device of API level
+// sdk whose time zone is loc.
+func setTimeCommand(t time.Time, sdk int, loc *time.Location) string {
+	if sdk >= sdkToyboxDate {
+		return "date -u " + t.UTC().Format("010215042006.05")
+	}
+	return "date -s " + t.In(loc).Format("20060102.150405")
+}
+
+// SetTimezone sets the device time zone to an IANA name such as "Europe/Berlin", and reads
+// it back to verify it. It sticks only with automatic time zone off, which it turns off.
+// Since Android 12 it uses `cmd time_zone_detector`; earlier releases set
+// persist.sys.timezone, which needs root.
+func (d Device) SetTimezone(tz string) error {
+	if tz == "" || strings.ContainsAny(tz, " \t\n") {
+		return fmt.Errorf("adb timezone: invalid time zone %q", tz)
+	}
+	if err := d.Settings(SettingsGlobal).PutBool("auto_time_zone", false); err != nil {
+		return fmt.Errorf("adb timezone: %w", err)
+	}
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return err
+	}
+	if sdk >= sdkTimeZoneDetector {
+		_, err = d.RunShellCommand("cmd time_zone_detector suggest_manual_time_zone --zone_id", shellQuote(tz))
+	} else {
+		err = d.SetProp("persist.sys.timezone", tz)
+	}
+	d.InvalidateCache(CacheProps)
+	if err != nil {
+		return err
+	}
+
+	got, err := d.getProp("persist.sys.timezone")
+	if err != nil {
+		return err
+	}
+	if got != tz {
+		return fmt.Errorf("adb timezone: persist.sys.timezone is %q after setting it to %q", got, tz)
+	}
+	return nil
+}
+
+// SetAutoTime turns automatic, network-provided, time on or off with the auto_time global
+// setting. Turn it off before SetTime.
+func (d Device) SetAutoTime(on bool) error {
+	return d.Settings(SettingsGlobal).PutBool("auto_time", on)
+}
+
+// ClockOffset measures how far the device clock is ahead of the host's, negative if it is
+// behind. The device time is taken as of the middle of the shell round trip, so the offset
+// is only as precise as half the round trip, and to the second on devices whose date has
+// no %N.
+func (d Device) ClockOffset() (time.Duration, error) {
+	start := time.Now()
+	output, err := d.RunShellCommand("date +%s.%N")
+	if err != nil {
+		return 0, err
+	}
+	host := start.Add(time.Since(start) / 2)
+	device, err := parseEpoch(output)
+	if err != nil {
+		return 0, err
+	}
+	return device.Sub(host), nil
+}
+
+// parseEpoch parses the output of `date +%s.%N`, such as "1700000000.123456789", or
+// "1700000000.%N" or "1700000000.N" where date doesn't support %N.
+func parseEpoch(output string) (time.Time, error) {
+	output = strings.TrimSpace(output)
+	secs, frac, _ := strings.Cut(output, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("adb date: unexpected output " + output)
+	}
+	var nsec int64
+	if n, err := strconv.ParseInt(frac, 10, 64); err == nil && len(frac) == 9 {
+		nsec = n
+	}
+	return time.Unix(sec, nsec), nil
+}
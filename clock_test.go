@@ -0,0 +1,33 @@
+package gadb
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_setTimeCommand(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	at := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	if got, want := setTimeCommand(at, 34, berlin), "date -u 030514072024.09"; got != want {
+		t.Errorf("toybox: got %q, want %q", got, want)
+	}
+	if got, want := setTimeCommand(at, 22, berlin), "date -s 20240305.150709"; got != want {
+		t.Errorf("toolbox: got %q, want %q", got, want)
+	}
+}
+
+func Test_parseEpoch(t *testing.T) {
+	for output, want := range map[string]time.Time{
+		"1700000000.123456789\n": time.Unix(1700000000, 123456789),
+		"1700000000.%N\n":        time.Unix(1700000000, 0),
+		"1700000000.N\r\n":       time.Unix(1700000000, 0),
+	} {
+		got, err := parseEpoch(output)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%q: got %v, %v, want %v", output, got, err, want)
+		}
+	}
+	if _, err := parseEpoch("date: bad format\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
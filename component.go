@@ -0,0 +1,84 @@
+package gadb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComponentName identifies an app component (activity, service, receiver or provider),
+// as written by am and pm: "com.example/.MainActivity" or "com.example/com.example.MainActivity".
+type ComponentName struct {
+	Package string
+	// Class is the fully qualified class name.
+	Class string
+}
+
+// ParseComponentName parses "pkg/Class", expanding a class starting with "." relative to pkg.
+func ParseComponentName(s string) (ComponentName, error) {
+	pkg, class, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok || pkg == "" || class == "" || strings.ContainsAny(class, "/ ") {
+		return ComponentName{}, fmt.Errorf("invalid component name %q", s)
+	}
+	if strings.HasPrefix(class, ".") {
+		class = pkg + class
+	}
+	return ComponentName{Package: pkg, Class: class}, nil
+}
+
+// String returns the flattened "pkg/Class" form.
+func (c ComponentName) String() string {
+	return c.Package + "/" + c.Class
+}
+
+// ShortString abbreviates a class inside the package, as in "com.example/.MainActivity".
+func (c ComponentName) ShortString() string {
+	if strings.HasPrefix(c.Class, c.Package+".") {
+		return c.Package + "/" + strings.TrimPrefix(c.Class, c.Package)
+	}
+	return c.String()
+}
+
+// EnablePackage re-enables pkg for the given user (the device default if omitted).
+func (d Device) EnablePackage(pkg string, user ...int) error {
+	return d.setEnabledState("enable", pkg, user)
+}
+
+// DisablePackage disables pkg for the given user (0 if omitted) with `pm disable-user`, which
+// works without root for most apps, including preinstalled updaters.
+func (d Device) DisablePackage(pkg string, user ...int) error {
+	if len(user) == 0 {
+		user = []int{0}
+	}
+	return d.setEnabledState("disable-user", pkg, user)
+}
+
+// EnableComponent re-enables a single component.
+func (d Device) EnableComponent(c ComponentName, user ...int) error {
+	return d.setEnabledState("enable", c.String(), user)
+}
+
+// DisableComponent disables a single component, e.g. a system dialog activity. Changing the
+// components of other apps usually requires root or a privileged shell.
+func (d Device) DisableComponent(c ComponentName, user ...int) error {
+	return d.setEnabledState("disable", c.String(), user)
+}
+
+// setEnabledState runs pm <op>, which prints "Package <name> new state: <state>" on success
+// (or "Component ..." for components).
+func (d Device) setEnabledState(op, target string, user []int) error {
+	if target == "" || strings.ContainsAny(target, " ") {
+		return fmt.Errorf("adb %s: invalid package or component %q", op, target)
+	}
+	cmd := "pm " + op
+	if len(user) != 0 {
+		cmd += fmt.Sprintf(" --user %d", user[0])
+	}
+	output, err := d.RunShellCommand(cmd, shellQuote(target))
+	if err != nil {
+		return err
+	}
+	if strings.Contains(output, "new state:") {
+		return nil
+	}
+	return parsePMResult(op, output)
+}
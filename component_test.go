@@ -0,0 +1,27 @@
+package gadb
+
+import "testing"
+
+func TestParseComponentName(t *testing.T) {
+	c, err := ParseComponentName("com.example/.ui.MainActivity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Class != "com.example.ui.MainActivity" || c.String() != "com.example/com.example.ui.MainActivity" {
+		t.Fatalf("unexpected component: %+v", c)
+	}
+	if c.ShortString() != "com.example/.ui.MainActivity" {
+		t.Fatalf("unexpected short form: %s", c.ShortString())
+	}
+
+	other := ComponentName{Package: "com.android.settings", Class: "com.android.other.Receiver"}
+	if other.ShortString() != other.String() {
+		t.Fatalf("unexpected short form: %s", other.ShortString())
+	}
+
+	for _, s := range []string{"com.example", "/.Main", "com.example/", "a/b/c"} {
+		if _, err = ParseComponentName(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CPUUsage is the CPU load measured over an interval by Device.CPUUsage.
+type CPUUsage struct {
+	PID int
+	// Process is the share of the CPU time of all cores used by the process, and Total the
+	// share used by everything, both in percent from 0 to 100. top reports per-core
+	// percentages instead, which are Process times Cores.
+	Process float64
+	Total   float64
+	Cores   int
+}
+
+// cpuSampleSeparator is echoed between the two samples of CPUUsage.
+const cpuSampleSeparator = "--- gadb cpu sample ---"
+
+// CPUUsage measures the CPU usage of a process, given by pid or by name (such as a package
+// name, looked up with pidof), over interval, along with the total CPU load. Both samples
+// of /proc/stat and /proc/<pid>/stat are taken on the device in one shell call, so the
+// interval isn't stretched by adb round trips. interval defaults to one second.
+// It returns ErrProcessNotRunning if the process doesn't exist or exits meanwhile.
+func (d Device) CPUUsage(process string, interval time.Duration) (CPUUsage, error) {
+	pid, err := d.resolvePID(process)
+	if err != nil {
+		return CPUUsage{}, err
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	sample := fmt.Sprintf("cat /proc/stat /proc/%d/stat", pid)
+	output, err := d.RunShellCommand(fmt.Sprintf("%s; sleep %s; echo %s; %s", sample,
+		strconv.FormatFloat(interval.Seconds(), 'f', -1, 64), shellQuote(cpuSampleSeparator), sample))
+	if err != nil {
+		return CPUUsage{}, err
+	}
+	usage, err := parseCPUUsage(output)
+	if err != nil {
+		return CPUUsage{}, fmt.Errorf("adb cpu usage of %d: %w", pid, err)
+	}
+	usage.PID = pid
+	return usage, nil
+}
+
+// resolvePID returns the pid of process, given either as a pid or as a process name. If
+// several processes have the name, the first listed by pidof is used.
+func (d Device) resolvePID(process string) (int, error) {
+	if pid, err := strconv.Atoi(process); err == nil {
+		return pid, nil
+	}
+	output, err := d.RunShellCommand("pidof", shellQuote(process))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("adb pidof %s: %w", process, ErrProcessNotRunning)
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, fmt.Errorf("adb pidof %s: %s", process, strings.TrimSpace(output))
+	}
+	return pid, nil
+}
+
+// cpuSample is one reading of the CPU counters, in clock ticks.
+type cpuSample struct {
+	total, idle, process uint64
+	cores                int
+}
+
+func parseCPUUsage(output string) (CPUUsage, error) {
+	first, second, ok := strings.Cut(output, cpuSampleSeparator)
+	if !ok {
+		return CPUUsage{}, fmt.Errorf("unexpected output %q", truncateOutput([]byte(output)))
+	}
+	before, err := parseCPUSample(first)
+	if err != nil {
+		return CPUUsage{}, err
+	}
+	after, err := parseCPUSample(second)
+	if err != nil {
+		return CPUUsage{}, err
+	}
+
+	usage := CPUUsage{Cores: after.cores}
+	if elapsed := float64(after.total - before.total); after.total > before.total {
+		usage.Total = 100 * (elapsed - float64(after.idle-before.idle)) / elapsed
+		usage.Process = 100 * float64(after.process-before.process) / elapsed
+	}
+	return usage, nil
+}
+
+// parseCPUSample reads /proc/stat followed by /proc/<pid>/stat. The first line of /proc/stat
+// sums the time of all cores:
+//
+//	cpu  user nice system idle iowait irq softirq steal guest guest_nice
+//
+// The process's user and system times are the 14th and 15th fields of its stat line, counting
+// from the pid; the second, the command name in parentheses, may contain spaces.
+func parseCPUSample(output string) (sample cpuSample, err error) {
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "cpu":
+			found = true
+			// guest time is already included in user time.
+			for i, field := range fields[1:min(len(fields), 9)] {
+				n, _ := strconv.ParseUint(field, 10, 64)
+				sample.total += n
+				if i == 3 || i == 4 {
+					sample.idle += n
+				}
+			}
+		case strings.HasPrefix(fields[0], "cpu"):
+			sample.cores++
+		case strings.Contains(line, ") "):
+			stat := strings.Fields(line[strings.LastIndex(line, ") ")+2:])
+			if len(stat) < 13 {
+				return cpuSample{}, fmt.Errorf("unexpected process stat %q", truncateOutput([]byte(line)))
+			}
+			utime, _ := strconv.ParseUint(stat[11], 10, 64)
+			stime, _ := strconv.ParseUint(stat[12], 10, 64)
+			sample.process = utime + stime
+			if !found {
+				return cpuSample{}, errors.New("cpu line not found in /proc/stat")
+			}
+			return sample, nil
+		}
+	}
+	return cpuSample{}, ErrProcessNotRunning
+}
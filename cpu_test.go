@@ -0,0 +1,29 @@
+package gadb
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func Test_parseCPUUsage(t *testing.T) {
+	output := "cpu  100 0 100 700 100 0 0 0 50 0\ncpu0 50 0 50 350 50 0 0 0 0 0\ncpu1 50 0 50 350 50 0 0 0 0 0\nintr 12345\n" +
+		"4321 (My App) S 1 4321 0 0 -1 4194560 100 0 0 0 20 10 0 0 20 0 30 0 1000 0 0\n" +
+		cpuSampleSeparator + "\n" +
+		"cpu  200 0 200 1350 150 0 0 0 50 0\ncpu0 100 0 100 675 75 0 0 0 0 0\ncpu1 100 0 100 675 75 0 0 0 0 0\n" +
+		"4321 (My App) S 1 4321 0 0 -1 4194560 100 0 0 0 70 40 0 0 20 0 30 0 1000 0 0\n"
+	usage, err := parseCPUUsage(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 900 ticks elapsed, 700 of them idle, 80 used by the process.
+	if usage.Cores != 2 || math.Abs(usage.Total-200.0/9) > 1e-9 || math.Abs(usage.Process-80.0/9) > 1e-9 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+
+	exited := "cpu  100 0 100 700 100 0 0 0 0 0\n4321 (app) S 1 4321 0 0 -1 0 0 0 0 0 20 10\n" + cpuSampleSeparator +
+		"\ncpu  200 0 200 1350 150 0 0 0 0 0\ncat: /proc/4321/stat: No such file or directory\n"
+	if _, err = parseCPUUsage(exited); !errors.Is(err, ErrProcessNotRunning) {
+		t.Fatalf("expected ErrProcessNotRunning, got %v", err)
+	}
+}
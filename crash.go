@@ -0,0 +1,217 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tryanks/gadb/logcat"
+)
+
+// CrashKind classifies a CrashReport.
+type CrashKind string
+
+const (
+	CrashJava     CrashKind = "java"
+	CrashNative   CrashKind = "native"
+	CrashANR      CrashKind = "anr"
+	CrashWatchdog CrashKind = "watchdog"
+)
+
+// tombstoneDir is where debuggerd writes native crash reports.
+const tombstoneDir = "/data/tombstones"
+
+// CrashReport is one crash found on the device.
+type CrashReport struct {
+	Kind CrashKind
+	Time time.Time
+	// Process is the crashed process or package name, and PID its pid; either may be empty
+	// when the report doesn't name them.
+	Process string
+	PID     int
+	// Source is where the report was found: "logcat", the tombstone's path, or "dropbox:"
+	// followed by the dropbox tag, such as "dropbox:data_app_anr".
+	Source string
+	// Text is the full report: the stack trace, tombstone or dropbox entry.
+	Text string
+}
+
+// CollectCrashes gathers the crashes since the given time, oldest first:
+//   - Java crashes from the crash logcat buffer,
+//   - native tombstones from /data/tombstones, which is readable only when adbd runs as root,
+//   - and the crash, ANR and watchdog entries of the dropbox.
+//
+// Without root, tombstones are still found through the dropbox's SYSTEM_TOMBSTONE entries,
+// which hold their text; a bugreport would have them too, but takes minutes to generate.
+// The same crash reported by several sources, as told by its kind and pid, is listed once.
+func (d Device) CollectCrashes(since time.Time) ([]CrashReport, error) {
+	entries, err := d.LogcatEntries(context.Background(), logcat.Options{
+		Buffers: []logcat.Buffer{logcat.BufferCrash},
+		Since:   since,
+		Dump:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adb collect crashes: %w", err)
+	}
+	var logged []logcat.LogEntry
+	for entry := range entries {
+		logged = append(logged, entry)
+	}
+	reports := parseJavaCrashes(logged)
+	reports = append(reports, d.tombstones(since)...)
+
+	loc := d.location()
+	output, err := d.DumpsysWithTimeout(time.Minute, "dropbox", "--print", since.In(loc).Format(time.DateOnly), since.In(loc).Format(time.TimeOnly))
+	if err != nil {
+		return nil, fmt.Errorf("adb collect crashes: %w", err)
+	}
+	reports = append(reports, parseDropbox(output, loc)...)
+
+	return dedupeCrashes(reports, since), nil
+}
+
+// tombstones reads the text tombstones modified since the given time. It finds none unless
+// the directory is readable.
+func (d Device) tombstones(since time.Time) (reports []CrashReport) {
+	infos, err := d.ListV2(tombstoneDir)
+	if err != nil {
+		return nil
+	}
+	for _, info := range infos {
+		// Android 12 and later write a protobuf copy next to each text tombstone.
+		if !strings.HasPrefix(info.Name, "tombstone_") || path.Ext(info.Name) == ".pb" || info.LastModified.Before(since) {
+			continue
+		}
+		remotePath := path.Join(tombstoneDir, info.Name)
+		text, err := d.PullBytes(remotePath)
+		if err != nil {
+			continue
+		}
+		report := CrashReport{Kind: CrashNative, Time: info.LastModified, Source: remotePath, Text: string(text)}
+		report.parseHeader()
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// parseJavaCrashes groups the AndroidRuntime entries of the crash buffer into reports. Each
+// crash starts with "FATAL EXCEPTION: <thread>", followed by "Process: <name>, PID: <pid>"
+// and the stack trace.
+func parseJavaCrashes(entries []logcat.LogEntry) (reports []CrashReport) {
+	for _, entry := range entries {
+		if entry.Tag != "AndroidRuntime" {
+			continue
+		}
+		if strings.HasPrefix(entry.Message, "FATAL EXCEPTION") {
+			reports = append(reports, CrashReport{Kind: CrashJava, Time: entry.Time, PID: entry.PID, Source: "logcat"})
+		} else if len(reports) == 0 || entry.PID != reports[len(reports)-1].PID {
+			continue
+		}
+		current := &reports[len(reports)-1]
+		if process, ok := strings.CutPrefix(entry.Message, "Process: "); ok {
+			current.Process, _, _ = strings.Cut(process, ",")
+		}
+		current.Text += entry.Message + "\n"
+	}
+	return reports
+}
+
+// dropboxSeparator starts each entry printed by `dumpsys dropbox --print`, followed by a line
+// with its time and tag:
+//
+//	2025-10-14 12:34:56 data_app_crash (text, 1234 bytes)
+const dropboxSeparator = "========================================"
+
+// parseDropbox reads the crash, ANR and watchdog entries of `dumpsys dropbox --print` output.
+// Entry times are in the device's time zone, loc.
+func parseDropbox(output string, loc *time.Location) (reports []CrashReport) {
+	blocks := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), dropboxSeparator+"\n")
+	for _, block := range blocks[1:] {
+		header, text, _ := strings.Cut(block, "\n")
+		fields := strings.Fields(header)
+		if len(fields) < 3 {
+			continue
+		}
+		kind, ok := dropboxKind(fields[2])
+		if !ok {
+			continue
+		}
+		t, err := time.ParseInLocation(time.DateTime, fields[0]+" "+fields[1], loc)
+		if err != nil {
+			continue
+		}
+		report := CrashReport{Kind: kind, Time: t, Source: "dropbox:" + fields[2], Text: strings.TrimRight(text, "\n") + "\n"}
+		report.parseHeader()
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// dropboxKind classifies a dropbox tag, such as data_app_crash or system_server_anr.
+func dropboxKind(tag string) (CrashKind, bool) {
+	switch {
+	case tag == "SYSTEM_TOMBSTONE" || strings.HasSuffix(tag, "_native_crash"):
+		return CrashNative, true
+	case strings.HasSuffix(tag, "_crash"):
+		return CrashJava, true
+	case strings.HasSuffix(tag, "_anr"):
+		return CrashANR, true
+	case strings.HasSuffix(tag, "_watchdog"):
+		return CrashWatchdog, true
+	}
+	return "", false
+}
+
+// parseHeader fills in Process and PID from the text, which names them in "Process: " and
+// "PID: " lines for dropbox entries, or in a tombstone's line
+//
+//	pid: 1234, tid: 1234, name: main  >>> com.example <<<
+func (r *CrashReport) parseHeader() {
+	for _, line := range strings.Split(r.Text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Process: ") && r.Process == "":
+			r.Process = strings.TrimSpace(strings.TrimPrefix(line, "Process: "))
+		case strings.HasPrefix(line, "PID: ") && r.PID == 0:
+			r.PID, _ = strconv.Atoi(strings.TrimPrefix(line, "PID: "))
+		case strings.HasPrefix(line, "pid: ") && r.PID == 0:
+			pid, rest, _ := strings.Cut(strings.TrimPrefix(line, "pid: "), ",")
+			r.PID, _ = strconv.Atoi(pid)
+			if _, name, ok := strings.Cut(rest, ">>> "); ok {
+				r.Process, _, _ = strings.Cut(name, " <<<")
+			}
+		}
+	}
+}
+
+// dedupeCrashes drops reports older than since and repeated reports of a crash, keeping the
+// first of each kind and pid, and sorts the rest by time.
+func dedupeCrashes(reports []CrashReport, since time.Time) []CrashReport {
+	type key struct {
+		kind CrashKind
+		pid  int
+	}
+	// Dropbox times have whole seconds.
+	since = since.Truncate(time.Second)
+	seen := map[key]bool{}
+	var kept []CrashReport
+	for _, report := range reports {
+		if report.Time.Before(since) {
+			continue
+		}
+		if report.PID != 0 {
+			k := key{report.Kind, report.PID}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		kept = append(kept, report)
+	}
+	slices.SortStableFunc(kept, func(a, b CrashReport) int { return a.Time.Compare(b.Time) })
+	return kept
+}
@@ -0,0 +1,77 @@
+package gadb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tryanks/gadb/logcat"
+)
+
+func Test_parseJavaCrashes(t *testing.T) {
+	at := time.Date(2025, 10, 14, 12, 0, 0, 0, time.UTC)
+	entries := []logcat.LogEntry{
+		{Time: at, PID: 4321, Tag: "AndroidRuntime", Message: "FATAL EXCEPTION: main"},
+		{Time: at, PID: 4321, Tag: "AndroidRuntime", Message: "Process: com.example, PID: 4321"},
+		{Time: at, PID: 99, Tag: "AndroidRuntime", Message: "unrelated"},
+		{Time: at, PID: 4321, Tag: "AndroidRuntime", Message: "java.lang.IllegalStateException: boom"},
+		{Time: at, PID: 4321, Tag: "libc", Message: "Fatal signal 6"},
+	}
+	reports := parseJavaCrashes(entries)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	want := "FATAL EXCEPTION: main\nProcess: com.example, PID: 4321\njava.lang.IllegalStateException: boom\n"
+	if r.Kind != CrashJava || r.Process != "com.example" || r.PID != 4321 || r.Source != "logcat" || r.Text != want {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
+
+func Test_parseDropbox(t *testing.T) {
+	output := `Drop box contents: 3 entries
+Max entries: 1000
+Searching for: 2025-10-14 11:59:00
+
+========================================
+2025-10-14 12:00:01 data_app_anr (text, 120 bytes)
+Process: com.example
+PID: 4321
+Subject: Input dispatching timed out
+
+========================================
+2025-10-14 12:00:02 SYSTEM_BOOT (text, 10 bytes)
+boot
+
+========================================
+2025-10-14 12:00:03 SYSTEM_TOMBSTONE (compressed text, 2000 bytes)
+*** *** *** *** *** *** *** *** *** *** *** *** *** *** *** ***
+pid: 77, tid: 78, name: RenderThread  >>> com.example.native <<<
+signal 11 (SIGSEGV), code 1 (SEGV_MAPERR), fault addr 0x0
+`
+	reports := parseDropbox(output, time.UTC)
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2: %+v", len(reports), reports)
+	}
+	anr := reports[0]
+	if anr.Kind != CrashANR || anr.Process != "com.example" || anr.PID != 4321 || anr.Source != "dropbox:data_app_anr" ||
+		!anr.Time.Equal(time.Date(2025, 10, 14, 12, 0, 1, 0, time.UTC)) {
+		t.Fatalf("unexpected ANR report %+v", anr)
+	}
+	native := reports[1]
+	if native.Kind != CrashNative || native.Process != "com.example.native" || native.PID != 77 {
+		t.Fatalf("unexpected native report %+v", native)
+	}
+}
+
+func Test_dedupeCrashes(t *testing.T) {
+	since := time.Date(2025, 10, 14, 12, 0, 0, 500_000_000, time.UTC)
+	reports := dedupeCrashes([]CrashReport{
+		{Kind: CrashJava, PID: 1, Time: since.Add(2 * time.Second), Source: "logcat"},
+		{Kind: CrashJava, PID: 1, Time: since.Add(time.Second), Source: "dropbox:data_app_crash"},
+		{Kind: CrashANR, PID: 1, Time: since.Add(-300 * time.Millisecond)},
+		{Kind: CrashWatchdog, Time: since.Add(-time.Hour)},
+	}, since)
+	if len(reports) != 2 || reports[0].Kind != CrashANR || reports[1].Source != "logcat" {
+		t.Fatalf("unexpected reports %+v", reports)
+	}
+}
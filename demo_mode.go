@@ -0,0 +1,93 @@
+package gadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// demoAction is the broadcast SystemUI listens to for demo mode commands.
+const demoAction = "com.android.systemui.demo"
+
+// DemoModeOptions configures the status bar shown in demo mode. Zero fields use the defaults
+// noted below, which match the status bar of Android's own marketing screenshots.
+type DemoModeOptions struct {
+	// Clock is the time shown, as hhmm; "1200" if empty.
+	Clock string
+	// BatteryLevel is the battery percentage; 100 if zero.
+	BatteryLevel int
+	Charging     bool
+	// WifiLevel and MobileLevel are the signal strengths from 1 to 4; 4 if zero.
+	WifiLevel   int
+	MobileLevel int
+	// ShowNotifications keeps notification icons visible.
+	ShowNotifications bool
+}
+
+func (opts DemoModeOptions) commands() ([]map[string]string, error) {
+	clock := opts.Clock
+	if clock == "" {
+		clock = "1200"
+	}
+	if len(clock) != 4 || strings.Trim(clock, "0123456789") != "" || clock[:2] > "23" || clock[2:] > "59" {
+		return nil, fmt.Errorf("adb demo mode: clock %q is not hhmm", clock)
+	}
+	battery := opts.BatteryLevel
+	if battery == 0 {
+		battery = 100
+	}
+	if battery < 0 || battery > 100 {
+		return nil, fmt.Errorf("adb demo mode: battery level %d out of range 0-100", battery)
+	}
+	wifi, mobile := opts.WifiLevel, opts.MobileLevel
+	if wifi == 0 {
+		wifi = 4
+	}
+	if mobile == 0 {
+		mobile = 4
+	}
+	if wifi < 1 || wifi > 4 || mobile < 1 || mobile > 4 {
+		return nil, fmt.Errorf("adb demo mode: signal levels %d, %d out of range 1-4", wifi, mobile)
+	}
+
+	return []map[string]string{
+		{"command": "enter"},
+		{"command": "clock", "hhmm": clock},
+		{"command": "battery", "level": strconv.Itoa(battery), "plugged": strconv.FormatBool(opts.Charging)},
+		{"command": "network", "wifi": "show", "level": strconv.Itoa(wifi), "fully": "true"},
+		{"command": "network", "mobile": "show", "datatype": "none", "level": strconv.Itoa(mobile), "fully": "true"},
+		{"command": "notifications", "visible": strconv.FormatBool(opts.ShowNotifications)},
+	}, nil
+}
+
+// DemoMode turns SystemUI's demo mode on, with a fixed clock, battery and signal and without
+// notification icons, so that screenshots get identical status bars; or off again, restoring
+// the real status bar. opts is ignored when disabling.
+func (d Device) DemoMode(enable bool, opts DemoModeOptions) error {
+	if !enable {
+		return d.demoCommand(map[string]string{"command": "exit"})
+	}
+
+	commands, err := opts.commands()
+	if err != nil {
+		return err
+	}
+	// SystemUI ignores demo commands unless they are allowed.
+	if err = d.Settings(SettingsGlobal).PutBool("sysui_demo_allowed", true); err != nil {
+		return fmt.Errorf("adb demo mode: %w", err)
+	}
+	for _, command := range commands {
+		if err = d.demoCommand(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Device) demoCommand(extras map[string]string) error {
+	_, err := d.SendBroadcast(Intent{Action: demoAction, StringExtras: extras})
+	if err != nil {
+		return fmt.Errorf("adb demo mode %s: %w", extras["command"], err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDemoModeOptions_commands(t *testing.T) {
+	commands, err := DemoModeOptions{}.commands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"command": "enter"},
+		{"command": "clock", "hhmm": "1200"},
+		{"command": "battery", "level": "100", "plugged": "false"},
+		{"command": "network", "wifi": "show", "level": "4", "fully": "true"},
+		{"command": "network", "mobile": "show", "datatype": "none", "level": "4", "fully": "true"},
+		{"command": "notifications", "visible": "false"},
+	}
+	if !reflect.DeepEqual(commands, want) {
+		t.Fatalf("got %v, want %v", commands, want)
+	}
+
+	commands, err = DemoModeOptions{Clock: "0941", BatteryLevel: 42, Charging: true, WifiLevel: 2, MobileLevel: 1, ShowNotifications: true}.commands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commands[1]["hhmm"] != "0941" || commands[2]["level"] != "42" || commands[2]["plugged"] != "true" ||
+		commands[3]["level"] != "2" || commands[4]["level"] != "1" || commands[5]["visible"] != "true" {
+		t.Fatalf("unexpected commands %v", commands)
+	}
+
+	for _, opts := range []DemoModeOptions{
+		{Clock: "9999"},
+		{Clock: "2400"},
+		{Clock: "1260"},
+		{Clock: "930"},
+		{Clock: "12:00"},
+		{BatteryLevel: 101},
+		{BatteryLevel: -1},
+		{WifiLevel: 5},
+		{MobileLevel: -1},
+	} {
+		if _, err := opts.commands(); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+	if _, err = (DemoModeOptions{Clock: "2359"}).commands(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,15 +1,22 @@
 package gadb
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
+	"slices"
 	"strings"
 	"time"
 )
 
+// DeviceFileInfo describes a device file as reported by the sync v1 protocol.
+// Its Size is only 32 bits wide and wraps for files over 4 GiB; use StatV2 and ListV2
+// to get a DeviceFileInfoV2 with exact sizes.
 type DeviceFileInfo struct {
 	Name         string
 	Mode         os.FileMode
@@ -26,16 +33,34 @@ const DefaultFileMode = os.FileMode(0664)
 type DeviceState string
 
 const (
-	StateUnknown      DeviceState = "UNKNOWN"
-	StateOnline       DeviceState = "online"
-	StateOffline      DeviceState = "offline"
-	StateDisconnected DeviceState = "disconnected"
+	StateUnknown       DeviceState = "UNKNOWN"
+	StateOnline        DeviceState = "online"
+	StateOffline       DeviceState = "offline"
+	StateDisconnected  DeviceState = "disconnected"
+	StateUnauthorized  DeviceState = "unauthorized"
+	StateAuthorizing   DeviceState = "authorizing"
+	StateConnecting    DeviceState = "connecting"
+	StateNoPermissions DeviceState = "no permissions"
+	StateBootloader    DeviceState = "bootloader"
+	StateRecovery      DeviceState = "recovery"
+	StateRescue        DeviceState = "rescue"
+	StateSideload      DeviceState = "sideload"
+	StateHost          DeviceState = "host"
 )
 
 var deviceStateStrings = map[string]DeviceState{
-	"":        StateDisconnected,
-	"offline": StateOffline,
-	"device":  StateOnline,
+	"":               StateDisconnected,
+	"offline":        StateOffline,
+	"device":         StateOnline,
+	"unauthorized":   StateUnauthorized,
+	"authorizing":    StateAuthorizing,
+	"connecting":     StateConnecting,
+	"no permissions": StateNoPermissions,
+	"bootloader":     StateBootloader,
+	"recovery":       StateRecovery,
+	"rescue":         StateRescue,
+	"sideload":       StateSideload,
+	"host":           StateHost,
 }
 
 func deviceStateConv(k string) (deviceState DeviceState) {
@@ -66,6 +91,11 @@ type Device struct {
 	adbClient Client
 	serial    string
 	attrs     map[string]string
+	// listedState is the state reported by the listing this Device came from.
+	listedState DeviceState
+	cache       *deviceCache
+
+	normalizeOutput bool
 }
 
 func (d Device) HasAttribute(key string) bool {
@@ -119,6 +149,12 @@ func (d Device) IsUsb() (bool, error) {
 	return usb != "", nil
 }
 
+// ListedState returns the state the adb server reported when the device was listed,
+// without another round trip. Use State for the current state.
+func (d Device) ListedState() DeviceState {
+	return d.listedState
+}
+
 func (d Device) State() (DeviceState, error) {
 	resp, err := d.adbClient.executeCommand(fmt.Sprintf("host-serial:%s:get-state", d.serial))
 	return deviceStateConv(resp), err
@@ -129,6 +165,27 @@ func (d Device) DevicePath() (string, error) {
 	return resp, err
 }
 
+// Features returns the adb features supported by both the device and the server.
+func (d Device) Features() ([]string, error) {
+	features, err := cached(d, CacheFeatures, func() ([]string, error) {
+		resp, err := d.adbClient.executeCommand(fmt.Sprintf("host-serial:%s:features", d.serial))
+		if err != nil {
+			return nil, err
+		}
+		return strings.FieldsFunc(resp, func(r rune) bool { return r == ',' || r == '\n' }), nil
+	})
+	return slices.Clone(features), err
+}
+
+// HasFeature reports whether the device advertises the given adb feature.
+func (d Device) HasFeature(feature string) (bool, error) {
+	features, err := d.Features()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(features, feature), nil
+}
+
 func (d Device) Forward(local, remote Port, noRebind ...bool) (err error) {
 	command := ""
 	if len(noRebind) != 0 && noRebind[0] {
@@ -174,6 +231,9 @@ func (d Device) RunShellCommandWithBytes(cmd string, args ...string) ([]byte, er
 		return nil, errors.New("adb shell: command cannot be empty")
 	}
 	raw, err := d.executeCommand(fmt.Sprintf("shell:%s", cmd))
+	if d.normalizeOutput {
+		raw = NormalizeOutput(raw)
+	}
 	return raw, err
 }
 
@@ -227,7 +287,7 @@ func (d Device) EnableAdbOverTCP(port ...int) (err error) {
 }
 
 func (d Device) createDeviceTransport() (tp transport, err error) {
-	if tp, err = newTransport(fmt.Sprintf("%s:%d", d.adbClient.host, d.adbClient.port)); err != nil {
+	if tp, err = d.adbClient.createTransport(); err != nil {
 		return transport{}, err
 	}
 
@@ -242,6 +302,7 @@ func (d Device) executeCommand(command string, onlyVerifyResponse ...bool) (raw
 	if len(onlyVerifyResponse) == 0 {
 		onlyVerifyResponse = []bool{false}
 	}
+	defer func() { d.recordCommand(err) }()
 
 	var tp transport
 	if tp, err = d.createDeviceTransport(); err != nil {
@@ -295,6 +356,34 @@ func (d Device) List(remotePath string) (devFileInfos []DeviceFileInfo, err erro
 	return
 }
 
+// Stat returns information about remotePath using the sync STAT request.
+// The returned error wraps fs.ErrNotExist if the path does not exist.
+func (d Device) Stat(remotePath string) (info DeviceFileInfo, err error) {
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return DeviceFileInfo{}, err
+	}
+	defer func() { _ = tp.Close() }()
+
+	var sync syncTransport
+	if sync, err = tp.CreateSyncTransport(); err != nil {
+		return DeviceFileInfo{}, err
+	}
+	defer func() { _ = sync.Close() }()
+
+	if err = sync.Send("STAT", remotePath); err != nil {
+		return DeviceFileInfo{}, err
+	}
+	if info, err = sync.ReadStat(); err != nil {
+		return DeviceFileInfo{}, err
+	}
+	if info.Mode == 0 {
+		return DeviceFileInfo{}, &fs.PathError{Op: "stat", Path: remotePath, Err: fs.ErrNotExist}
+	}
+	info.Name = path.Base(remotePath)
+	return
+}
+
 func (d Device) PushFile(local *os.File, remotePath string, modification ...time.Time) (err error) {
 	if len(modification) == 0 {
 		var stat os.FileInfo
@@ -312,6 +401,12 @@ func (d Device) Push(source io.Reader, remotePath string, modification time.Time
 		mode = []os.FileMode{DefaultFileMode}
 	}
 
+	counter := &countingReader{r: source}
+	defer func(started time.Time) {
+		d.recordCommand(err)
+		d.recordSync(counter.n, 0, started)
+	}(time.Now())
+
 	var tp transport
 	if tp, err = d.createDeviceTransport(); err != nil {
 		return err
@@ -324,13 +419,19 @@ func (d Device) Push(source io.Reader, remotePath string, modification time.Time
 	}
 	defer func() { _ = sync.Close() }()
 
-	data := fmt.Sprintf("%s,%d", remotePath, mode[0])
-	if err = sync.Send("SEND", data); err != nil {
-		return err
-	}
+	if codec, flag := d.syncCodec(); codec != nil {
+		if err = sync.pushCompressed(counter, remotePath, uint32(mode[0]), codec, flag); err != nil {
+			return
+		}
+	} else {
+		data := fmt.Sprintf("%s,%d", remotePath, mode[0])
+		if err = sync.Send("SEND", data); err != nil {
+			return err
+		}
 
-	if err = sync.SendStream(source); err != nil {
-		return
+		if err = sync.SendStream(counter); err != nil {
+			return
+		}
 	}
 
 	if err = sync.SendStatus("DONE", uint32(modification.Unix())); err != nil {
@@ -344,6 +445,12 @@ func (d Device) Push(source io.Reader, remotePath string, modification time.Time
 }
 
 func (d Device) Pull(remotePath string, dest io.Writer) (err error) {
+	counter := &countingWriter{w: dest}
+	defer func(started time.Time) {
+		d.recordCommand(err)
+		d.recordSync(0, counter.n, started)
+	}(time.Now())
+
 	var tp transport
 	if tp, err = d.createDeviceTransport(); err != nil {
 		return err
@@ -356,40 +463,149 @@ func (d Device) Pull(remotePath string, dest io.Writer) (err error) {
 	}
 	defer func() { _ = sync.Close() }()
 
+	if codec, flag := d.syncCodec(); codec != nil {
+		return sync.pullCompressed(remotePath, counter, codec, flag)
+	}
+
 	if err = sync.Send("RECV", remotePath); err != nil {
 		return err
 	}
 
-	err = sync.WriteStream(dest)
+	err = sync.WriteStream(counter)
 	return
 }
 
+// PushBytes writes data to remotePath, stamping it with the current time.
+func (d Device) PushBytes(data []byte, remotePath string, mode ...os.FileMode) error {
+	return d.Push(bytes.NewReader(data), remotePath, time.Now(), mode...)
+}
+
+// PushString writes s to remotePath, stamping it with the current time.
+func (d Device) PushString(s string, remotePath string, mode ...os.FileMode) error {
+	return d.Push(strings.NewReader(s), remotePath, time.Now(), mode...)
+}
+
+// PullBytes reads the whole of remotePath into memory.
+func (d Device) PullBytes(remotePath string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Pull(remotePath, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ErrLogcatEnded is returned by Logcat when logcat exits successfully before being asked to stop.
+var ErrLogcatEnded = errors.New("logcat ended unexpectedly")
+
+// Logcat streams the device log into dst until exitChan receives, then returns nil. It is
+// a wrapper around LogcatStream, which new code should use instead.
+//
+// If logcat exits, is killed, or the device disconnects before exitChan fires, Logcat returns
+// early with an *ExitError, *SignalError, *ExitMissingError or *TransportError, or with
+// ErrLogcatEnded for a clean exit. Without shell v2 a disconnect looks like a clean exit.
 func (d Device) Logcat(dst io.Writer, exitChan chan bool) error {
-	var tp transport
-	var err error
-	if tp, err = d.createDeviceTransport(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := d.LogcatStream(ctx)
+	if err != nil {
 		return err
 	}
-	defer func() { _ = tp.Close() }()
+	defer func() { _ = stream.Close() }()
+
+	return copyLogcat(dst, stream, exitChan, cancel)
+}
+
+// copyLogcat copies stream into dst until exitChan receives, then calls cancel, which must
+// end stream, and returns nil.
+func copyLogcat(dst io.Writer, stream io.Reader, exitChan chan bool, cancel func()) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(dst, stream)
+		done <- err
+	}()
 
-	if err = tp.Send("shell:logcat"); err != nil {
+	select {
+	case <-exitChan:
+		cancel()
+		<-done
+		return nil
+	case err := <-done:
+		if err == nil {
+			err = fmt.Errorf("adb logcat: %w", ErrLogcatEnded)
+		}
 		return err
 	}
-	if err = tp.VerifyResponse(); err != nil {
-		return err
+}
+
+// startShellV2 starts cmd over shell v2 with its stdin closed. The caller closes tp.
+func (d Device) startShellV2(cmd string) (tp transport, shTp shellTransport, err error) {
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return tp, shTp, err
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	go func() {
-		r := NewReader(ctx, tp.sock)
-		io.Copy(dst, r)
+	defer func() {
+		if err != nil {
+			_ = tp.Close()
+		}
 	}()
-	<-exitChan
-	cancel()
-	return err
+	if err = tp.Send("shell,v2,raw:" + cmd); err != nil {
+		return tp, shTp, err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		return tp, shTp, err
+	}
+	if shTp, err = tp.CreateShellTransport(); err != nil {
+		return tp, shTp, err
+	}
+	return tp, shTp, shTp.Send(ShellCloseStdin, []byte{})
 }
 
-func (d Device) Logcat2File(file string, exitChan chan bool) error {
-	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_SYNC|os.O_APPEND, 0755)
+// copyShellOutput copies stdout and stderr packets to dst until the command exits, returning
+// the error describing its exit status.
+func copyShellOutput(shTp *shellTransport, dst io.Writer) error {
+	return copyShellStreams(shTp, dst, dst)
+}
+
+// copyShellStreams is copyShellOutput with stdout and stderr kept apart.
+func copyShellStreams(shTp *shellTransport, stdout, stderr io.Writer) error {
+	for {
+		msgType, data, err := shTp.Read()
+		if err == io.EOF {
+			return &ExitMissingError{}
+		}
+		if err != nil {
+			return &TransportError{Err: err}
+		}
+
+		switch msgType {
+		case ShellStdout:
+			if _, err = stdout.Write(data); err != nil {
+				return err
+			}
+		case ShellStderr:
+			if _, err = stderr.Write(data); err != nil {
+				return err
+			}
+		case ShellExit:
+			if len(data) == 0 {
+				return &ExitMissingError{}
+			}
+			return exitStatusError(int(data[0]))
+		default:
+			if _, err = handleShellPacket(msgType, data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Logcat2File appends the device log to file until exitChan receives, as Logcat does. With a
+// LogRotation, file is capped in size and rotated into file.1, file.2 and so on.
+func (d Device) Logcat2File(file string, exitChan chan bool, rotation ...LogRotation) error {
+	var r LogRotation
+	if len(rotation) != 0 {
+		r = rotation[0]
+	}
+	f, err := openRotatingFile(file, r)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,402 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActivityOptions configures StartActivity.
+type ActivityOptions struct {
+	// Wait blocks until the activity has launched and reports launch timings (-W).
+	Wait bool
+	// ForceStop stops the target app before starting the activity (-S).
+	ForceStop bool
+	// User starts the activity as the given user id or "current"; empty uses the device default.
+	User string
+}
+
+func (opts ActivityOptions) args() []string {
+	var args []string
+	if opts.Wait {
+		args = append(args, "-W")
+	}
+	if opts.ForceStop {
+		args = append(args, "-S")
+	}
+	if opts.User != "" {
+		args = append(args, "--user", shellQuote(opts.User))
+	}
+	return args
+}
+
+// ActivityResult is what am reported for a started activity.
+type ActivityResult struct {
+	// Intent is the intent as am resolved it, from the "Starting: Intent { ... }" line.
+	Intent string
+	// Warning is set when am started nothing new, e.g. because the task was brought to the front.
+	Warning string
+
+	// The remaining fields are only reported with ActivityOptions.Wait.
+	Status      string
+	LaunchState string
+	Activity    ComponentName
+	ThisTime    time.Duration
+	TotalTime   time.Duration
+	WaitTime    time.Duration
+}
+
+// StartActivity starts the activity for intent with `am start`. Errors reported by am are
+// returned as an *IntentError; use errors.Is(err, ErrIntentNotResolved) to detect an intent
+// that no activity handles.
+func (d Device) StartActivity(intent Intent, opts ActivityOptions) (ActivityResult, error) {
+	args := append(opts.args(), intent.Args()...)
+	output, err := d.RunShellCommand("am start", args...)
+	if err != nil {
+		return ActivityResult{}, err
+	}
+	return parseActivityResult(output)
+}
+
+// MeasureLaunch starts componentOrPkg, either a component such as "com.example/.MainActivity"
+// or a package whose launcher activity is started, with `am start -W` and returns the launch
+// timings am reports. With coldStart the app is force-stopped first so the measurement
+// includes process creation; otherwise a running app is brought to the front (a warm or hot
+// start). A launch that doesn't complete is returned as an *IntentError.
+func (d Device) MeasureLaunch(componentOrPkg string, coldStart bool) (ActivityResult, error) {
+	intent, err := launchIntent(componentOrPkg)
+	if err != nil {
+		return ActivityResult{}, err
+	}
+	result, err := d.StartActivity(intent, ActivityOptions{Wait: true, ForceStop: coldStart})
+	if err != nil {
+		return ActivityResult{}, err
+	}
+	return result, launchError(result)
+}
+
+// launchIntent returns the launcher intent for a component or a package.
+func launchIntent(componentOrPkg string) (Intent, error) {
+	intent := Intent{Action: "android.intent.action.MAIN", Categories: []string{"android.intent.category.LAUNCHER"}}
+	if strings.Contains(componentOrPkg, "/") {
+		component, err := ParseComponentName(componentOrPkg)
+		if err != nil {
+			return Intent{}, err
+		}
+		intent.Component = component
+	} else if componentOrPkg == "" || strings.ContainsAny(componentOrPkg, " ") {
+		return Intent{}, fmt.Errorf("adb am start: invalid package name %q", componentOrPkg)
+	} else {
+		intent.Package = componentOrPkg
+	}
+	return intent, nil
+}
+
+// launchError reports a launch that `am start -W` did not see complete.
+func launchError(result ActivityResult) error {
+	if result.Status != "ok" {
+		return &IntentError{Op: "start", Message: fmt.Sprintf("launch did not complete (status %q)", result.Status)}
+	}
+	return nil
+}
+
+// OpenURL opens url with an android.intent.action.VIEW intent, like tapping a link, and
+// optionally restricts it to the app pkg, e.g. to test a deep link without the disambiguation
+// dialog. Query strings and fragments are passed through intact. errors.Is(err,
+// ErrIntentNotResolved) reports that no app handles the URL.
+func (d Device) OpenURL(rawURL string, pkg ...string) error {
+	intent, err := viewIntent(rawURL, pkg)
+	if err != nil {
+		return err
+	}
+	_, err = d.StartActivity(intent, ActivityOptions{})
+	return err
+}
+
+func viewIntent(rawURL string, pkg []string) (Intent, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Intent{}, fmt.Errorf("adb am start: %w", err)
+	}
+	if u.Scheme == "" {
+		return Intent{}, fmt.Errorf("adb am start: URL %q has no scheme", rawURL)
+	}
+
+	intent := Intent{Action: "android.intent.action.VIEW", Data: rawURL}
+	if len(pkg) != 0 {
+		intent.Package = pkg[0]
+	}
+	return intent, nil
+}
+
+func parseActivityResult(output string) (result ActivityResult, err error) {
+	if err = amError("start", output); err != nil {
+		return ActivityResult{}, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Starting":
+			result.Intent = value
+		case "Warning":
+			result.Warning = value
+		case "Status":
+			result.Status = value
+		case "LaunchState":
+			result.LaunchState = value
+		case "Activity":
+			result.Activity, _ = ParseComponentName(value)
+		case "ThisTime":
+			result.ThisTime = parseMillis(value)
+		case "TotalTime":
+			result.TotalTime = parseMillis(value)
+		case "WaitTime":
+			result.WaitTime = parseMillis(value)
+		}
+	}
+	return result, nil
+}
+
+func parseMillis(s string) time.Duration {
+	ms, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+type broadcastOptions struct {
+	foreground bool
+	user       string
+	permission string
+}
+
+// BroadcastOption configures SendBroadcast.
+type BroadcastOption func(*broadcastOptions)
+
+// BroadcastReceiverForeground delivers the broadcast with foreground priority (--receiver-foreground),
+// which avoids the queueing delay of background broadcasts.
+func BroadcastReceiverForeground() BroadcastOption {
+	return func(o *broadcastOptions) { o.foreground = true }
+}
+
+// BroadcastUser sends the broadcast to the given user only (--user); by default it goes to all users.
+func BroadcastUser(user int) BroadcastOption {
+	return func(o *broadcastOptions) { o.user = strconv.Itoa(user) }
+}
+
+// BroadcastPermission only delivers to receivers holding permission (--receiver-permission).
+func BroadcastPermission(permission string) BroadcastOption {
+	return func(o *broadcastOptions) { o.permission = permission }
+}
+
+// BroadcastResult is the final result of an ordered broadcast as set by its receivers.
+type BroadcastResult struct {
+	// Code is the result code; 0 (Activity.RESULT_CANCELED) unless a receiver set one.
+	Code int
+	// Data is the result data, if a receiver set any.
+	Data string
+	// Extras is the result extras bundle as printed by am, e.g. "Bundle[{key=value}]".
+	Extras string
+}
+
+// SendBroadcast sends intent with `am broadcast` and waits for it to be delivered. Errors
+// reported by am are returned as an *IntentError.
+func (d Device) SendBroadcast(intent Intent, opts ...BroadcastOption) (BroadcastResult, error) {
+	var o broadcastOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var args []string
+	if o.user != "" {
+		args = append(args, "--user", o.user)
+	}
+	if o.foreground {
+		args = append(args, "--receiver-foreground")
+	}
+	if o.permission != "" {
+		args = append(args, "--receiver-permission", shellQuote(o.permission))
+	}
+	output, err := d.RunShellCommand("am broadcast", append(args, intent.Args()...)...)
+	if err != nil {
+		return BroadcastResult{}, err
+	}
+	return parseBroadcastResult(output)
+}
+
+// parseBroadcastResult parses the `Broadcast completed: result=N[, data="..."][, extras: ...]`
+// line of am broadcast.
+func parseBroadcastResult(output string) (result BroadcastResult, err error) {
+	if err = amError("broadcast", output); err != nil {
+		return BroadcastResult{}, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "Broadcast completed: result=")
+		if !ok {
+			continue
+		}
+		code := rest
+		if i := strings.Index(rest, ","); i >= 0 {
+			code, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		if result.Code, err = strconv.Atoi(code); err != nil {
+			return BroadcastResult{}, &IntentError{Op: "broadcast", Message: "unexpected result " + strconv.Quote(line)}
+		}
+		if before, extras, ok := strings.Cut(rest, ", extras: "); ok {
+			result.Extras, rest = extras, before
+		}
+		if data, ok := strings.CutPrefix(rest, `, data="`); ok {
+			result.Data = strings.TrimSuffix(data, `"`)
+		}
+		return result, nil
+	}
+	return BroadcastResult{}, &IntentError{Op: "broadcast", Message: "no result in output " + strconv.Quote(strings.TrimSpace(output))}
+}
+
+// StartService starts the service for intent with `am startservice` and returns the component
+// that was started. Since Android 5 service intents must be explicit or name a Package. Errors
+// reported by am, such as a service that can't be found or requires a permission, are returned
+// as an *IntentError; errors.Is(err, ErrIntentNotResolved) detects an unresolved intent.
+func (d Device) StartService(intent Intent) (ComponentName, error) {
+	return d.startService("startservice", intent)
+}
+
+// StartForegroundService is like StartService but uses `am start-foreground-service`, which
+// lets the service start while its app is in the background as long as it calls
+// startForeground promptly. It requires Android 8.0.
+func (d Device) StartForegroundService(intent Intent) (ComponentName, error) {
+	return d.startService("start-foreground-service", intent)
+}
+
+func (d Device) startService(op string, intent Intent) (ComponentName, error) {
+	// am only prints the intent back, so resolve an implicit intent up front to know the component.
+	component := intent.Component
+	if component.Package == "" {
+		var err error
+		if component, err = d.resolveService(op, intent); err != nil {
+			return ComponentName{}, err
+		}
+	}
+
+	output, err := d.RunShellCommand("am "+op, intent.Args()...)
+	if err != nil {
+		return ComponentName{}, err
+	}
+	if err = amError(op, output); err != nil {
+		return ComponentName{}, err
+	}
+	return component, nil
+}
+
+// resolveService returns the highest priority service matching intent.
+func (d Device) resolveService(op string, intent Intent) (ComponentName, error) {
+	output, err := d.RunShellCommand("pm query-services --components", intent.Args()...)
+	if err != nil {
+		return ComponentName{}, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if component, err := ParseComponentName(line); err == nil {
+			return component, nil
+		}
+	}
+	if err = amError(op, output); err != nil {
+		return ComponentName{}, err
+	}
+	return ComponentName{}, &IntentError{Op: op, Message: "unable to resolve service intent"}
+}
+
+// ForceStop stops everything associated with pkg for the given user (all users if omitted):
+// its processes are killed, its alarms and jobs are cancelled and it won't receive broadcasts
+// until it is launched again, so each test case starts from a clean state.
+func (d Device) ForceStop(pkg string, user ...int) error {
+	return d.stopPackage("force-stop", pkg, user)
+}
+
+// KillBackgroundProcesses kills the processes of pkg that are safe to kill, i.e. those not in
+// the foreground, like the system does under memory pressure (am kill). Unlike ForceStop the
+// app's alarms and jobs stay scheduled.
+func (d Device) KillBackgroundProcesses(pkg string, user ...int) error {
+	return d.stopPackage("kill", pkg, user)
+}
+
+func (d Device) stopPackage(op, pkg string, user []int) error {
+	cmd, err := stopPackageCommand(op, pkg, user)
+	if err != nil {
+		return err
+	}
+	output, err := d.RunShellCommand(cmd)
+	if err != nil {
+		return err
+	}
+	return amError(op, output)
+}
+
+func stopPackageCommand(op, pkg string, user []int) (string, error) {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return "", fmt.Errorf("adb am %s: invalid package name %q", op, pkg)
+	}
+	cmd := "am " + op
+	if len(user) != 0 {
+		cmd += fmt.Sprintf(" --user %d", user[0])
+	}
+	return cmd + " " + shellQuote(pkg), nil
+}
+
+// ErrNoFocusedActivity is returned by CurrentActivity when no activity has focus, e.g. while
+// the screen is off or a system window such as the notification shade is focused.
+var ErrNoFocusedActivity = errors.New("no focused activity")
+
+// CurrentActivity returns the activity that is resumed and focused. It reads the activity
+// manager state and falls back to the window manager's focus on devices whose dumpsys
+// activity output isn't understood.
+func (d Device) CurrentActivity() (ComponentName, error) {
+	output, err := d.Dumpsys("activity", "activities")
+	if err != nil {
+		return ComponentName{}, err
+	}
+	if component, ok := focusedComponent(output, "mResumedActivity:", "ResumedActivity:", "topResumedActivity="); ok {
+		return component, nil
+	}
+
+	if output, err = d.Dumpsys("window", "windows"); err != nil {
+		return ComponentName{}, err
+	}
+	if component, ok := focusedComponent(output, "mCurrentFocus=", "mFocusedApp="); ok {
+		return component, nil
+	}
+	return ComponentName{}, ErrNoFocusedActivity
+}
+
+// focusedComponent returns the component of the first record following one of keys, in key
+// order. Records look like "ActivityRecord{8e3c2f1 u0 com.example/.MainActivity t42}" or
+// "Window{5d1a0b u0 com.example/com.example.MainActivity}".
+func focusedComponent(output string, keys ...string) (ComponentName, bool) {
+	lines := strings.Split(output, "\n")
+	for _, key := range keys {
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, key) {
+				continue
+			}
+			for _, field := range strings.Fields(strings.TrimPrefix(line, key)) {
+				field = strings.TrimRight(field, "}")
+				if !strings.Contains(field, "/") {
+					continue
+				}
+				if component, err := ParseComponentName(field); err == nil {
+					return component, true
+				}
+			}
+		}
+	}
+	return ComponentName{}, false
+}
@@ -0,0 +1,211 @@
+package gadb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseActivityResult(t *testing.T) {
+	result, err := parseActivityResult("Starting: Intent { act=android.intent.action.MAIN cmp=com.example/.Main }\n" +
+		"Status: ok\nLaunchState: COLD\nActivity: com.example/.Main\nTotalTime: 523\nWaitTime: 530\nComplete\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Intent != "Intent { act=android.intent.action.MAIN cmp=com.example/.Main }" || result.Status != "ok" ||
+		result.LaunchState != "COLD" || result.Activity.Class != "com.example.Main" ||
+		result.TotalTime != 523*time.Millisecond || result.WaitTime != 530*time.Millisecond {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result, err = parseActivityResult("Starting: Intent { cmp=com.example/.Main }\n" +
+		"Warning: Activity not started, its current task has been brought to the front\n")
+	if err != nil || !strings.HasPrefix(result.Warning, "Activity not started") {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+
+	_, err = parseActivityResult("Starting: Intent { cmp=com.example/.Missing }\n" +
+		"Error type 3\nError: Activity class {com.example/com.example.Missing} does not exist.\n")
+	if !errors.Is(err, ErrIntentNotResolved) || err.Error() != "adb am start: Activity class {com.example/com.example.Missing} does not exist." {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = parseActivityResult("Starting: Intent { cmp=com.android.settings/.Hidden }\n" +
+		"Exception occurred while executing 'start':\njava.lang.SecurityException: Permission Denial: starting Intent\n")
+	var intentErr *IntentError
+	if !errors.As(err, &intentErr) || errors.Is(err, ErrIntentNotResolved) ||
+		!strings.HasPrefix(intentErr.Message, "java.lang.SecurityException") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func Test_parseBroadcastResult(t *testing.T) {
+	for output, want := range map[string]BroadcastResult{
+		"Broadcasting: Intent { act=com.example.PING flg=0x400000 }\nBroadcast completed: result=0\n": {},
+		`Broadcast completed: result=-1, data="pong, with \"quotes\""` + "\n":                         {Code: -1, Data: `pong, with \"quotes\"`},
+		`Broadcast completed: result=3, data="x", extras: Bundle[{count=2}]`:                          {Code: 3, Data: "x", Extras: "Bundle[{count=2}]"},
+		"Broadcast completed: result=1, extras: Bundle[mParcelledData.dataSize=48]":                   {Code: 1, Extras: "Bundle[mParcelledData.dataSize=48]"},
+	} {
+		got, err := parseBroadcastResult(output)
+		if err != nil {
+			t.Fatalf("%q: %v", output, err)
+		}
+		if got != want {
+			t.Errorf("%q: got %+v, want %+v", output, got, want)
+		}
+	}
+
+	if _, err := parseBroadcastResult("Broadcasting: Intent { act=x }\n"); err == nil {
+		t.Fatal("expected an error without a result line")
+	}
+	var intentErr *IntentError
+	if _, err := parseBroadcastResult("Security exception: Permission Denial: not allowed to send broadcast\n"); !errors.As(err, &intentErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestIntentError_Is(t *testing.T) {
+	for _, message := range []string{
+		"Not found; no service started.",
+		"Activity not started, unable to resolve Intent { act=x }",
+		"Activity class {a/a.B} does not exist.",
+	} {
+		if err := error(&IntentError{Op: "start", Message: message}); !errors.Is(err, ErrIntentNotResolved) {
+			t.Errorf("%q: expected ErrIntentNotResolved", message)
+		}
+	}
+	if errors.Is(&IntentError{Op: "startservice", Message: "Requires permission android.permission.BIND_JOB_SERVICE"}, ErrIntentNotResolved) {
+		t.Fatal("a permission failure is not a resolution failure")
+	}
+}
+
+func Test_focusedComponent(t *testing.T) {
+	activities := `ACTIVITY MANAGER ACTIVITIES (dumpsys activity activities)
+Display #0 (activities from top to bottom):
+  * Task{2f4d1e9 #42 type=standard A=10123:com.example U=0 visible=true mode=fullscreen}
+    topResumedActivity=ActivityRecord{8e3c2f1 u0 com.example/.ui.MainActivity t42}
+  ResumedActivity: ActivityRecord{8e3c2f1 u0 com.example/.ui.MainActivity t42}
+`
+	c, ok := focusedComponent(activities, "mResumedActivity:", "ResumedActivity:", "topResumedActivity=")
+	if !ok || c.String() != "com.example/com.example.ui.MainActivity" {
+		t.Fatalf("unexpected component: %v, %v", c, ok)
+	}
+
+	windows := `  mCurrentFocus=Window{5d1a0b u0 NotificationShade}
+  mFocusedApp=ActivityRecord{8e3c2f1 u0 com.android.settings/.Settings t7}
+`
+	c, ok = focusedComponent(windows, "mCurrentFocus=", "mFocusedApp=")
+	if !ok || c.String() != "com.android.settings/com.android.settings.Settings" {
+		t.Fatalf("unexpected component: %v, %v", c, ok)
+	}
+
+	if _, ok = focusedComponent("  mCurrentFocus=null\n", "mCurrentFocus="); ok {
+		t.Fatal("expected no component")
+	}
+}
+
+func Test_stopPackageCommand(t *testing.T) {
+	for _, tt := range []struct {
+		op, pkg string
+		user    []int
+		want    string
+	}{
+		{"force-stop", "com.example", nil, "am force-stop 'com.example'"},
+		{"force-stop", "com.example", []int{10}, "am force-stop --user 10 'com.example'"},
+		{"kill", "com.example.app_1", nil, "am kill 'com.example.app_1'"},
+	} {
+		if cmd, err := stopPackageCommand(tt.op, tt.pkg, tt.user); err != nil || cmd != tt.want {
+			t.Errorf("%s %s %v: got %q, %v, want %q", tt.op, tt.pkg, tt.user, cmd, err, tt.want)
+		}
+	}
+
+	for _, pkg := range []string{"", "com.example/.Main", "com.example; reboot"} {
+		if _, err := stopPackageCommand("force-stop", pkg, nil); err == nil || !strings.HasPrefix(err.Error(), "adb am force-stop: invalid package name") {
+			t.Errorf("%q: unexpected error %v", pkg, err)
+		}
+	}
+}
+
+func Test_amError_stopPackage(t *testing.T) {
+	// am force-stop and am kill print nothing on success, even for unknown packages.
+	if err := amError("force-stop", ""); err != nil {
+		t.Fatal(err)
+	}
+	for output, want := range map[string]string{
+		"Exception occurred while executing 'force-stop':\njava.lang.SecurityException: Permission Denial: forceStopPackage() from pid=1, uid=2000\n": "adb am force-stop: java.lang.SecurityException: Permission Denial: forceStopPackage() from pid=1, uid=2000",
+		"Security exception: Permission Denial: killBackgroundProcesses() requires android.permission.KILL_BACKGROUND_PROCESSES\n":                    "adb am force-stop: Permission Denial: killBackgroundProcesses() requires android.permission.KILL_BACKGROUND_PROCESSES",
+		"Error: user 12 does not exist\n": "adb am force-stop: user 12 does not exist",
+	} {
+		err := amError("force-stop", output)
+		var intentErr *IntentError
+		if !errors.As(err, &intentErr) || err.Error() != want {
+			t.Errorf("%q: got %v, want %s", output, err, want)
+		}
+	}
+}
+
+func Test_launchIntent(t *testing.T) {
+	intent, err := launchIntent("com.example/.MainActivity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(intent.Args(), " "); got != "-a 'android.intent.action.MAIN' -c 'android.intent.category.LAUNCHER' -n 'com.example/com.example.MainActivity'" {
+		t.Fatalf("unexpected arguments %s", got)
+	}
+
+	// A package is resolved to its launcher activity by am.
+	if intent, err = launchIntent("com.example"); err != nil || intent.Package != "com.example" || intent.Component != (ComponentName{}) {
+		t.Fatalf("unexpected intent %+v, %v", intent, err)
+	}
+
+	for _, arg := range []string{"", "com.example app", "com.example/"} {
+		if _, err = launchIntent(arg); err == nil {
+			t.Errorf("%q: expected an error", arg)
+		}
+	}
+}
+
+func Test_launchError(t *testing.T) {
+	if err := launchError(ActivityResult{Status: "ok", TotalTime: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := parseActivityResult("Starting: Intent { cmp=com.example/.Main }\nStatus: timeout\nActivity: com.example/.Main\nComplete\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = launchError(result)
+	var intentErr *IntentError
+	if !errors.As(err, &intentErr) || err.Error() != `adb am start: launch did not complete (status "timeout")` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A result without -W output has no status either.
+	if err = launchError(ActivityResult{}); err == nil || errors.Is(err, ErrIntentNotResolved) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func Test_viewIntent(t *testing.T) {
+	const link = "https://example.com/item?id=42&ref=it's#reviews"
+	for _, tt := range []struct {
+		pkg  []string
+		want string
+	}{
+		{nil, `-a 'android.intent.action.VIEW' -d 'https://example.com/item?id=42&ref=it'\''s#reviews'`},
+		{[]string{"com.example"}, `-a 'android.intent.action.VIEW' -d 'https://example.com/item?id=42&ref=it'\''s#reviews' -p 'com.example'`},
+	} {
+		intent, err := viewIntent(link, tt.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(intent.Args(), " "); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.pkg, got, tt.want)
+		}
+	}
+
+	for _, rawURL := range []string{"example.com/item", "http://[::1"} {
+		if _, err := viewIntent(rawURL, nil); err == nil {
+			t.Errorf("%q: expected an error", rawURL)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AppStorage selects an app-specific directory on external storage.
+type AppStorage int
+
+const (
+	// AppStorageOBB is /sdcard/Android/obb/<pkg>, where expansion files live.
+	AppStorageOBB AppStorage = iota
+	// AppStorageFiles is /sdcard/Android/data/<pkg>/files, returned by Context.getExternalFilesDir.
+	AppStorageFiles
+	// AppStorageMedia is /sdcard/Android/media/<pkg>, returned by Context.getExternalMediaDirs.
+	AppStorageMedia
+)
+
+// sdkScopedStorage is the first API level (Android 11) that enforces scoped storage.
+const sdkScopedStorage = 30
+
+// AppStorageDir returns the app-specific external storage directory of pkg for the given user
+// (0 if omitted).
+func (d Device) AppStorageDir(pkg string, kind AppStorage, user ...int) (string, error) {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return "", fmt.Errorf("adb app storage: invalid package name %q", pkg)
+	}
+
+	root := "/sdcard"
+	if len(user) != 0 && user[0] != 0 {
+		root = fmt.Sprintf("/storage/emulated/%d", user[0])
+	}
+
+	switch kind {
+	case AppStorageOBB:
+		return path.Join(root, "Android/obb", pkg), nil
+	case AppStorageFiles:
+		return path.Join(root, "Android/data", pkg, "files"), nil
+	case AppStorageMedia:
+		return path.Join(root, "Android/media", pkg), nil
+	default:
+		return "", errors.New("adb app storage: unknown storage kind")
+	}
+}
+
+// PushAppAsset pushes source as name into the app-specific directory of pkg, creating the
+// directory first, and returns the remote path.
+//
+// From Android 11 the directories are owned by the app itself; creating them from the shell
+// before the app has run works, but on some builds the app then cannot write to them. Launching
+// the app once before pushing avoids that. Files are pushed world-readable (0664 before Android 11,
+// where sdcardfs derives access itself; 0644 afterwards). The optional user selects the Android
+// user whose storage is used.
+func (d Device) PushAppAsset(source io.Reader, pkg string, kind AppStorage, name string, user ...int) (remotePath string, err error) {
+	if name == "" || path.IsAbs(name) || strings.HasPrefix(path.Clean(name), "..") {
+		return "", fmt.Errorf("adb app storage: invalid asset name %q", name)
+	}
+
+	var dir string
+	if dir, err = d.AppStorageDir(pkg, kind, user...); err != nil {
+		return "", err
+	}
+	remotePath = path.Join(dir, name)
+
+	var sdk int
+	if sdk, err = d.SdkVersion(); err != nil {
+		return "", err
+	}
+
+	mode := DefaultFileMode
+	if sdk >= sdkScopedStorage {
+		mode = os.FileMode(0644)
+	}
+
+	if err = d.MkdirAll(path.Dir(remotePath)); err != nil {
+		return "", err
+	}
+	if err = d.Push(source, remotePath, time.Now(), mode); err != nil {
+		return "", err
+	}
+	return remotePath, nil
+}
+
+// PushOBB pushes a local expansion file into the OBB directory of pkg and returns its remote path.
+func (d Device) PushOBB(local *os.File, pkg string, user ...int) (string, error) {
+	return d.PushAppAsset(local, pkg, AppStorageOBB, filepath.Base(local.Name()), user...)
+}
@@ -0,0 +1,30 @@
+package gadb
+
+import "testing"
+
+func TestAppStorageDir(t *testing.T) {
+	var d Device
+	for _, tt := range []struct {
+		kind AppStorage
+		user []int
+		want string
+	}{
+		{AppStorageOBB, nil, "/sdcard/Android/obb/com.example"},
+		{AppStorageFiles, nil, "/sdcard/Android/data/com.example/files"},
+		{AppStorageMedia, []int{0}, "/sdcard/Android/media/com.example"},
+		{AppStorageFiles, []int{10}, "/storage/emulated/10/Android/data/com.example/files"},
+	} {
+		if got, err := d.AppStorageDir("com.example", tt.kind, tt.user...); err != nil || got != tt.want {
+			t.Errorf("%d for %v: got %q, %v, want %q", tt.kind, tt.user, got, err, tt.want)
+		}
+	}
+
+	for _, pkg := range []string{"", "com.example/../x", "com example"} {
+		if _, err := d.AppStorageDir(pkg, AppStorageOBB); err == nil {
+			t.Errorf("%q: expected an error", pkg)
+		}
+	}
+	if _, err := d.AppStorageDir("com.example", AppStorage(7)); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,73 @@
+package gadb
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CacheBinaries caches the result of ListBinaries.
+const CacheBinaries CacheKey = "binaries"
+
+// defaultBinaryPath is searched when the device shell does not report a PATH.
+var defaultBinaryPath = []string{"/system/bin", "/system/xbin", "/vendor/bin"}
+
+// DeviceBinary is a command that can be run from the device shell.
+type DeviceBinary struct {
+	Name string
+	// Path is the absolute path of the executable. For applets it is the multiplexer, e.g. /system/bin/toybox.
+	Path string
+	// Applet is set for commands only reachable through toybox/toolbox.
+	Applet bool
+}
+
+// ListBinaries enumerates the commands available on the device: executables found in the shell's
+// PATH (in PATH order, first match wins, as the shell resolves them) followed by toybox and toolbox
+// applets not otherwise installed. The result is sorted by name.
+func (d Device) ListBinaries() ([]DeviceBinary, error) {
+	binaries, err := cached(d, CacheBinaries, d.listBinaries)
+	return slices.Clone(binaries), err
+}
+
+func (d Device) listBinaries() (binaries []DeviceBinary, err error) {
+	dirs := defaultBinaryPath
+	if resp, err := d.RunShellCommand("echo $PATH"); err == nil && strings.TrimSpace(resp) != "" {
+		dirs = strings.Split(strings.TrimSpace(resp), ":")
+	}
+
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		entries, err := d.List(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			mode := entry.FileMode()
+			if seen[entry.Name] || mode.IsDir() || mode.Perm()&0111 == 0 && mode.Type() != fs.ModeSymlink {
+				continue
+			}
+			seen[entry.Name] = true
+			binaries = append(binaries, DeviceBinary{Name: entry.Name, Path: path.Join(dir, entry.Name)})
+		}
+	}
+
+	for _, multiplexer := range []string{"toybox", "toolbox"} {
+		resp, err := d.RunShellCommand(multiplexer)
+		if err != nil || strings.Contains(resp, "not found") {
+			continue
+		}
+		muxPath := "/system/bin/" + multiplexer
+		for _, name := range strings.Fields(resp) {
+			if seen[name] || strings.ContainsAny(name, ":/") {
+				continue
+			}
+			seen[name] = true
+			binaries = append(binaries, DeviceBinary{Name: name, Path: muxPath, Applet: true})
+		}
+	}
+
+	sort.Slice(binaries, func(i, j int) bool { return binaries[i].Name < binaries[j].Name })
+	return binaries, nil
+}
@@ -0,0 +1,125 @@
+package gadb
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheKey identifies a class of cached device data.
+type CacheKey string
+
+const (
+	CacheProps    CacheKey = "props"
+	CacheFeatures CacheKey = "features"
+	CacheVersion  CacheKey = "version"
+	// CachePackages holds the results of Packages, one entry per filter. Installing,
+	// uninstalling or clearing a package through the Device drops them.
+	CachePackages CacheKey = "packages"
+)
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+type deviceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	ttls    map[CacheKey]time.Duration
+	entries map[CacheKey]cacheEntry
+}
+
+// WithCache returns a copy of the device that caches stable query results (properties,
+// features, the Android version, package lists) for ttl. Copies of the returned Device share the same cache,
+// so it can be handed to several goroutines polling the same device.
+func (d Device) WithCache(ttl time.Duration) Device {
+	d.cache = &deviceCache{
+		ttl:     ttl,
+		ttls:    map[CacheKey]time.Duration{},
+		entries: map[CacheKey]cacheEntry{},
+	}
+	return d
+}
+
+// SetCacheTTL overrides the cache lifetime for a single key. It has no effect if caching is not enabled.
+func (d Device) SetCacheTTL(key CacheKey, ttl time.Duration) {
+	if d.cache == nil {
+		return
+	}
+	d.cache.mu.Lock()
+	defer d.cache.mu.Unlock()
+	d.cache.ttls[key] = ttl
+}
+
+// InvalidateCache drops the given cached entries, or everything if no key is given. Dropping
+// CachePackages drops the package lists of every filter.
+func (d Device) InvalidateCache(keys ...CacheKey) {
+	if d.cache == nil {
+		return
+	}
+	d.cache.mu.Lock()
+	defer d.cache.mu.Unlock()
+	if len(keys) == 0 {
+		clear(d.cache.entries)
+		return
+	}
+	for key := range d.cache.entries {
+		if slices.Contains(keys, key.base()) {
+			delete(d.cache.entries, key)
+		}
+	}
+}
+
+// subKey returns the key of one of several entries cached under key, such as the package
+// list of one filter.
+func (key CacheKey) subKey(sub string) CacheKey {
+	if sub == "" {
+		return key
+	}
+	return key + " " + CacheKey(sub)
+}
+
+// base returns the key a subKey was derived from.
+func (key CacheKey) base() CacheKey {
+	base, _, _ := strings.Cut(string(key), " ")
+	return CacheKey(base)
+}
+
+func (c *deviceCache) get(key CacheKey) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *deviceCache) put(key CacheKey, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ttl, ok := c.ttls[key.base()]
+	if !ok {
+		ttl = c.ttl
+	}
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(ttl)}
+}
+
+// cached returns the cached value for key, calling fetch to fill the cache on a miss.
+// Without a cache, fetch is called every time.
+func cached[T any](d Device, key CacheKey, fetch func() (T, error)) (T, error) {
+	if d.cache == nil {
+		return fetch()
+	}
+	if v, ok := d.cache.get(key); ok {
+		return v.(T), nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	d.cache.put(key, v)
+	return v, nil
+}
@@ -0,0 +1,67 @@
+package gadb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDevice_WithCache(t *testing.T) {
+	dev := Device{serial: "test"}.WithCache(time.Minute)
+
+	calls := 0
+	fetch := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	for range 3 {
+		if v, _ := cached(dev, CacheProps, fetch); v != 1 {
+			t.Fatalf("expected cached value 1, got %d", v)
+		}
+	}
+
+	dev.InvalidateCache(CacheProps)
+	if v, _ := cached(dev, CacheProps, fetch); v != 2 {
+		t.Fatalf("expected refetch after invalidation, got %d", v)
+	}
+
+	dev.SetCacheTTL(CacheFeatures, 0)
+	cached(dev, CacheFeatures, fetch)
+	if v, _ := cached(dev, CacheFeatures, fetch); v != 4 {
+		t.Fatalf("expected zero TTL to disable caching, got %d", v)
+	}
+
+	if v, _ := cached(Device{}, CacheProps, fetch); v != 5 {
+		t.Fatalf("expected uncached device to always fetch, got %d", v)
+	}
+}
+
+func TestCacheKey_subKey(t *testing.T) {
+	dev := Device{serial: "test"}.WithCache(time.Minute)
+	dev.SetCacheTTL(CachePackages, 0)
+	if CachePackages.subKey("-3").base() != CachePackages || CachePackages.subKey("") != CachePackages {
+		t.Fatal("unexpected sub key")
+	}
+
+	calls := 0
+	fetch := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+	cached(dev, CachePackages.subKey("-3"), fetch)
+	if v, _ := cached(dev, CachePackages.subKey("-3"), fetch); v != 2 {
+		t.Fatalf("expected the TTL of CachePackages to apply to its sub keys, got %d", v)
+	}
+
+	dev.SetCacheTTL(CachePackages, time.Minute)
+	cached(dev, CachePackages.subKey("-3"), fetch)
+	cached(dev, CachePackages, fetch)
+	cached(dev, CacheProps, fetch)
+	dev.InvalidateCache(CachePackages)
+	if v, _ := cached(dev, CachePackages.subKey("-3"), fetch); v != 6 {
+		t.Fatalf("expected refetch after invalidation, got %d", v)
+	}
+	if v, _ := cached(dev, CacheProps, fetch); v != 5 {
+		t.Fatalf("expected other keys to stay cached, got %d", v)
+	}
+}
@@ -0,0 +1,137 @@
+package gadb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigPropNamespace is the ConfigKey namespace used for system properties;
+// the others are the settings namespaces "system", "secure" and "global".
+const ConfigPropNamespace = "prop"
+
+// configSeparator delimits the output of the individual commands batched into one shell call.
+const configSeparator = "--gadb-config--"
+
+// ConfigKey names a setting ("system", "secure" or "global" namespace) or a system property.
+type ConfigKey struct {
+	Namespace string
+	Key       string
+}
+
+func (k ConfigKey) String() string {
+	return k.Namespace + "/" + k.Key
+}
+
+// DefaultConfigKeys is the set captured by SnapshotConfig when no keys are given: the
+// developer options and display/power settings automation most often changes.
+var DefaultConfigKeys = []ConfigKey{
+	{"global", "window_animation_scale"},
+	{"global", "transition_animation_scale"},
+	{"global", "animator_duration_scale"},
+	{"global", "stay_on_while_plugged_in"},
+	{"global", "development_settings_enabled"},
+	{"global", "airplane_mode_on"},
+	{"global", "auto_time"},
+	{"global", "auto_time_zone"},
+	{"global", "always_finish_activities"},
+	{"system", "screen_off_timeout"},
+	{"system", "screen_brightness"},
+	{"system", "screen_brightness_mode"},
+	{"system", "accelerometer_rotation"},
+	{"system", "user_rotation"},
+	{"system", "font_scale"},
+	{"system", "show_touches"},
+	{"system", "pointer_location"},
+	{"secure", "show_ime_with_hard_keyboard"},
+	{"secure", "long_press_timeout"},
+}
+
+// ConfigValue is the captured state of a single ConfigKey.
+type ConfigValue struct {
+	ConfigKey
+	Value string
+	// Present is false when the setting did not exist (settings get printed "null") or the property was empty.
+	Present bool
+}
+
+// ConfigSnapshot is a point-in-time copy of device configuration, restorable with RestoreConfig.
+type ConfigSnapshot struct {
+	Values []ConfigValue
+}
+
+// SnapshotConfig captures the given settings and properties, or DefaultConfigKeys if none are given.
+func (d Device) SnapshotConfig(keys ...ConfigKey) (snapshot ConfigSnapshot, err error) {
+	if len(keys) == 0 {
+		keys = DefaultConfigKeys
+	}
+
+	var resp string
+	if resp, err = d.RunShellCommand(snapshotCommand(keys)); err != nil {
+		return ConfigSnapshot{}, fmt.Errorf("adb snapshot config: %w", err)
+	}
+	return parseConfigSnapshot(keys, resp)
+}
+
+// snapshotCommand returns the shell command reading keys, printing configSeparator lines
+// between their values.
+func snapshotCommand(keys []ConfigKey) string {
+	cmds := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Namespace == ConfigPropNamespace {
+			cmds[i] = fmt.Sprintf("getprop %s", shellQuote(key.Key))
+		} else {
+			cmds[i] = fmt.Sprintf("settings get %s %s", shellQuote(key.Namespace), shellQuote(key.Key))
+		}
+	}
+	return strings.Join(cmds, fmt.Sprintf("; echo %s; ", configSeparator))
+}
+
+// parseConfigSnapshot splits the output of snapshotCommand into the values of keys.
+func parseConfigSnapshot(keys []ConfigKey, resp string) (snapshot ConfigSnapshot, err error) {
+	outputs := strings.Split(strings.ReplaceAll(resp, "\r\n", "\n"), configSeparator+"\n")
+	if len(outputs) != len(keys) {
+		return ConfigSnapshot{}, fmt.Errorf("adb snapshot config: unexpected output: %s", resp)
+	}
+
+	snapshot.Values = make([]ConfigValue, len(keys))
+	for i, key := range keys {
+		value := strings.TrimSuffix(outputs[i], "\n")
+		present := value != "" && (key.Namespace == ConfigPropNamespace || value != "null")
+		if !present {
+			value = ""
+		}
+		snapshot.Values[i] = ConfigValue{ConfigKey: key, Value: value, Present: present}
+	}
+	return
+}
+
+// RestoreConfig writes every value of snapshot back to the device, deleting settings that
+// did not exist when the snapshot was taken. Properties that were empty are reset to "".
+// Restoring read-only (ro.*) or persist.* properties usually requires root.
+func (d Device) RestoreConfig(snapshot ConfigSnapshot) (err error) {
+	if len(snapshot.Values) == 0 {
+		return nil
+	}
+
+	cmds := make([]string, len(snapshot.Values))
+	for i, v := range snapshot.Values {
+		switch {
+		case v.Namespace == ConfigPropNamespace:
+			cmds[i] = fmt.Sprintf("setprop %s %s", shellQuote(v.Key), shellQuote(v.Value))
+		case v.Present:
+			cmds[i] = fmt.Sprintf("settings put %s %s %s", shellQuote(v.Namespace), shellQuote(v.Key), shellQuote(v.Value))
+		default:
+			cmds[i] = fmt.Sprintf("settings delete %s %s >/dev/null", shellQuote(v.Namespace), shellQuote(v.Key))
+		}
+	}
+
+	var resp string
+	if resp, err = d.RunShellCommand(strings.Join(cmds, "; ")); err != nil {
+		return fmt.Errorf("adb restore config: %w", err)
+	}
+	if resp = strings.TrimSpace(resp); resp != "" {
+		return fmt.Errorf("adb restore config: %s", resp)
+	}
+	d.InvalidateCache(CacheProps)
+	return
+}
@@ -0,0 +1,40 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_snapshotCommand(t *testing.T) {
+	keys := []ConfigKey{{"global", "auto_time"}, {ConfigPropNamespace, "persist.sys.locale"}}
+	want := "settings get 'global' 'auto_time'; echo --gadb-config--; getprop 'persist.sys.locale'"
+	if got := snapshotCommand(keys); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func Test_parseConfigSnapshot(t *testing.T) {
+	keys := []ConfigKey{
+		{"global", "auto_time"},
+		{"system", "font_scale"},
+		{ConfigPropNamespace, "persist.sys.locale"},
+		{ConfigPropNamespace, "persist.sys.timezone"},
+	}
+	snapshot, err := parseConfigSnapshot(keys, "1\r\n--gadb-config--\r\nnull\r\n--gadb-config--\r\nfr-FR\r\n--gadb-config--\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ConfigValue{
+		{ConfigKey: keys[0], Value: "1", Present: true},
+		{ConfigKey: keys[1]},
+		{ConfigKey: keys[2], Value: "fr-FR", Present: true},
+		{ConfigKey: keys[3]},
+	}
+	if !reflect.DeepEqual(snapshot.Values, want) {
+		t.Fatalf("got %+v, want %+v", snapshot.Values, want)
+	}
+
+	if _, err = parseConfigSnapshot(keys, "1\n--gadb-config--\nnull\n"); err == nil {
+		t.Fatal("expected an error for missing values")
+	}
+}
@@ -0,0 +1,91 @@
+package gadb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+)
+
+// DeviceProfile summarises the build of a device, taken from its system properties.
+type DeviceProfile struct {
+	Manufacturer   string   `json:"manufacturer,omitempty"`
+	Brand          string   `json:"brand,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Device         string   `json:"device,omitempty"`
+	AndroidVersion string   `json:"android_version,omitempty"`
+	SDK            int      `json:"sdk,omitempty"`
+	ABIs           []string `json:"abis,omitempty"`
+	Fingerprint    string   `json:"fingerprint,omitempty"`
+	BuildID        string   `json:"build_id,omitempty"`
+	SecurityPatch  string   `json:"security_patch,omitempty"`
+}
+
+// DeviceDescription is a JSON-serializable snapshot of what gadb knows about a device,
+// suitable for persisting in an inventory and diffing between collections.
+type DeviceDescription struct {
+	Serial   string            `json:"serial"`
+	State    DeviceState       `json:"state,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Features []string          `json:"features,omitempty"`
+	Profile  DeviceProfile     `json:"profile"`
+}
+
+// Describe collects the device's serial, listing attributes, features and build profile.
+func (d Device) Describe() (desc DeviceDescription, err error) {
+	desc = DeviceDescription{
+		Serial: d.serial,
+		State:  d.listedState,
+		Attrs:  maps.Clone(d.attrs),
+	}
+
+	if desc.Features, err = d.Features(); err != nil {
+		return DeviceDescription{}, err
+	}
+
+	var props map[string]string
+	if props, err = d.Props(); err != nil {
+		return DeviceDescription{}, err
+	}
+	desc.Profile = profileFromProps(props)
+	return desc, nil
+}
+
+func profileFromProps(props map[string]string) DeviceProfile {
+	profile := DeviceProfile{
+		Manufacturer:   props["ro.product.manufacturer"],
+		Brand:          props["ro.product.brand"],
+		Model:          props["ro.product.model"],
+		Device:         props["ro.product.device"],
+		AndroidVersion: props["ro.build.version.release"],
+		Fingerprint:    props["ro.build.fingerprint"],
+		BuildID:        props["ro.build.id"],
+		SecurityPatch:  props["ro.build.version.security_patch"],
+	}
+	profile.SDK, _ = strconv.Atoi(props["ro.build.version.sdk"])
+	profile.ABIs = abisFromProps(func(key string) string { return props[key] })
+	return profile
+}
+
+// ReadDeviceDescription decodes a DeviceDescription previously encoded as JSON.
+func ReadDeviceDescription(r io.Reader) (desc DeviceDescription, err error) {
+	if err = json.NewDecoder(r).Decode(&desc); err != nil {
+		return DeviceDescription{}, fmt.Errorf("device description: %w", err)
+	}
+	if desc.Serial == "" {
+		return DeviceDescription{}, errors.New("device description: missing serial")
+	}
+	return desc, nil
+}
+
+// DeviceFromDescription returns a Device for the serial in desc, carrying its recorded
+// attributes and state, without contacting the adb server.
+func (c Client) DeviceFromDescription(desc DeviceDescription) Device {
+	attrs := maps.Clone(desc.Attrs)
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	return Device{adbClient: c, serial: desc.Serial, attrs: attrs, listedState: desc.State}
+}
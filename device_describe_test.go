@@ -0,0 +1,52 @@
+package gadb
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_profileFromProps(t *testing.T) {
+	profile := profileFromProps(map[string]string{
+		"ro.product.manufacturer": "Google",
+		"ro.product.model":        "Pixel 8",
+		"ro.build.version.sdk":    "34",
+		"ro.product.cpu.abilist":  "arm64-v8a,armeabi-v7a",
+	})
+	want := DeviceProfile{Manufacturer: "Google", Model: "Pixel 8", SDK: 34, ABIs: []string{"arm64-v8a", "armeabi-v7a"}}
+	if !reflect.DeepEqual(profile, want) {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+}
+
+func TestReadDeviceDescription(t *testing.T) {
+	desc := DeviceDescription{
+		Serial:   "emulator-5554",
+		State:    StateOnline,
+		Attrs:    map[string]string{"model": "sdk_gphone64"},
+		Features: []string{"shell_v2", "cmd"},
+		Profile:  DeviceProfile{SDK: 34},
+	}
+	raw, err := json.Marshal(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadDeviceDescription(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, desc) {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+
+	dev := Client{}.DeviceFromDescription(got)
+	if model, _ := dev.Model(); dev.Serial() != "emulator-5554" || model != "sdk_gphone64" || dev.ListedState() != StateOnline {
+		t.Fatalf("unexpected device: %s %s %s", dev.Serial(), model, dev.ListedState())
+	}
+
+	if _, err = ReadDeviceDescription(bytes.NewReader([]byte(`{}`))); err == nil {
+		t.Fatal("expected an error for a description without serial")
+	}
+}
@@ -0,0 +1,145 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// execConn is a raw, binary-safe connection to a command started with the exec: service.
+// Reads see the command's output, writes are delivered to its stdin.
+type execConn struct {
+	tp   transport
+	ctx  context.Context
+	stop func() bool
+}
+
+// openExec starts cmd via the exec: service. The connection is closed when ctx is done.
+func (d Device) openExec(ctx context.Context, cmd string) (conn *execConn, err error) {
+	if strings.TrimSpace(cmd) == "" {
+		return nil, errors.New("adb exec: command cannot be empty")
+	}
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return nil, err
+	}
+	if err = tp.Send(fmt.Sprintf("exec:%s", cmd)); err != nil {
+		_ = tp.Close()
+		return nil, err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		_ = tp.Close()
+		return nil, err
+	}
+	// The command may legitimately be silent for long periods.
+	_ = tp.sock.SetReadDeadline(time.Time{})
+
+	conn = &execConn{tp: tp, ctx: ctx}
+	conn.stop = context.AfterFunc(ctx, func() { _ = tp.Close() })
+	return conn, nil
+}
+
+func (c *execConn) Read(p []byte) (n int, err error) {
+	n, err = c.tp.sock.Read(p)
+	if err != nil && c.ctx.Err() != nil {
+		err = c.ctx.Err()
+	}
+	return
+}
+
+func (c *execConn) Write(p []byte) (n int, err error) {
+	n, err = c.tp.sock.Write(p)
+	if err != nil && c.ctx.Err() != nil {
+		err = c.ctx.Err()
+	}
+	return
+}
+
+// Close terminates the remote command.
+func (c *execConn) Close() error {
+	c.stop()
+	return c.tp.Close()
+}
+
+// cancelReader is the read end of a pipe fed by a goroutine that runs until cancel is called.
+type cancelReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+// Close stops the goroutine and whatever remote command it reads from.
+func (r *cancelReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// shellStream returns a reader fed by copyOutput, which copies the output of a shell v2
+// command until it exits. Closing the reader or cancelling ctx calls closeConn, which must
+// make copyOutput return, and the reader then ends with io.EOF as it does after a clean
+// exit. Other failures of copyOutput are returned by the reader.
+func shellStream(ctx context.Context, op string, closeConn func(), copyOutput func(io.Writer) error) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(ctx, closeConn)
+	pr, pw := io.Pipe()
+	go func() {
+		defer cancel()
+		err := copyOutput(pw)
+		stop()
+		closeConn()
+		if err == nil || ctx.Err() != nil {
+			_ = pw.Close()
+			return
+		}
+		_ = pw.CloseWithError(fmt.Errorf("adb %s: %w", op, err))
+	}()
+	return &cancelReader{PipeReader: pr, cancel: cancel}
+}
+
+// TailFile follows the growing device file at remotePath like `tail -f`, starting from its
+// current end. The returned reader yields data as it is appended; close it, or cancel ctx,
+// to stop following.
+//
+// On devices with shell v2, tail failing, for example because remotePath does not exist or
+// can't be read, is returned by the reader as an *fs.PathError. Without it, remotePath is
+// checked with Stat before tail starts, and errors after that read as io.EOF.
+func (d Device) TailFile(ctx context.Context, remotePath string) (io.ReadCloser, error) {
+	v2, err := d.HasFeature("shell_v2")
+	if err != nil {
+		return nil, err
+	}
+	if !v2 {
+		if _, err = d.Stat(remotePath); err != nil {
+			return nil, fmt.Errorf("adb tail: %w", err)
+		}
+		conn, err := d.openExec(ctx, tailCommand(remotePath))
+		if err != nil {
+			return nil, fmt.Errorf("adb tail: %w", err)
+		}
+		return conn, nil
+	}
+
+	tp, shTp, err := d.startShellV2(tailCommand(remotePath))
+	if err != nil {
+		return nil, fmt.Errorf("adb tail: %w", err)
+	}
+	return shellStream(ctx, "tail", func() { _ = tp.Close() }, func(w io.Writer) error {
+		return copyTail(&shTp, w, remotePath)
+	}), nil
+}
+
+func tailCommand(remotePath string) string {
+	return "tail -n 0 -f " + shellQuote(remotePath)
+}
+
+// copyTail copies tail's output to dst until it exits, returning its failure as an
+// *fs.PathError built from what it printed on stderr.
+func copyTail(shTp *shellTransport, dst io.Writer, remotePath string) error {
+	var stderr bytes.Buffer
+	err := copyShellStreams(shTp, dst, &stderr)
+	return shellPathError("tail", remotePath, ShellResult{Stderr: stderr.Bytes()}, err)
+}
@@ -0,0 +1,48 @@
+package gadb
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net"
+	"testing"
+)
+
+func Test_tailCommand(t *testing.T) {
+	if cmd := tailCommand("/sdcard/my app's.log"); cmd != `tail -n 0 -f '/sdcard/my app'\''s.log'` {
+		t.Fatalf("unexpected command %s", cmd)
+	}
+}
+
+func Test_copyTail(t *testing.T) {
+	client, peer := net.Pipe()
+	defer client.Close()
+	go func() {
+		_ = WriteShellPacket(peer, ShellStdout, []byte("line 1\n"))
+		_ = WriteShellPacket(peer, ShellStderr, []byte("tail: /data/local/tmp/app.log: Permission denied\n"))
+		_ = WriteShellPacket(peer, ShellExit, []byte{1})
+		_ = peer.Close()
+	}()
+
+	var out bytes.Buffer
+	st := newShellTransport(client, 0)
+	err := copyTail(&st, &out, "/data/local/tmp/app.log")
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || !errors.Is(err, fs.ErrPermission) || pathErr.Op != "tail" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Stderr is kept out of the followed data.
+	if out.String() != "line 1\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	exited, peer2 := net.Pipe()
+	go func() {
+		_ = WriteShellPacket(peer2, ShellExit, []byte{0})
+		_ = peer2.Close()
+	}()
+	st = newShellTransport(exited, 0)
+	if err = copyTail(&st, &out, "/sdcard/f"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,238 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// deviceFileReadAhead is the minimum number of bytes fetched per ranged read.
+const deviceFileReadAhead = 64 * 1024
+
+// DeviceFile is a read-only handle on a device file that supports random access.
+// Each uncached read issues a ranged `dd` on the device, so only the bytes actually
+// needed cross the wire. It implements io.ReadSeeker and io.ReaderAt.
+type DeviceFile struct {
+	path string
+	size int64
+	// readRange reads up to count bytes of the file starting at offset.
+	readRange func(offset, count int64) ([]byte, error)
+
+	mu     sync.Mutex
+	offset int64
+	bufOff int64
+	buf    []byte
+}
+
+// OpenFile opens remotePath for random-access reading.
+func (d Device) OpenFile(remotePath string) (*DeviceFile, error) {
+	info, err := d.StatV2(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("adb open %s: is a directory", remotePath)
+	}
+	readRange := func(offset, count int64) ([]byte, error) {
+		return d.readRange(remotePath, offset, count)
+	}
+	return &DeviceFile{path: remotePath, size: int64(info.Size), readRange: readRange}, nil
+}
+
+// Name returns the remote path of the file.
+func (f *DeviceFile) Name() string {
+	return f.path
+}
+
+// Size returns the size of the file at the time it was opened.
+func (f *DeviceFile) Size() int64 {
+	return f.size
+}
+
+// Read reads from the current offset.
+func (f *DeviceFile) Read(p []byte) (n int, err error) {
+	f.mu.Lock()
+	off := f.offset
+	f.mu.Unlock()
+
+	n, err = f.ReadAt(p, off)
+	f.mu.Lock()
+	f.offset = off + int64(n)
+	f.mu.Unlock()
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return
+}
+
+// Seek sets the offset for the next Read.
+func (f *DeviceFile) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("adb seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("adb seek: negative position")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// ReadAt reads len(p) bytes starting at off. It is safe for concurrent use.
+func (f *DeviceFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("adb read: negative offset")
+	}
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= f.size {
+			return n, io.EOF
+		}
+
+		f.mu.Lock()
+		if pos >= f.bufOff && pos < f.bufOff+int64(len(f.buf)) {
+			n += copy(p[n:], f.buf[pos-f.bufOff:])
+			f.mu.Unlock()
+			continue
+		}
+		f.mu.Unlock()
+
+		var chunk []byte
+		if chunk, err = f.readRange(pos, max(int64(len(p)-n), deviceFileReadAhead)); err != nil {
+			return n, err
+		}
+		if len(chunk) == 0 {
+			// The file was opened with more bytes than dd found.
+			return n, fmt.Errorf("adb read %s: file ended after %d of %d bytes: %w", f.path, pos, f.size, io.ErrUnexpectedEOF)
+		}
+
+		f.mu.Lock()
+		f.bufOff, f.buf = pos, chunk
+		f.mu.Unlock()
+	}
+	return n, nil
+}
+
+// Close releases the handle. No device resources are held between reads.
+func (f *DeviceFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.buf = nil
+	return nil
+}
+
+// readRange reads up to count bytes of remotePath starting at offset.
+func (d Device) readRange(remotePath string, offset, count int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.copyRange(context.Background(), &buf, remotePath, offset, count); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyRange streams up to count bytes of remotePath starting at offset into dst using dd.
+// With shell v2, dd's failures, such as a missing or unreadable file or a dd too old for
+// iflag, are returned with its error message; without it they read as an empty range.
+func (d Device) copyRange(ctx context.Context, dst io.Writer, remotePath string, offset, count int64) (n int64, err error) {
+	cmd := fmt.Sprintf("dd if=%s bs=%d skip=%d count=%d iflag=skip_bytes,count_bytes",
+		shellQuote(remotePath), deviceFileReadAhead, offset, count)
+
+	var v2 bool
+	if v2, err = d.HasFeature("shell_v2"); err != nil {
+		return 0, err
+	}
+	if v2 {
+		return d.copyRangeV2(ctx, dst, remotePath, cmd)
+	}
+
+	cmd += " 2>/dev/null"
+	var conn *execConn
+	if conn, err = d.openExec(ctx, cmd); err != nil {
+		return 0, fmt.Errorf("adb read: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if n, err = io.Copy(dst, io.LimitReader(conn, count)); err != nil {
+		return n, fmt.Errorf("adb read: %w", err)
+	}
+	return n, nil
+}
+
+// copyRangeV2 runs the dd command cmd over shell v2, copying its output into dst.
+func (d Device) copyRangeV2(ctx context.Context, dst io.Writer, remotePath, cmd string) (n int64, err error) {
+	tp, shTp, err := d.startShellV2(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("adb read: %w", err)
+	}
+	defer func() { _ = tp.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = tp.Close() })
+	defer stop()
+
+	out := &countingWriter{w: dst}
+	var stderr bytes.Buffer
+	err = copyShellStreams(&shTp, out, &stderr)
+	switch {
+	case ctx.Err() != nil:
+		return out.n, fmt.Errorf("adb read: %w", ctx.Err())
+	case err != nil:
+		return out.n, shellPathError("read", remotePath, ShellResult{Stderr: stderr.Bytes()}, err)
+	}
+	return out.n, nil
+}
+
+// PullParallel is an experimental alternative to Pull for very large files: it splits
+// remotePath into streams byte ranges, reads them concurrently over separate connections
+// with ranged dd, and writes each range at its offset in dest. A single sync RECV stream
+// often cannot saturate a USB 3 link; several exec streams can.
+//
+// The file should not change during the transfer. It returns the number of bytes written.
+func (d Device) PullParallel(ctx context.Context, remotePath string, dest io.WriterAt, streams int) (written int64, err error) {
+	var info DeviceFileInfoV2
+	if info, err = d.StatV2(remotePath); err != nil {
+		return 0, err
+	}
+	size := int64(info.Size)
+	streams = max(min(streams, int(size/deviceFileReadAhead)), 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	part := (size + int64(streams) - 1) / int64(streams)
+	for off := int64(0); off < size; off += part {
+		count := min(part, size-off)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := d.copyRange(ctx, io.NewOffsetWriter(dest, off), remotePath, off, count)
+			if err == nil && n != count {
+				err = fmt.Errorf("adb read: short read at offset %d: got %d of %d bytes", off, n, count)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			written += n
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return written, firstErr
+}
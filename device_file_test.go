@@ -0,0 +1,103 @@
+package gadb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// memDeviceFile returns a DeviceFile over content, counting the ranged reads it makes.
+func memDeviceFile(content []byte, reads *int) *DeviceFile {
+	return &DeviceFile{path: "/sdcard/f", size: int64(len(content)), readRange: func(offset, count int64) ([]byte, error) {
+		*reads++
+		return bytes.Clone(content[offset:min(offset+count, int64(len(content)))]), nil
+	}}
+}
+
+func TestDeviceFile_ReadAt(t *testing.T) {
+	content := make([]byte, 3*deviceFileReadAhead+10)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	reads := 0
+	f := memDeviceFile(content, &reads)
+
+	p := make([]byte, 100)
+	if n, err := f.ReadAt(p, 1000); n != 100 || err != nil || !bytes.Equal(p, content[1000:1100]) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	// The read ahead serves nearby reads without another round trip.
+	if n, err := f.ReadAt(p, 2000); n != 100 || err != nil || !bytes.Equal(p, content[2000:2100]) || reads != 1 {
+		t.Fatalf("got %d, %v after %d reads", n, err, reads)
+	}
+
+	// A read spanning the buffered range fetches the rest.
+	big := make([]byte, 2*deviceFileReadAhead)
+	if n, err := f.ReadAt(big, 500); n != len(big) || err != nil || !bytes.Equal(big, content[500:500+len(big)]) || reads != 2 {
+		t.Fatalf("got %d, %v after %d reads", n, err, reads)
+	}
+
+	// Reads past the end are short and report io.EOF.
+	size := int64(len(content))
+	if n, err := f.ReadAt(p, size-10); n != 10 || err != io.EOF || !bytes.Equal(p[:10], content[size-10:]) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if n, err := f.ReadAt(p, size); n != 0 || err != io.EOF {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if _, err := f.ReadAt(p, -1); err == nil {
+		t.Fatal("expected an error for a negative offset")
+	}
+}
+
+func TestDeviceFile_ReadAt_truncated(t *testing.T) {
+	// The file shrank after it was opened: dd finds only 10 of its 100 bytes.
+	content := bytes.Repeat([]byte("x"), 10)
+	reads := 0
+	f := memDeviceFile(content, &reads)
+	f.size = 100
+
+	p := make([]byte, 50)
+	if n, err := f.ReadAt(p, 0); n != 10 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got %d, %v", n, err)
+	}
+	if n, err := f.ReadAt(p, 100); n != 0 || err != io.EOF {
+		t.Fatalf("got %d, %v", n, err)
+	}
+}
+
+func TestDeviceFile_Seek(t *testing.T) {
+	content := []byte("0123456789")
+	reads := 0
+	f := memDeviceFile(content, &reads)
+
+	for _, tt := range []struct {
+		offset int64
+		whence int
+		want   int64
+	}{
+		{3, io.SeekStart, 3},
+		{2, io.SeekCurrent, 5},
+		{-4, io.SeekEnd, 6},
+		{5, io.SeekEnd, 15},
+	} {
+		if pos, err := f.Seek(tt.offset, tt.whence); pos != tt.want || err != nil {
+			t.Fatalf("Seek(%d, %d) = %d, %v, want %d", tt.offset, tt.whence, pos, err, tt.want)
+		}
+	}
+	if _, err := f.Seek(-16, io.SeekCurrent); err == nil {
+		t.Fatal("expected an error for a negative position")
+	}
+	if _, err := f.Seek(0, 3); err == nil {
+		t.Fatal("expected an error for an invalid whence")
+	}
+
+	if _, err := f.Seek(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(f)
+	if err != nil || string(rest) != "6789" {
+		t.Fatalf("got %q, %v", rest, err)
+	}
+}
@@ -0,0 +1,152 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrReadOnlyFileSystem = errors.New("read-only file system")
+	ErrDirectoryNotEmpty  = errors.New("directory not empty")
+	ErrNotDirectory       = errors.New("not a directory")
+	ErrIsDirectory        = errors.New("is a directory")
+)
+
+// shellErrorMessages maps the error strings printed by toybox/toolbox to typed errors.
+var shellErrorMessages = []struct {
+	text string
+	err  error
+}{
+	{"Permission denied", fs.ErrPermission},
+	{"Operation not permitted", fs.ErrPermission},
+	{"No such file or directory", fs.ErrNotExist},
+	{"File exists", fs.ErrExist},
+	{"Read-only file system", ErrReadOnlyFileSystem},
+	{"Directory not empty", ErrDirectoryNotEmpty},
+	{"Not a directory", ErrNotDirectory},
+	{"Is a directory", ErrIsDirectory},
+}
+
+// Mkdir creates a directory on the device. It fails if the parent does not exist.
+func (d Device) Mkdir(remotePath string, perm ...os.FileMode) error {
+	return d.runFSCommand("mkdir", remotePath, "mkdir"+modeFlag(perm), shellQuote(remotePath))
+}
+
+// MkdirAll creates a directory on the device along with any missing parents.
+func (d Device) MkdirAll(remotePath string, perm ...os.FileMode) error {
+	return d.runFSCommand("mkdir", remotePath, "mkdir -p"+modeFlag(perm), shellQuote(remotePath))
+}
+
+// Remove removes a file or an empty directory on the device.
+func (d Device) Remove(remotePath string) error {
+	p := shellQuote(remotePath)
+	return d.runFSCommand("remove", remotePath, fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir %s; else rm %s; fi", p, p, p, p))
+}
+
+// RemoveAll removes remotePath and everything it contains. It returns nil if the path does not exist.
+func (d Device) RemoveAll(remotePath string) error {
+	return d.runFSCommand("remove", remotePath, "rm -rf", shellQuote(remotePath))
+}
+
+// Rename moves oldPath to newPath on the device.
+func (d Device) Rename(oldPath, newPath string) error {
+	return d.runFSCommand("rename", oldPath, "mv", shellQuote(oldPath), shellQuote(newPath))
+}
+
+// Copy copies src to dst on the device, recursing into directories.
+func (d Device) Copy(src, dst string) error {
+	return d.runFSCommand("copy", src, "cp -R", shellQuote(src), shellQuote(dst))
+}
+
+// Chmod changes the mode of remotePath, including the setuid, setgid and sticky bits.
+func (d Device) Chmod(remotePath string, mode os.FileMode) error {
+	return d.runFSCommand("chmod", remotePath, fmt.Sprintf("chmod %o", unixMode(mode)), shellQuote(remotePath))
+}
+
+// Chown changes the numeric owner and group of remotePath. A uid or gid of -1 leaves it unchanged.
+func (d Device) Chown(remotePath string, uid, gid int) error {
+	var owner string
+	switch {
+	case uid >= 0 && gid >= 0:
+		owner = fmt.Sprintf("%d:%d", uid, gid)
+	case uid >= 0:
+		owner = strconv.Itoa(uid)
+	case gid >= 0:
+		owner = fmt.Sprintf(":%d", gid)
+	default:
+		return nil
+	}
+	return d.runFSCommand("chown", remotePath, "chown", owner, shellQuote(remotePath))
+}
+
+// Touch creates remotePath if it does not exist and sets its access and modification times
+// to mtime, or to the current device time if none is given.
+func (d Device) Touch(remotePath string, mtime ...time.Time) error {
+	if len(mtime) == 0 {
+		return d.runFSCommand("touch", remotePath, "touch", shellQuote(remotePath))
+	}
+	stamp := mtime[0].UTC().Format("200601021504.05")
+	return d.runFSCommand("touch", remotePath, "TZ=UTC touch -t "+stamp, shellQuote(remotePath))
+}
+
+// unixMode converts an fs.FileMode's permission and special bits into their Unix representation.
+func unixMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= 01000
+	}
+	return m
+}
+
+func modeFlag(perm []os.FileMode) string {
+	if len(perm) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" -m %o", perm[0].Perm())
+}
+
+// runFSCommand runs a file operation and translates a failure into an *fs.PathError.
+func (d Device) runFSCommand(op, remotePath, cmd string, args ...string) error {
+	if len(args) > 0 {
+		cmd = fmt.Sprintf("%s %s", cmd, strings.Join(args, " "))
+	}
+	result, err := d.RunShellBounded(cmd, 0, 0)
+	return shellPathError(op, remotePath, result, err)
+}
+
+// shellPathError converts the outcome of a failed file command into an *fs.PathError whose
+// Err is one of the fs or gadb sentinel errors when the message is recognised.
+func shellPathError(op, remotePath string, result ShellResult, err error) error {
+	var exitErr *ExitError
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	msg := strings.TrimSpace(string(result.Stderr))
+	if msg == "" {
+		msg = strings.TrimSpace(string(result.Stdout))
+	}
+	for _, m := range shellErrorMessages {
+		if strings.Contains(msg, m.text) {
+			return &fs.PathError{Op: op, Path: remotePath, Err: m.err}
+		}
+	}
+	if msg == "" {
+		return &fs.PathError{Op: op, Path: remotePath, Err: err}
+	}
+	return &fs.PathError{Op: op, Path: remotePath, Err: errors.New(msg)}
+}
@@ -0,0 +1,37 @@
+package gadb
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func Test_shellPathError(t *testing.T) {
+	exitErr := &ExitError{Waitmsg: Waitmsg{exitStatus: 1}}
+
+	cases := []struct {
+		stderr string
+		want   error
+	}{
+		{"mkdir: '/system/foo': Read-only file system\n", ErrReadOnlyFileSystem},
+		{"rm: /data/foo: Permission denied\n", fs.ErrPermission},
+		{"mv: bad '/sdcard/none': No such file or directory\n", fs.ErrNotExist},
+		{"mkdir: '/sdcard/Download': File exists\n", fs.ErrExist},
+	}
+	for _, c := range cases {
+		err := shellPathError("op", "/p", ShellResult{Stderr: []byte(c.stderr), ExitCode: 1}, exitErr)
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || !errors.Is(err, c.want) {
+			t.Errorf("%q: got %v, want %v", c.stderr, err, c.want)
+		}
+	}
+
+	err := shellPathError("op", "/p", ShellResult{Stderr: []byte("weird failure")}, exitErr)
+	if err == nil || err.Error() != "op /p: weird failure" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := shellPathError("op", "/p", ShellResult{}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
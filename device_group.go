@@ -0,0 +1,51 @@
+package gadb
+
+import (
+	"fmt"
+)
+
+// DeviceGroup is a set of devices operated on together, such as every phone attached to
+// a test host:
+//
+//	devices, _ := client.DeviceList()
+//	group := gadb.DeviceGroup(devices)
+type DeviceGroup []Device
+
+// Serials returns the serial of every device in the group, in order.
+func (g DeviceGroup) Serials() []string {
+	serials := make([]string, len(g))
+	for i, d := range g {
+		serials[i] = d.serial
+	}
+	return serials
+}
+
+// ForwardAll forwards host port basePort+i to remote on the i-th device of the group and
+// returns the host port chosen for each serial. Ports already forwarded by someone else are
+// not rebound; if any forward fails, those established so far are removed again.
+func (g DeviceGroup) ForwardAll(remote Port, basePort int) (ports map[string]int, err error) {
+	return forwardRange(g.Serials(), basePort,
+		func(i, port int) error { return g[i].Forward(TcpPort(port), remote, true) },
+		func(i, port int) { _ = g[i].ForwardKill(TcpPort(port)) })
+}
+
+// forwardRange calls forward with host port basePort+i for the i-th of serials. If one
+// fails, kill is called for each forward made before it.
+func forwardRange(serials []string, basePort int, forward func(i, port int) error, kill func(i, port int)) (ports map[string]int, err error) {
+	if basePort <= 0 || basePort+len(serials)-1 > 65535 {
+		return nil, fmt.Errorf("adb forward: host ports %d..%d out of range", basePort, basePort+len(serials)-1)
+	}
+
+	ports = make(map[string]int, len(serials))
+	for i, serial := range serials {
+		port := basePort + i
+		if err = forward(i, port); err != nil {
+			for j := range i {
+				kill(j, basePort+j)
+			}
+			return nil, fmt.Errorf("adb forward %s tcp:%d: %w", serial, port, err)
+		}
+		ports[serial] = port
+	}
+	return ports, nil
+}
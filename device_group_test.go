@@ -0,0 +1,53 @@
+package gadb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_forwardRange(t *testing.T) {
+	serials := []string{"emulator-5554", "emulator-5556", "R58M123ABC"}
+	var forwarded, killed []int
+	forward := func(i, port int) error {
+		forwarded = append(forwarded, port)
+		return nil
+	}
+	kill := func(i, port int) { killed = append(killed, port) }
+
+	ports, err := forwardRange(serials, 27183, forward, kill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"emulator-5554": 27183, "emulator-5556": 27184, "R58M123ABC": 27185}
+	if !reflect.DeepEqual(ports, want) || len(killed) != 0 {
+		t.Fatalf("got %v, killed %v", ports, killed)
+	}
+
+	// A failed forward removes those established before it, and nothing else.
+	forwarded, killed = nil, nil
+	errBound := errors.New("cannot rebind existing socket")
+	failing := func(i, port int) error {
+		if i == 2 {
+			return errBound
+		}
+		return forward(i, port)
+	}
+	ports, err = forwardRange(serials, 27183, failing, kill)
+	if !errors.Is(err, errBound) || err.Error() != "adb forward R58M123ABC tcp:27185: cannot rebind existing socket" || ports != nil {
+		t.Fatalf("got %v, %v", ports, err)
+	}
+	if !reflect.DeepEqual(killed, []int{27183, 27184}) {
+		t.Fatalf("killed %v", killed)
+	}
+
+	for _, basePort := range []int{0, -1, 65534} {
+		forwarded = nil
+		if _, err = forwardRange(serials, basePort, forward, kill); err == nil || len(forwarded) != 0 {
+			t.Errorf("%d: got %v after forwarding %v", basePort, err, forwarded)
+		}
+	}
+	if _, err = forwardRange(serials, 65533, forward, kill); err != nil {
+		t.Fatalf("the last port is valid: %v", err)
+	}
+}
@@ -0,0 +1,592 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrIncrementalUnsupported is returned by InstallIncremental when an incremental install
+// isn't possible and a fallback to a full install was not allowed.
+var ErrIncrementalUnsupported = errors.New("incremental install not supported by device")
+
+// apkSignatureV4Version is the only .idsig format version Android understands.
+const apkSignatureV4Version = 2
+
+// The incremental serving protocol of adb's incremental_server.cpp. All integers are big
+// endian.
+const (
+	incBlockSize      = 4096
+	incHashesPerBlock = incBlockSize / sha256.Size
+	// incChunkFlushSize is how many bytes of blocks are batched into one chunk.
+	incChunkFlushSize = 31 * incBlockSize
+	// incMagic precedes every request from the device, which is then a 2-byte request type,
+	// 2-byte file id and 4-byte block index.
+	incMagic       = "INCR"
+	incRequestSize = len(incMagic) + 8
+	// incHeaderSize is the size of the header preceding each block sent: 2-byte file id,
+	// 1-byte block type, 1-byte compression type, 4-byte block index and 2-byte block size.
+	incHeaderSize = 10
+	// incPrefetchBatch is how many prefetched blocks are sent between checks for requests.
+	incPrefetchBatch = 128
+	// incMissReadAhead is how many blocks following a missed one are sent along with it.
+	incMissReadAhead = 7
+)
+
+// Request types sent by the device.
+const (
+	incServingComplete = 0
+	incBlockMissing    = 1
+	incPrefetch        = 2
+	incDestroy         = 3
+)
+
+// Block types sent to the device.
+const (
+	incTypeData = 0
+	incTypeHash = 1
+)
+
+// SupportsIncrementalInstall reports whether the device advertises incremental installs:
+// adbd must offer abb_exec and the kernel must provide incfs (ro.incremental.enable).
+func (d Device) SupportsIncrementalInstall() (bool, error) {
+	ok, err := d.HasFeature("abb_exec")
+	if err != nil || !ok {
+		return false, err
+	}
+	props, err := d.Props()
+	if err != nil {
+		return false, err
+	}
+	switch v := props["ro.incremental.enable"]; {
+	case v == "", v == "0", v == "false", v == "no":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// IncrementalInstall is an incremental install whose APK is still being served to the
+// device. The app can be started as soon as InstallIncremental returns, but reads blocks
+// that haven't arrived yet from the host, so the host must keep serving until Wait returns.
+type IncrementalInstall struct {
+	tp   transport
+	stop func() bool
+	done chan struct{}
+	err  error
+}
+
+// Wait blocks until the device has received every block of the APK, and returns the error
+// that ended serving early, if any.
+func (in *IncrementalInstall) Wait() error {
+	<-in.done
+	return in.err
+}
+
+// Close stops serving the APK. Reads of blocks the device doesn't have yet then fail until
+// the app is reinstalled.
+func (in *IncrementalInstall) Close() error {
+	if in.stop != nil {
+		in.stop()
+	}
+	err := in.tp.Close()
+	<-in.done
+	return err
+}
+
+// InstallIncremental installs the APK at apkPath, whose v4 signature is at idsigPath (the
+// apk path plus ".idsig" if empty), like `adb install --incremental`: the package manager
+// commits the install as soon as it has verified the signature, and the device fetches the
+// rest of the APK in the background, prefetching it in order and requesting blocks an app
+// reads before they arrive. Serving stops when the device has everything, ctx is done or
+// the returned IncrementalInstall is closed. opts.Progress, if set, is called as blocks are
+// served, also after InstallIncremental has returned.
+//
+// Devices that don't support incremental installs get a full streamed install if
+// allowFallback is set, which is what adb does; otherwise ErrIncrementalUnsupported is
+// returned. A rejected install is returned as a *PackageError.
+func (d Device) InstallIncremental(ctx context.Context, apkPath, idsigPath string, opts InstallOptions, allowFallback bool) (_ *IncrementalInstall, err error) {
+	if idsigPath == "" {
+		idsigPath = apkPath + ".idsig"
+	}
+	var apk, idsig *os.File
+	if apk, err = os.Open(apkPath); err != nil {
+		return nil, err
+	}
+	var info os.FileInfo
+	if info, err = apk.Stat(); err != nil {
+		_ = apk.Close()
+		return nil, err
+	}
+	if idsig, err = os.Open(idsigPath); err != nil {
+		_ = apk.Close()
+		return nil, fmt.Errorf("adb install-incremental: v4 signature: %w", err)
+	}
+	closeFiles := func() {
+		_ = apk.Close()
+		_ = idsig.Close()
+	}
+	sig, err := readSignatureV4(idsig, idsigPath, info.Size())
+	if err != nil {
+		closeFiles()
+		return nil, err
+	}
+
+	supported, err := d.SupportsIncrementalInstall()
+	if err != nil || !supported {
+		defer closeFiles()
+		if err != nil {
+			return nil, err
+		}
+		if !allowFallback {
+			return nil, fmt.Errorf("adb install-incremental: %w", ErrIncrementalUnsupported)
+		}
+		if err = d.InstallAPK(apk, info.Size(), opts); err != nil {
+			return nil, err
+		}
+		done := make(chan struct{})
+		close(done)
+		return &IncrementalInstall{done: done}, nil
+	}
+
+	args := append([]string{"package", "install-incremental"}, opts.flags()...)
+	args = append(args, fmt.Sprintf("%s:%d:0:%s:1", filepath.Base(apkPath), info.Size(), base64.StdEncoding.EncodeToString(sig.header)))
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		closeFiles()
+		return nil, err
+	}
+	if err = tp.Send("abb_exec:" + strings.Join(args, "\x00")); err == nil {
+		err = tp.VerifyResponse()
+	}
+	if err != nil {
+		_ = tp.Close()
+		closeFiles()
+		return nil, fmt.Errorf("adb install-incremental: %w", err)
+	}
+	// The device may not ask for blocks for a long while once the app is installed.
+	_ = tp.sock.SetReadDeadline(time.Time{})
+
+	file := newIncFile(apk, info.Size(), idsig, sig.treeOffset)
+	requests := make(chan incRequest, 16)
+	result := make(chan string, 1)
+	go readIncRequests(tp.sock, requests, result)
+
+	in := &IncrementalInstall{tp: tp, done: make(chan struct{})}
+	in.stop = context.AfterFunc(ctx, func() { _ = tp.Close() })
+	go func() {
+		defer close(in.done)
+		defer closeFiles()
+		defer func() {
+			_ = tp.Close()
+			// Unblock the reader, which stops once the connection is closed.
+			for range requests {
+			}
+		}()
+		server := &incServer{w: tp.sock, files: []*incFile{file}, progress: opts.Progress}
+		in.err = server.serve(requests)
+		if in.err != nil && ctx.Err() != nil {
+			in.err = ctx.Err()
+		}
+	}()
+
+	output, ok := <-result
+	if !ok {
+		// The connection ended before the package manager reported a result.
+		output = ""
+	}
+	d.InvalidateCache(CachePackages)
+	if err = parsePMResult("install-incremental", output); err != nil {
+		_ = in.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("adb install-incremental: %w", ctx.Err())
+		}
+		return nil, err
+	}
+	return in, nil
+}
+
+// signatureV4 is the parsed header of an APK signature scheme v4 file.
+type signatureV4 struct {
+	// header is the version, hashing info and signing info, which the package manager
+	// verifies the install with.
+	header []byte
+	// treeOffset is where the fs-verity Merkle tree of the APK starts in the file.
+	treeOffset int64
+}
+
+// readSignatureV4 reads the header of a v4 signature at path, checking its version and that
+// its Merkle tree fits an APK of apkSize bytes:
+//
+//	int32 version
+//	int32 size, hashing info
+//	int32 size, signing info
+//	int32 size, Merkle tree
+func readSignatureV4(r io.Reader, path string, apkSize int64) (sig signatureV4, err error) {
+	fail := func(err error) (signatureV4, error) {
+		return signatureV4{}, fmt.Errorf("adb install-incremental: v4 signature %s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	var version int32
+	if err = binary.Read(io.TeeReader(r, &buf), binary.LittleEndian, &version); err != nil {
+		return fail(err)
+	}
+	if version != apkSignatureV4Version {
+		return fail(fmt.Errorf("unsupported version %d", version))
+	}
+	for range 2 {
+		var size int32
+		if err = binary.Read(io.TeeReader(r, &buf), binary.LittleEndian, &size); err != nil {
+			return fail(err)
+		}
+		if size < 0 {
+			return fail(fmt.Errorf("invalid size %d", size))
+		}
+		if _, err = io.CopyN(&buf, r, int64(size)); err != nil {
+			return fail(err)
+		}
+	}
+	var treeSize int32
+	if err = binary.Read(r, binary.LittleEndian, &treeSize); err != nil {
+		return fail(err)
+	}
+	if want := verityTreeBlocks(apkSize) * incBlockSize; int64(treeSize) != want {
+		return fail(fmt.Errorf("Merkle tree of %d bytes, want %d for an APK of %d bytes", treeSize, want, apkSize))
+	}
+	return signatureV4{header: buf.Bytes(), treeOffset: int64(buf.Len()) + 4}, nil
+}
+
+// verityTreeBlocks returns how many blocks the fs-verity Merkle tree of a file of size
+// bytes has: one SHA-256 digest per 4 KiB block at each level, up to a single root block.
+func verityTreeBlocks(size int64) int64 {
+	if size == 0 {
+		return 0
+	}
+	var total int64
+	for blocks := (size + incBlockSize - 1) / incBlockSize; blocks > 1; {
+		blocks = (blocks + incHashesPerBlock - 1) / incHashesPerBlock
+		total += blocks
+	}
+	return total
+}
+
+// incRequest is a request of the device's data loader.
+type incRequest struct {
+	kind  int16
+	file  int16
+	block int32
+}
+
+// readIncRequests reads the connection of an incremental install, which interleaves the
+// package manager's output with requests, until it closes. It sends the output to result
+// once it has the install's outcome, or when the connection closes, and closes requests
+// when the connection closes.
+func readIncRequests(r io.Reader, requests chan<- incRequest, result chan<- string) {
+	defer close(requests)
+	var output strings.Builder
+	reported := false
+	report := func(final bool) {
+		if reported {
+			return
+		}
+		out := output.String()
+		failure := strings.Index(out, "Failure [")
+		if final || strings.Contains(out, "Success") || failure >= 0 && strings.Contains(out[failure:], "]") {
+			result <- out
+			close(result)
+			reported = true
+		}
+	}
+	defer report(true)
+
+	var buf []byte
+	chunk := make([]byte, 32*1024)
+	for {
+		for {
+			i := bytes.Index(buf, []byte(incMagic))
+			if i < 0 {
+				// Keep what may be the start of a magic split across reads.
+				keep := min(len(buf), len(incMagic)-1)
+				output.Write(buf[:len(buf)-keep])
+				buf = buf[len(buf)-keep:]
+				break
+			}
+			output.Write(buf[:i])
+			buf = buf[i:]
+			if len(buf) < incRequestSize {
+				break
+			}
+			requests <- incRequest{
+				kind:  int16(binary.BigEndian.Uint16(buf[4:])),
+				file:  int16(binary.BigEndian.Uint16(buf[6:])),
+				block: int32(binary.BigEndian.Uint32(buf[8:])),
+			}
+			buf = buf[incRequestSize:]
+		}
+		report(false)
+
+		n, err := r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if err != nil {
+			output.Write(buf)
+			return
+		}
+	}
+}
+
+// incFile is an APK being served, with its Merkle tree.
+type incFile struct {
+	data       io.ReaderAt
+	size       int64
+	tree       io.ReaderAt
+	treeOffset int64
+	sent       []bool
+	sentCount  int
+	sentTree   []bool
+}
+
+func newIncFile(data io.ReaderAt, size int64, tree io.ReaderAt, treeOffset int64) *incFile {
+	return &incFile{
+		data:       data,
+		size:       size,
+		tree:       tree,
+		treeOffset: treeOffset,
+		sent:       make([]bool, (size+incBlockSize-1)/incBlockSize),
+		sentTree:   make([]bool, verityTreeBlocks(size)),
+	}
+}
+
+// incPrefetchRange is a range of blocks of a file still to be sent unasked.
+type incPrefetchRange struct {
+	file      int
+	next, end int
+}
+
+// incServer serves the blocks of files to the data loader of an incremental install.
+type incServer struct {
+	w          io.Writer
+	files      []*incFile
+	progress   func(InstallProgress)
+	prefetches []incPrefetchRange
+	// pending holds the blocks of the next chunk.
+	pending bytes.Buffer
+	served  int64
+}
+
+// serve greets the device and answers requests until the device reports it has everything
+// or is gone.
+func (s *incServer) serve(requests <-chan incRequest) error {
+	if err := _send(s.w, []byte("OKAY")); err != nil {
+		return fmt.Errorf("adb install-incremental: %w", err)
+	}
+	doneSent := false
+	for {
+		if !doneSent && len(s.prefetches) == 0 && s.allSent() {
+			// An empty block for file -1 tells the device that everything was sent.
+			if err := s.sendBlock(-1, 0, 0, nil, true); err != nil {
+				return err
+			}
+			doneSent = true
+		}
+
+		var req incRequest
+		var ok, got bool
+		if len(s.prefetches) == 0 {
+			if err := s.flush(); err != nil {
+				return err
+			}
+			req, ok = <-requests
+			got = true
+		} else {
+			select {
+			case req, ok = <-requests:
+				got = true
+			default:
+			}
+		}
+		if got {
+			if !ok {
+				if doneSent {
+					return nil
+				}
+				return errors.New("adb install-incremental: connection closed before the APK was served")
+			}
+			if finished, err := s.handle(req); finished || err != nil {
+				return err
+			}
+		}
+		if err := s.runPrefetches(); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers req and reports whether serving is over.
+func (s *incServer) handle(req incRequest) (finished bool, err error) {
+	switch req.kind {
+	case incServingComplete, incDestroy:
+		return true, nil
+	case incBlockMissing:
+		if int(req.file) < 0 || int(req.file) >= len(s.files) || req.block < 0 || int(req.block) >= len(s.files[req.file].sent) {
+			return false, nil
+		}
+		sent, err := s.sendDataBlock(int(req.file), int(req.block), true)
+		if err != nil {
+			return false, err
+		}
+		if sent {
+			// The reader likely wants the following blocks too.
+			s.prefetches = append([]incPrefetchRange{{file: int(req.file), next: int(req.block) + 1, end: min(int(req.block)+1+incMissReadAhead, len(s.files[req.file].sent))}}, s.prefetches...)
+		}
+	case incPrefetch:
+		if int(req.file) >= 0 && int(req.file) < len(s.files) {
+			s.prefetches = append(s.prefetches, incPrefetchRange{file: int(req.file), end: len(s.files[req.file].sent)})
+		}
+	}
+	return false, nil
+}
+
+func (s *incServer) allSent() bool {
+	for _, f := range s.files {
+		if f.sentCount < len(f.sent) {
+			return false
+		}
+	}
+	return true
+}
+
+// runPrefetches sends a batch of prefetched blocks.
+func (s *incServer) runPrefetches() error {
+	budget := incPrefetchBatch
+	for len(s.prefetches) > 0 && budget > 0 {
+		p := &s.prefetches[0]
+		for ; p.next < p.end && budget > 0; p.next++ {
+			sent, err := s.sendDataBlock(p.file, p.next, false)
+			if err != nil {
+				return err
+			}
+			if sent {
+				budget--
+			}
+		}
+		if p.next >= p.end {
+			s.prefetches = s.prefetches[1:]
+		}
+	}
+	return nil
+}
+
+// sendDataBlock sends a block of a file not sent yet, preceded by the Merkle tree blocks
+// needed to verify it, and reports whether it sent it.
+func (s *incServer) sendDataBlock(fileID, block int, flush bool) (bool, error) {
+	f := s.files[fileID]
+	if block >= len(f.sent) || f.sent[block] {
+		return false, nil
+	}
+	if err := s.sendTreeBlocks(fileID, block); err != nil {
+		return false, err
+	}
+
+	data := make([]byte, min(incBlockSize, f.size-int64(block)*incBlockSize))
+	if _, err := f.data.ReadAt(data, int64(block)*incBlockSize); err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("adb install-incremental: read APK block %d: %w", block, err)
+	}
+	f.sent[block] = true
+	f.sentCount++
+	if err := s.sendBlock(fileID, incTypeData, block, data, flush); err != nil {
+		return false, err
+	}
+	s.served += int64(len(data))
+	if s.progress != nil {
+		s.progress(InstallProgress{Stage: InstallStageWriting, Written: s.served, Total: s.totalSize()})
+	}
+	return true, nil
+}
+
+// sendTreeBlocks sends the leaf of the Merkle tree covering block, and the first time the
+// whole rest of the tree, as adb does.
+func (s *incServer) sendTreeBlocks(fileID, block int) error {
+	f := s.files[fileID]
+	if f.tree == nil || len(f.sentTree) == 0 {
+		return nil
+	}
+	leaves := (len(f.sent) + incHashesPerBlock - 1) / incHashesPerBlock
+	leavesOffset := len(f.sentTree) - leaves
+	leaf := leavesOffset + block/incHashesPerBlock
+	if f.sentTree[leaf] {
+		return nil
+	}
+	if err := s.sendTreeBlock(fileID, leaf); err != nil {
+		return err
+	}
+	if leavesOffset == 0 || f.sentTree[0] {
+		return nil
+	}
+	for i := range leavesOffset {
+		if err := s.sendTreeBlock(fileID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *incServer) sendTreeBlock(fileID, index int) error {
+	f := s.files[fileID]
+	data := make([]byte, incBlockSize)
+	n, err := f.tree.ReadAt(data, f.treeOffset+int64(index)*incBlockSize)
+	if n == 0 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("adb install-incremental: read Merkle tree block %d: %w", index, err)
+	}
+	f.sentTree[index] = true
+	return s.sendBlock(fileID, incTypeHash, index, data[:n], false)
+}
+
+// sendBlock queues a block in the pending chunk, flushing it if it is full or flush is set.
+// Blocks are sent uncompressed.
+func (s *incServer) sendBlock(fileID int, kind byte, index int, data []byte, flush bool) error {
+	var header [incHeaderSize]byte
+	binary.BigEndian.PutUint16(header[0:], uint16(int16(fileID)))
+	header[2] = kind
+	binary.BigEndian.PutUint32(header[4:], uint32(index))
+	binary.BigEndian.PutUint16(header[8:], uint16(len(data)))
+	s.pending.Write(header[:])
+	s.pending.Write(data)
+	if flush || s.pending.Len() > incChunkFlushSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush sends the pending blocks as one chunk, prefixed with its size.
+func (s *incServer) flush() error {
+	if s.pending.Len() == 0 {
+		return nil
+	}
+	chunk := binary.BigEndian.AppendUint32(make([]byte, 0, 4+s.pending.Len()), uint32(s.pending.Len()))
+	chunk = append(chunk, s.pending.Bytes()...)
+	s.pending.Reset()
+	if err := _send(s.w, chunk); err != nil {
+		return fmt.Errorf("adb install-incremental: %w", err)
+	}
+	return nil
+}
+
+func (s *incServer) totalSize() (total int64) {
+	for _, f := range s.files {
+		total += f.size
+	}
+	return total
+}
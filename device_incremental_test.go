@@ -0,0 +1,201 @@
+package gadb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_verityTreeBlocks(t *testing.T) {
+	for size, want := range map[int64]int64{
+		0:                0,
+		1:                0,
+		4096:             0,
+		4097:             1,
+		128 * 4096:       1,
+		128*4096 + 1:     3,
+		128 * 128 * 4096: 129,
+	} {
+		if got := verityTreeBlocks(size); got != want {
+			t.Errorf("verityTreeBlocks(%d) = %d, want %d", size, got, want)
+		}
+	}
+}
+
+func Test_readSignatureV4(t *testing.T) {
+	le := func(n int32) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(n)) }
+	header := append(le(2), le(3)...)
+	header = append(header, "abc"...)
+	header = append(header, le(1)...)
+	header = append(header, 'x')
+	idsig := append(append(bytes.Clone(header), le(4096)...), make([]byte, 4096)...)
+
+	sig, err := readSignatureV4(bytes.NewReader(idsig), "app.apk.idsig", 2*4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig.header, header) || sig.treeOffset != int64(len(header))+4 {
+		t.Fatalf("unexpected signature %x at %d", sig.header, sig.treeOffset)
+	}
+
+	if _, err = readSignatureV4(bytes.NewReader(idsig), "app.apk.idsig", 200*4096); err == nil {
+		t.Fatal("expected an error for a tree of the wrong size")
+	}
+	if _, err = readSignatureV4(bytes.NewReader(append(le(1), idsig[4:]...)), "app.apk.idsig", 2*4096); err == nil {
+		t.Fatal("expected an error for version 1")
+	}
+	if _, err = readSignatureV4(bytes.NewReader(idsig[:10]), "app.apk.idsig", 2*4096); err == nil {
+		t.Fatal("expected an error for a truncated file")
+	}
+}
+
+func incRequestBytes(kind, file int16, block int32) []byte {
+	b := []byte(incMagic)
+	b = binary.BigEndian.AppendUint16(b, uint16(kind))
+	b = binary.BigEndian.AppendUint16(b, uint16(file))
+	return binary.BigEndian.AppendUint32(b, uint32(block))
+}
+
+func Test_readIncRequests(t *testing.T) {
+	var stream []byte
+	stream = append(stream, incRequestBytes(incPrefetch, 0, 0)...)
+	stream = append(stream, "Succ"...)
+	stream = append(stream, incRequestBytes(incBlockMissing, 0, 42)...)
+	stream = append(stream, "ess\n"...)
+	stream = append(stream, incRequestBytes(incServingComplete, 0, 0)...)
+
+	requests := make(chan incRequest, 8)
+	result := make(chan string, 1)
+	readIncRequests(iotest.OneByteReader(bytes.NewReader(stream)), requests, result)
+
+	if output := <-result; output != "Success" {
+		t.Fatalf("unexpected output %q", output)
+	}
+	var got []incRequest
+	for req := range requests {
+		got = append(got, req)
+	}
+	want := []incRequest{{kind: incPrefetch}, {kind: incBlockMissing, block: 42}, {kind: incServingComplete}}
+	if len(got) != len(want) {
+		t.Fatalf("got requests %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got requests %+v, want %+v", got, want)
+		}
+	}
+}
+
+// incBlock is a block as received by the device.
+type incBlock struct {
+	file  int16
+	kind  byte
+	index int
+	data  []byte
+}
+
+// readIncChunk reads one chunk of blocks sent by an incServer.
+func readIncChunk(r io.Reader) ([]incBlock, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(r, chunk); err != nil {
+		return nil, err
+	}
+	var blocks []incBlock
+	for len(chunk) > 0 {
+		n := int(binary.BigEndian.Uint16(chunk[8:]))
+		blocks = append(blocks, incBlock{
+			file:  int16(binary.BigEndian.Uint16(chunk)),
+			kind:  chunk[2],
+			index: int(binary.BigEndian.Uint32(chunk[4:])),
+			data:  chunk[incHeaderSize : incHeaderSize+n],
+		})
+		chunk = chunk[incHeaderSize+n:]
+	}
+	return blocks, nil
+}
+
+func Test_incServer_serve(t *testing.T) {
+	// 130 blocks, the last one short, verified by two leaves and a root.
+	apk := make([]byte, 129*incBlockSize+100)
+	for i := range apk {
+		apk[i] = byte(i / incBlockSize)
+	}
+	const treeOffset = 8
+	idsig := make([]byte, treeOffset+3*incBlockSize)
+	for i := range 3 {
+		idsig[treeOffset+i*incBlockSize] = byte(0xa0 + i)
+	}
+
+	host, device := net.Pipe()
+	defer func() { _ = device.Close() }()
+	requests := make(chan incRequest, 4)
+	var progress []InstallProgress
+	server := &incServer{
+		w:        host,
+		files:    []*incFile{newIncFile(bytes.NewReader(apk), int64(len(apk)), bytes.NewReader(idsig), treeOffset)},
+		progress: func(p InstallProgress) { progress = append(progress, p) },
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.serve(requests) }()
+
+	okay := make([]byte, 4)
+	if _, err := io.ReadFull(device, okay); err != nil || string(okay) != "OKAY" {
+		t.Fatalf("unexpected greeting %q, %v", okay, err)
+	}
+
+	// A missing block comes with its leaf, the rest of the tree and the blocks after it.
+	requests <- incRequest{kind: incBlockMissing, block: 129}
+	blocks, err := readIncChunk(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 || blocks[0].kind != incTypeHash || blocks[0].index != 2 || blocks[0].data[0] != 0xa2 ||
+		blocks[1].kind != incTypeHash || blocks[1].index != 0 || blocks[1].data[0] != 0xa0 ||
+		blocks[2].kind != incTypeData || blocks[2].index != 129 || len(blocks[2].data) != 100 || blocks[2].data[0] != 129 {
+		t.Fatalf("unexpected blocks %+v", blocks)
+	}
+
+	requests <- incRequest{kind: incPrefetch}
+	data := map[int]bool{129: true}
+	hashes := map[int]bool{0: true, 2: true}
+	for done := false; !done; {
+		blocks, err := readIncChunk(device)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range blocks {
+			switch {
+			case b.file == -1:
+				done = true
+			case b.kind == incTypeHash:
+				hashes[b.index] = true
+			case b.index < 128 && !hashes[1]:
+				t.Fatalf("block %d sent before its leaf", b.index)
+			case data[b.index]:
+				t.Fatalf("block %d sent twice", b.index)
+			case !bytes.Equal(b.data, apk[b.index*incBlockSize:min(len(apk), (b.index+1)*incBlockSize)]):
+				t.Fatalf("unexpected content of block %d", b.index)
+			default:
+				data[b.index] = true
+			}
+		}
+	}
+	if len(data) != 130 || len(hashes) != 3 {
+		t.Fatalf("got %d blocks and %d tree blocks", len(data), len(hashes))
+	}
+
+	requests <- incRequest{kind: incServingComplete}
+	if err = <-served; err != nil {
+		t.Fatal(err)
+	}
+	if last := progress[len(progress)-1]; len(progress) != 130 || last.Written != int64(len(apk)) || last.Total != int64(len(apk)) {
+		t.Fatalf("unexpected progress %d %+v", len(progress), last)
+	}
+}
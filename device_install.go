@@ -0,0 +1,438 @@
+package gadb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InstallOptions controls how an APK is installed.
+type InstallOptions struct {
+	// Replace reinstalls an existing app, keeping its data (-r).
+	Replace bool
+	// AllowDowngrade permits a lower version code than the installed one (-d).
+	AllowDowngrade bool
+	// GrantPermissions grants all runtime permissions in the manifest (-g).
+	GrantPermissions bool
+	// AllowTest allows APKs built with android:testOnly (-t).
+	AllowTest bool
+	// Instant installs the app as an instant app (--instant).
+	Instant bool
+	// ABI forces the native code ABI to install, e.g. "armeabi-v7a" on an arm64 device (--abi).
+	ABI string
+	// User installs for the given user id, "all" or "current"; empty uses the device default.
+	User string
+
+	// Progress, if set, is called as APK bytes are streamed, as the device confirms staging
+	// each split of a session, and when the install is committed.
+	Progress func(InstallProgress)
+}
+
+// InstallStage is the phase an install has reached.
+type InstallStage string
+
+const (
+	InstallStageWriting InstallStage = "writing"
+	// InstallStageStaged is reported once per split of a session, when install-write has
+	// reported staging it; Written then counts the bytes the device confirmed. The package
+	// manager reports no finer staging progress to the shell.
+	InstallStageStaged     InstallStage = "staged"
+	InstallStageCommitting InstallStage = "committing"
+	InstallStageDone       InstallStage = "done"
+)
+
+// InstallProgress reports how far an install has got. Written and Total count the bytes of
+// all APKs in the install, so Percent is meaningful across the splits of InstallMultiple.
+type InstallProgress struct {
+	Stage InstallStage
+	// Split is the name of the APK being written, if known.
+	Split   string
+	Written int64
+	Total   int64
+}
+
+// Percent returns the share of bytes written, from 0 to 100.
+func (p InstallProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Written) * 100 / float64(p.Total)
+}
+
+// progressReader reports the bytes read through it to an install progress callback.
+type progressReader struct {
+	r        io.Reader
+	progress func(InstallProgress)
+	split    string
+	// offset is the number of bytes written before this reader, total covers all of them.
+	offset, written, total int64
+}
+
+func (pr *progressReader) Read(p []byte) (n int, err error) {
+	n, err = pr.r.Read(p)
+	if n > 0 {
+		pr.written += int64(n)
+		pr.progress(InstallProgress{Stage: InstallStageWriting, Split: pr.split, Written: pr.offset + pr.written, Total: pr.total})
+	}
+	return
+}
+
+// withProgress wraps r so that reading it reports progress, if a callback is set.
+func withProgress(r io.Reader, progress func(InstallProgress), split string, offset, total int64) io.Reader {
+	if progress == nil {
+		return r
+	}
+	return &progressReader{r: r, progress: progress, split: split, offset: offset, total: total}
+}
+
+// reportInstall reports a stage other than writing, if a callback is set.
+func reportInstall(progress func(InstallProgress), stage InstallStage, total int64) {
+	if progress != nil {
+		progress(InstallProgress{Stage: stage, Written: total, Total: total})
+	}
+}
+
+// flags returns the options as pm install arguments, unquoted, for services such as abb_exec
+// that don't go through the shell.
+func (opts InstallOptions) flags() []string {
+	var flags []string
+	for _, f := range []struct {
+		set  bool
+		flag string
+	}{
+		{opts.Replace, "-r"},
+		{opts.AllowDowngrade, "-d"},
+		{opts.GrantPermissions, "-g"},
+		{opts.AllowTest, "-t"},
+		{opts.Instant, "--instant"},
+	} {
+		if f.set {
+			flags = append(flags, f.flag)
+		}
+	}
+	if opts.ABI != "" {
+		flags = append(flags, "--abi", opts.ABI)
+	}
+	if opts.User != "" {
+		flags = append(flags, "--user", opts.User)
+	}
+	return flags
+}
+
+// args returns the options as pm install arguments quoted for the device shell, each
+// preceded by a space.
+func (opts InstallOptions) args() string {
+	var b strings.Builder
+	for _, flag := range opts.flags() {
+		b.WriteString(" ")
+		if strings.HasPrefix(flag, "-") {
+			b.WriteString(flag)
+		} else {
+			b.WriteString(shellQuote(flag))
+		}
+	}
+	return b.String()
+}
+
+// PackageError is a failure reported by the package manager, e.g.
+// "Failure [INSTALL_FAILED_ALREADY_EXISTS: Attempt to re-install ...]".
+type PackageError struct {
+	Op string
+	// Code is the failure reason such as INSTALL_FAILED_VERSION_DOWNGRADE, if one was given.
+	Code    string
+	Message string
+}
+
+func (e *PackageError) Error() string {
+	switch {
+	case e.Code == "":
+		return fmt.Sprintf("adb %s: %s", e.Op, e.Message)
+	case e.Message == "":
+		return fmt.Sprintf("adb %s: %s", e.Op, e.Code)
+	default:
+		return fmt.Sprintf("adb %s: %s: %s", e.Op, e.Code, e.Message)
+	}
+}
+
+// parsePMResult turns the output of a package manager command into nil on "Success" (optionally
+// followed by details, as in "Success: streamed 1024 bytes") or a *PackageError otherwise.
+func parsePMResult(op, output string) error {
+	output = strings.TrimSpace(output)
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line == "Success" || strings.HasPrefix(line, "Success:") {
+			return nil
+		}
+	}
+
+	if i := strings.Index(output, "Failure ["); i >= 0 {
+		reason := output[i+len("Failure ["):]
+		if j := strings.LastIndex(reason, "]"); j >= 0 {
+			reason = reason[:j]
+		}
+		code, msg, _ := strings.Cut(reason, ":")
+		return &PackageError{Op: op, Code: strings.TrimSpace(code), Message: strings.TrimSpace(msg)}
+	}
+	if output == "" {
+		output = "no output from package manager"
+	}
+	return &PackageError{Op: op, Message: output}
+}
+
+// InstallAPK installs the APK read from r, which must yield exactly size bytes. On devices
+// with the cmd feature (Android 7+) the APK is streamed straight into `cmd package install -S`,
+// so it can come from memory or a network stream without touching /data/local/tmp. Older
+// devices fall back to pushing it to a temporary file and running pm install.
+//
+// A rejected install is returned as a *PackageError.
+func (d Device) InstallAPK(r io.Reader, size int64, opts InstallOptions) (err error) {
+	if size <= 0 {
+		return errors.New("adb install: APK size must be positive")
+	}
+
+	var streamed bool
+	if streamed, err = d.HasFeature("cmd"); err != nil {
+		return err
+	}
+	r = withProgress(r, opts.Progress, "", 0, size)
+	if !streamed {
+		return d.installPushed(r, size, opts)
+	}
+
+	var conn *execConn
+	if conn, err = d.openExec(context.Background(), fmt.Sprintf("cmd package install%s -S %d", opts.args(), size)); err != nil {
+		return fmt.Errorf("adb install: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	defer d.InvalidateCache(CachePackages)
+
+	var n int64
+	if n, err = io.CopyN(conn, r, size); err != nil {
+		return fmt.Errorf("adb install: wrote %d of %d bytes: %w", n, size, err)
+	}
+
+	// cmd package commits as soon as it has read the whole APK.
+	reportInstall(opts.Progress, InstallStageCommitting, size)
+	var output []byte
+	if output, err = io.ReadAll(conn); err != nil {
+		return fmt.Errorf("adb install: %w", err)
+	}
+	if err = parsePMResult("install", string(output)); err != nil {
+		return err
+	}
+	reportInstall(opts.Progress, InstallStageDone, size)
+	return nil
+}
+
+// installPushed installs r by way of a temporary file, for devices without cmd.
+func (d Device) installPushed(r io.Reader, size int64, opts InstallOptions) (err error) {
+	remote := fmt.Sprintf("/data/local/tmp/gadb-%d.apk", time.Now().UnixNano())
+	if err = d.Push(r, remote, time.Now()); err != nil {
+		return err
+	}
+	defer func() { _, _ = d.RunShellCommand("rm -f", shellQuote(remote)) }()
+
+	reportInstall(opts.Progress, InstallStageCommitting, size)
+	defer d.InvalidateCache(CachePackages)
+	var output string
+	if output, err = d.RunShellCommand("pm install"+opts.args(), shellQuote(remote)); err != nil {
+		return err
+	}
+	if err = parsePMResult("install", output); err != nil {
+		return err
+	}
+	reportInstall(opts.Progress, InstallStageDone, size)
+	return nil
+}
+
+// InstallSession is a PackageInstaller session, used to install an app made of several
+// split APKs atomically. Create one with CreateInstallSession, Write every split, then
+// Commit; Abandon discards it.
+type InstallSession struct {
+	d  Device
+	ID int
+	// streamed reports whether splits are streamed through cmd or pushed for pm.
+	streamed bool
+
+	progress func(InstallProgress)
+	// written counts the bytes of completed splits; total is the expected size of all splits,
+	// growing as splits are added when it isn't known up front.
+	written, total int64
+}
+
+// InstallSplit is one APK of a split install.
+type InstallSplit struct {
+	// Name identifies the split inside the session, e.g. "base.apk" or "split_config.arm64_v8a.apk".
+	Name string
+	R    io.Reader
+	Size int64
+}
+
+// CreateInstallSession starts a PackageInstaller session (install-create).
+func (d Device) CreateInstallSession(opts InstallOptions) (session *InstallSession, err error) {
+	session = &InstallSession{d: d, progress: opts.Progress}
+	if session.streamed, err = d.HasFeature("cmd"); err != nil {
+		return nil, err
+	}
+
+	var output string
+	if output, err = d.RunShellCommand(session.pm() + " install-create" + opts.args()); err != nil {
+		return nil, err
+	}
+	if session.ID, err = parseSessionID(output); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// parseSessionID extracts the id from "Success: created install session [1234]".
+func parseSessionID(output string) (int, error) {
+	output = strings.TrimSpace(output)
+	i, j := strings.LastIndex(output, "["), strings.LastIndex(output, "]")
+	if !strings.HasPrefix(output, "Success") || i < 0 || j < i {
+		return 0, parsePMResult("install-create", output)
+	}
+	id, err := strconv.Atoi(output[i+1 : j])
+	if err != nil {
+		return 0, fmt.Errorf("adb install-create: unexpected output: %s", output)
+	}
+	return id, nil
+}
+
+func (s *InstallSession) pm() string {
+	if s.streamed {
+		return "cmd package"
+	}
+	return "pm"
+}
+
+// Write adds a split to the session, streaming exactly size bytes from r.
+func (s *InstallSession) Write(name string, r io.Reader, size int64) (err error) {
+	if name == "" || strings.ContainsAny(name, "/ ") {
+		return fmt.Errorf("adb install-write: invalid split name %q", name)
+	}
+	if size <= 0 {
+		return errors.New("adb install-write: APK size must be positive")
+	}
+	s.total = max(s.total, s.written+size)
+	r = withProgress(r, s.progress, name, s.written, s.total)
+	if !s.streamed {
+		if err = s.writePushed(name, r, size); err == nil {
+			s.staged(name, size)
+		}
+		return err
+	}
+
+	var conn *execConn
+	cmd := fmt.Sprintf("cmd package install-write -S %d %d %s -", size, s.ID, shellQuote(name))
+	if conn, err = s.d.openExec(context.Background(), cmd); err != nil {
+		return fmt.Errorf("adb install-write: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var n int64
+	if n, err = io.CopyN(conn, r, size); err != nil {
+		return fmt.Errorf("adb install-write: wrote %d of %d bytes: %w", n, size, err)
+	}
+
+	var output []byte
+	if output, err = io.ReadAll(conn); err != nil {
+		return fmt.Errorf("adb install-write: %w", err)
+	}
+	if err = parseInstallWrite(string(output), size); err != nil {
+		return err
+	}
+	s.staged(name, size)
+	return nil
+}
+
+// staged counts a split the device has staged and reports it.
+func (s *InstallSession) staged(name string, size int64) {
+	s.written += size
+	if s.progress != nil {
+		s.progress(InstallProgress{Stage: InstallStageStaged, Split: name, Written: s.written, Total: s.total})
+	}
+}
+
+// parseInstallWrite checks the output of install-write, "Success: streamed 1234 bytes", and
+// that the device staged all size bytes of the split.
+func parseInstallWrite(output string, size int64) error {
+	if err := parsePMResult("install-write", output); err != nil {
+		return err
+	}
+	_, rest, ok := strings.Cut(output, "streamed ")
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), "bytes")), 10, 64)
+	if err == nil && n != size {
+		return fmt.Errorf("adb install-write: device staged %d of %d bytes", n, size)
+	}
+	return nil
+}
+
+// writePushed adds a split by way of a temporary file, for devices without cmd.
+func (s *InstallSession) writePushed(name string, r io.Reader, size int64) (err error) {
+	remote := fmt.Sprintf("/data/local/tmp/gadb-%d-%s", s.ID, name)
+	if err = s.d.Push(r, remote, time.Now()); err != nil {
+		return err
+	}
+	defer func() { _, _ = s.d.RunShellCommand("rm -f", shellQuote(remote)) }()
+
+	var output string
+	cmd := fmt.Sprintf("pm install-write -S %d %d %s %s", size, s.ID, shellQuote(name), shellQuote(remote))
+	if output, err = s.d.RunShellCommand(cmd); err != nil {
+		return err
+	}
+	return parseInstallWrite(output, size)
+}
+
+// Commit installs all written splits. A rejected install is returned as a *PackageError.
+func (s *InstallSession) Commit() error {
+	reportInstall(s.progress, InstallStageCommitting, s.written)
+	output, err := s.d.RunShellCommand(fmt.Sprintf("%s install-commit %d", s.pm(), s.ID))
+	s.d.InvalidateCache(CachePackages)
+	if err != nil {
+		return err
+	}
+	if err = parsePMResult("install-commit", output); err != nil {
+		return err
+	}
+	reportInstall(s.progress, InstallStageDone, s.written)
+	return nil
+}
+
+// Abandon discards the session and everything written to it.
+func (s *InstallSession) Abandon() error {
+	output, err := s.d.RunShellCommand(fmt.Sprintf("%s install-abandon %d", s.pm(), s.ID))
+	if err != nil {
+		return err
+	}
+	return parsePMResult("install-abandon", output)
+}
+
+// InstallMultiple installs splits atomically in a single session, like `adb install-multiple`.
+// The session is abandoned if any split fails to write.
+func (d Device) InstallMultiple(splits []InstallSplit, opts InstallOptions) (err error) {
+	if len(splits) == 0 {
+		return errors.New("adb install-multiple: no APKs given")
+	}
+
+	var session *InstallSession
+	if session, err = d.CreateInstallSession(opts); err != nil {
+		return err
+	}
+	for _, split := range splits {
+		session.total += split.Size
+	}
+	for _, split := range splits {
+		if err = session.Write(split.Name, split.R, split.Size); err != nil {
+			_ = session.Abandon()
+			return err
+		}
+	}
+	return session.Commit()
+}
@@ -0,0 +1,102 @@
+package gadb
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_parsePMResult(t *testing.T) {
+	if err := parsePMResult("install", "Performing Streamed Install\nSuccess\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := parsePMResult("install-write", "Success: streamed 1024 bytes\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := parsePMResult("install", "Performing Streamed Install\nadb: failed to install: Failure [INSTALL_FAILED_VERSION_DOWNGRADE: Downgrade detected]\n")
+	var pkgErr *PackageError
+	if !errors.As(err, &pkgErr) {
+		t.Fatalf("expected *PackageError, got %v", err)
+	}
+	if pkgErr.Code != "INSTALL_FAILED_VERSION_DOWNGRADE" || pkgErr.Message != "Downgrade detected" {
+		t.Fatalf("unexpected failure: %+v", pkgErr)
+	}
+
+	if err = parsePMResult("install", "Failure [INSTALL_FAILED_INVALID_APK]"); !errors.As(err, &pkgErr) || pkgErr.Code != "INSTALL_FAILED_INVALID_APK" || pkgErr.Message != "" {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+	if err = parsePMResult("install", "Error: java.lang.IllegalArgumentException"); !errors.As(err, &pkgErr) || pkgErr.Code != "" {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+}
+
+func TestInstallOptions_args(t *testing.T) {
+	got := InstallOptions{Replace: true, GrantPermissions: true, Instant: true, ABI: "armeabi-v7a", User: "10"}.args()
+	if got != " -r -g --instant --abi 'armeabi-v7a' --user '10'" {
+		t.Fatalf("unexpected args: %q", got)
+	}
+}
+
+func Test_parseSessionID(t *testing.T) {
+	id, err := parseSessionID("Success: created install session [1234567]\n")
+	if err != nil || id != 1234567 {
+		t.Fatalf("unexpected session: %d %v", id, err)
+	}
+
+	var pkgErr *PackageError
+	if _, err = parseSessionID("Failure [INSTALL_FAILED_INTERNAL_ERROR]"); !errors.As(err, &pkgErr) {
+		t.Fatalf("expected *PackageError, got %v", err)
+	}
+}
+
+func TestPackageError_Is(t *testing.T) {
+	for _, output := range []string{"Failure [not installed for 0]", "Failure [DELETE_FAILED_INTERNAL_ERROR]", "Unknown package: com.example"} {
+		if err := parsePMResult("uninstall", output); !errors.Is(err, ErrPackageNotInstalled) {
+			t.Errorf("%q: expected ErrPackageNotInstalled, got %v", output, err)
+		}
+	}
+	if err := parsePMResult("install", "Failure [DELETE_FAILED_INTERNAL_ERROR]"); errors.Is(err, ErrPackageNotInstalled) {
+		t.Errorf("unexpected match for install failure: %v", err)
+	}
+}
+
+func Test_withProgress(t *testing.T) {
+	var reports []InstallProgress
+	r := withProgress(strings.NewReader("0123456789"), func(p InstallProgress) { reports = append(reports, p) }, "split.apk", 10, 20)
+	if _, err := io.Copy(io.Discard, iotest.OneByteReader(r)); err != nil {
+		t.Fatal(err)
+	}
+
+	last := reports[len(reports)-1]
+	if len(reports) != 10 || last.Written != 20 || last.Split != "split.apk" || last.Percent() != 100 {
+		t.Fatalf("unexpected progress: %d reports, last %+v", len(reports), last)
+	}
+	if reports[0].Percent() != 55 {
+		t.Fatalf("unexpected first report: %+v", reports[0])
+	}
+
+	plain := strings.NewReader("x")
+	if withProgress(plain, nil, "", 0, 1) != io.Reader(plain) {
+		t.Fatal("expected the reader to be returned unwrapped without a callback")
+	}
+}
+
+func Test_parseInstallWrite(t *testing.T) {
+	if err := parseInstallWrite("Success: streamed 1234 bytes\n", 1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := parseInstallWrite("Success\n", 1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := parseInstallWrite("Success: streamed 1000 bytes\n", 1234); err == nil {
+		t.Fatal("expected an error for a short write")
+	}
+	var pkgErr *PackageError
+	if err := parseInstallWrite("Failure [INSTALL_FAILED_INSUFFICIENT_STORAGE]\n", 1234); !errors.As(err, &pkgErr) {
+		t.Fatalf("expected a *PackageError, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tryanks/gadb/logcat"
+)
+
+// LogcatEntries streams the device log as parsed entries, selected by opts if given. The
+// channel is closed when ctx is done or logcat ends. Timestamps are interpreted in the
+// device's time zone when it can be determined, UTC otherwise.
+func (d Device) LogcatEntries(ctx context.Context, opts ...logcat.Options) (<-chan logcat.LogEntry, error) {
+	cmd, err := logcatCommand([]string{"-v", "threadtime"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := d.openExec(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := logcat.NewParser(conn)
+	parser.Location = d.location()
+
+	entries := make(chan logcat.LogEntry, 64)
+	go func() {
+		defer close(entries)
+		defer func() { _ = conn.Close() }()
+		for {
+			entry, err := parser.Next()
+			if err != nil {
+				return
+			}
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, nil
+}
+
+// location returns the device's time zone (persist.sys.timezone), or UTC if it can't be
+// determined.
+func (d Device) location() *time.Location {
+	if zone, err := d.GetProp("persist.sys.timezone"); err == nil && zone != "" {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// LogcatBinaryEntries is LogcatEntries over the binary log format of `logcat -B`, which is
+// cheaper for high-volume capture and keeps the typed fields of event log entries. Event
+// tags are named from the device's /system/etc/event-log-tags when it can be read.
+func (d Device) LogcatBinaryEntries(ctx context.Context, opts ...logcat.Options) (<-chan logcat.BinaryEntry, error) {
+	cmd, err := logcatCommand([]string{"-B"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var eventTags map[int32]string
+	if tags, err := d.PullBytes("/system/etc/event-log-tags"); err == nil {
+		eventTags, _ = logcat.ParseEventTags(bytes.NewReader(tags))
+	}
+	conn, err := d.openExec(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := logcat.NewBinaryReader(conn)
+	reader.EventTags = eventTags
+
+	entries := make(chan logcat.BinaryEntry, 64)
+	go func() {
+		defer close(entries)
+		defer func() { _ = conn.Close() }()
+		for {
+			entry, err := reader.Next()
+			if err != nil {
+				return
+			}
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, nil
+}
+
+// LogcatStream streams the device log in logcat's default format, selected by opts if given.
+// Close the reader, or cancel ctx, to stop logcat. The reader returns io.EOF when logcat
+// exits cleanly, as it does with Options.Dump. On devices with shell v2 other exits are
+// reported as an *ExitError, *SignalError, *ExitMissingError or *TransportError; without
+// it, they and disconnects also read as io.EOF.
+func (d Device) LogcatStream(ctx context.Context, opts ...logcat.Options) (io.ReadCloser, error) {
+	cmd, err := logcatCommand(nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := d.HasFeature("shell_v2")
+	if err != nil {
+		return nil, err
+	}
+	if !v2 {
+		return d.openExec(ctx, cmd)
+	}
+
+	tp, shTp, err := d.startShellV2(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return shellStream(ctx, "logcat", func() { _ = tp.Close() }, func(w io.Writer) error {
+		return copyShellOutput(&shTp, w)
+	}), nil
+}
+
+// logcatCommand builds the logcat command line printing in format for opts.
+func logcatCommand(format []string, opts []logcat.Options) (string, error) {
+	cmd := append([]string{"logcat"}, format...)
+	if len(opts) != 0 {
+		args, err := opts[0].Args()
+		if err != nil {
+			return "", err
+		}
+		for _, arg := range args {
+			cmd = append(cmd, shellQuote(arg))
+		}
+	}
+	return strings.Join(cmd, " "), nil
+}
+
+// LogRotation caps the size of a log file written by Logcat2File.
+type LogRotation struct {
+	// MaxSize is the size in bytes at which the file is rotated; zero means no cap.
+	MaxSize int64
+	// MaxRotations is the number of rotated files kept, file.1 being the newest. With zero,
+	// the file is truncated when it reaches MaxSize.
+	MaxRotations int
+}
+
+// rotatingFile is a log file that rotates itself before a write would take it past MaxSize.
+type rotatingFile struct {
+	name     string
+	rotation LogRotation
+	f        *os.File
+	size     int64
+}
+
+func openRotatingFile(name string, rotation LogRotation) (*rotatingFile, error) {
+	r := &rotatingFile{name: name, rotation: rotation}
+	if err := r.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open(flag int) error {
+	f, err := os.OpenFile(r.name, os.O_WRONLY|os.O_CREATE|os.O_SYNC|flag, 0755)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (n int, err error) {
+	if r.rotation.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.rotation.MaxSize {
+		if err = r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate renames file.i to file.i+1, overwriting the oldest, then file to file.1, and starts
+// a new empty file.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.rotation.MaxRotations <= 0 {
+		return r.open(os.O_TRUNC)
+	}
+	for i := r.rotation.MaxRotations - 1; i >= 1; i-- {
+		err := os.Rename(r.rotatedName(i), r.rotatedName(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.name, r.rotatedName(1)); err != nil {
+		return err
+	}
+	return r.open(os.O_TRUNC)
+}
+
+func (r *rotatingFile) rotatedName(i int) string {
+	return r.name + "." + strconv.Itoa(i)
+}
+
+func (r *rotatingFile) Close() error {
+	return r.f.Close()
+}
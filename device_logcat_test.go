@@ -0,0 +1,150 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_rotatingFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "logcat")
+	if err := os.WriteFile(name, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := openRotatingFile(name, LogRotation{MaxSize: 10, MaxRotations: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		if _, err = f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		name:        "line4\n",
+		name + ".1": "line3\n",
+		name + ".2": "line2\n",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(file)
+		if err != nil || string(got) != content {
+			t.Errorf("%s: got %q, %v; want %q", filepath.Base(file), got, err, content)
+		}
+	}
+	if _, err = os.Stat(name + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no third rotation, got %v", err)
+	}
+}
+
+func Test_rotatingFile_truncate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "logcat")
+	f, err := openRotatingFile(name, LogRotation{MaxSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.Write([]byte("first\n"))
+	_, _ = f.Write([]byte("second\n"))
+	_ = f.Close()
+
+	got, _ := os.ReadFile(name)
+	if string(got) != "second\n" {
+		t.Fatalf("got %q", got)
+	}
+	if _, err = os.Stat(name + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expected no rotation, got %v", err)
+	}
+}
+
+// fakeLogcat streams lines from a fake shell v2 logcat that then exits with status exit, or
+// closes the connection without an exit status if exit is -1, or keeps running if it is -2.
+func fakeLogcat(ctx context.Context, exit int, lines ...string) io.ReadCloser {
+	client, peer := net.Pipe()
+	go func() {
+		for _, line := range lines {
+			_ = WriteShellPacket(peer, ShellStdout, []byte(line))
+		}
+		switch exit {
+		case -2:
+			return
+		case -1:
+		default:
+			_ = WriteShellPacket(peer, ShellExit, []byte{byte(exit)})
+		}
+		_ = peer.Close()
+	}()
+	st := newShellTransport(client, 0)
+	return shellStream(ctx, "logcat", func() { _ = client.Close() }, func(w io.Writer) error {
+		return copyShellOutput(&st, w)
+	})
+}
+
+func Test_shellStream(t *testing.T) {
+	const line = "10-14 08:00:00.000  1234  1234 I ActivityManager: start\n"
+	var exitErr *ExitError
+	var signalErr *SignalError
+	for _, tt := range []struct {
+		name  string
+		exit  int
+		check func(error) bool
+	}{
+		{"clean exit", 0, func(err error) bool { return err == nil }},
+		{"exit status", 1, func(err error) bool {
+			return errors.As(err, &exitErr) && exitErr.ExitStatus() == 1 && err.Error() == "adb logcat: unexpected error code 1"
+		}},
+		{"killed", 128 + 9, func(err error) bool {
+			return errors.As(err, &signalErr) && signalErr.Signal() == syscall.SIGKILL && errors.As(err, &exitErr)
+		}},
+		{"disconnect", -1, func(err error) bool { return errors.As(err, new(*ExitMissingError)) }},
+	} {
+		stream := fakeLogcat(context.Background(), tt.exit, line, line)
+		output, err := io.ReadAll(stream)
+		if string(output) != line+line || !tt.check(err) {
+			t.Errorf("%s: got %q, %v", tt.name, output, err)
+		}
+		_ = stream.Close()
+	}
+
+	// Cancelling ends a running logcat like a clean exit.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := fakeLogcat(ctx, -2, line)
+	p := make([]byte, len(line))
+	if _, err := io.ReadFull(stream, p); err != nil || string(p) != line {
+		t.Fatalf("got %q, %v", p, err)
+	}
+	cancel()
+	if n, err := stream.Read(p); n != 0 || err != io.EOF {
+		t.Fatalf("got %d, %v after cancel", n, err)
+	}
+}
+
+func Test_copyLogcat(t *testing.T) {
+	var out bytes.Buffer
+	if err := copyLogcat(&out, strings.NewReader("a\n"), make(chan bool), func() {}); !errors.Is(err, ErrLogcatEnded) || out.String() != "a\n" {
+		t.Fatalf("got %q, %v", out.String(), err)
+	}
+
+	errBroken := errors.New("broken")
+	if err := copyLogcat(&out, iotest.ErrReader(errBroken), make(chan bool), func() {}); err != errBroken {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Stopping through exitChan cancels the stream and returns nil.
+	pr, pw := io.Pipe()
+	exitChan := make(chan bool, 1)
+	exitChan <- true
+	if err := copyLogcat(&out, pr, exitChan, func() { _ = pw.Close() }); err != nil {
+		t.Fatal(err)
+	}
+}
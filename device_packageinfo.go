@@ -0,0 +1,172 @@
+package gadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PackageInfo describes an installed package, as reported by `dumpsys package`.
+type PackageInfo struct {
+	Name        string
+	VersionName string
+	VersionCode int64
+	UID         int
+	MinSDK      int
+	TargetSDK   int
+	CodePath    string
+	// FirstInstallTime and LastUpdateTime are the device's wall-clock times; dumpsys prints
+	// them without a zone, so they are returned in UTC.
+	FirstInstallTime time.Time
+	LastUpdateTime   time.Time
+	// Installer is the package that installed this one, e.g. com.android.vending; empty for
+	// packages installed via adb on most builds.
+	Installer            string
+	RequestedPermissions []string
+	// GrantedPermissions holds the install-time and runtime permissions currently granted.
+	GrantedPermissions []string
+	// SigningCertDigests are the short certificate digests dumpsys prints in its signatures
+	// line; they identify a signer but are not full SHA-256 fingerprints.
+	SigningCertDigests []string
+}
+
+// dumpsysTimeLayout is the format of install timestamps printed by dumpsys package.
+const dumpsysTimeLayout = "2006-01-02 15:04:05"
+
+// PackageInfo returns details of the installed package pkg. The error wraps
+// ErrPackageNotInstalled if the package isn't installed.
+func (d Device) PackageInfo(pkg string) (PackageInfo, error) {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return PackageInfo{}, fmt.Errorf("adb package info: invalid package name %q", pkg)
+	}
+	resp, err := d.Dumpsys("package", pkg)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+	return parsePackageInfo(pkg, resp)
+}
+
+// parsePackageInfo parses the "Package [pkg]" section of dumpsys package output. The
+// "requested permissions:" list is recognised by its deeper indentation; granted permissions
+// are collected from the "name: granted=true" entries of the install and runtime lists.
+func parsePackageInfo(pkg, resp string) (info PackageInfo, err error) {
+	lines := strings.Split(strings.ReplaceAll(resp, "\r\n", "\n"), "\n")
+	start, depth := -1, 0
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "Package ["+pkg+"]") {
+			start, depth = i+1, indentOf(line)
+			break
+		}
+	}
+	if start < 0 {
+		return PackageInfo{}, fmt.Errorf("adb package info %s: %w", pkg, ErrPackageNotInstalled)
+	}
+
+	info.Name = pkg
+	var inRequested bool
+	var listDepth int
+	granted := map[string]bool{}
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := indentOf(line)
+		if indent <= depth {
+			break
+		}
+		if inRequested && indent > listDepth {
+			// Newer builds append attributes, e.g. "android.permission.CAMERA, restricted=true".
+			name, _, _ := strings.Cut(trimmed, ",")
+			info.RequestedPermissions = append(info.RequestedPermissions, strings.TrimSuffix(name, ":"))
+			continue
+		}
+		inRequested = false
+
+		if trimmed == "requested permissions:" {
+			inRequested, listDepth = true, indent
+			continue
+		}
+
+		if name, rest, ok := strings.Cut(trimmed, ": granted="); ok {
+			if strings.HasPrefix(rest, "true") && !granted[name] {
+				granted[name] = true
+				info.GrantedPermissions = append(info.GrantedPermissions, name)
+			}
+			continue
+		}
+		for _, field := range dumpsysFields(trimmed) {
+			key, val, _ := strings.Cut(field, "=")
+			info.setField(key, val)
+		}
+	}
+	return info, nil
+}
+
+// setField records one key=value pair of the package section, keeping the first value seen.
+func (info *PackageInfo) setField(key, val string) {
+	switch key {
+	case "versionName":
+		if info.VersionName == "" {
+			info.VersionName = val
+		}
+	case "versionCode":
+		if info.VersionCode == 0 {
+			info.VersionCode, _ = strconv.ParseInt(val, 10, 64)
+		}
+	case "userId", "appId":
+		if info.UID == 0 {
+			info.UID, _ = strconv.Atoi(val)
+		}
+	case "minSdk":
+		info.MinSDK, _ = strconv.Atoi(val)
+	case "targetSdk":
+		info.TargetSDK, _ = strconv.Atoi(val)
+	case "codePath":
+		info.CodePath = val
+	case "installerPackageName":
+		info.Installer = val
+	case "firstInstallTime":
+		if info.FirstInstallTime.IsZero() {
+			info.FirstInstallTime, _ = time.Parse(dumpsysTimeLayout, val)
+		}
+	case "lastUpdateTime":
+		if info.LastUpdateTime.IsZero() {
+			info.LastUpdateTime, _ = time.Parse(dumpsysTimeLayout, val)
+		}
+	case "signatures":
+		if i := strings.Index(val, "signatures:["); i >= 0 && info.SigningCertDigests == nil {
+			digests := val[i+len("signatures:["):]
+			digests, _, _ = strings.Cut(digests, "]")
+			for _, digest := range strings.Split(digests, ",") {
+				if digest = strings.TrimSpace(digest); digest != "" {
+					info.SigningCertDigests = append(info.SigningCertDigests, digest)
+				}
+			}
+		}
+	}
+}
+
+// dumpsysFields splits a line of "key=value" pairs. Values may contain spaces when they are
+// timestamps or braced structures, so a field only ends before the next "key=".
+func dumpsysFields(line string) []string {
+	if !strings.Contains(line, "=") {
+		return nil
+	}
+	words := strings.Split(line, " ")
+	fields := make([]string, 0, len(words))
+	for _, word := range words {
+		key, _, ok := strings.Cut(word, "=")
+		if len(fields) == 0 || (ok && key != "" && !strings.ContainsAny(key, "{}[],:")) {
+			fields = append(fields, word)
+		} else {
+			fields[len(fields)-1] += " " + word
+		}
+	}
+	return fields
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
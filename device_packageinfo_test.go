@@ -0,0 +1,70 @@
+package gadb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const dumpsysPackageSample = `Activity Resolver Table:
+  Non-Data Actions:
+      android.intent.action.MAIN:
+        5c1f2a com.example/.MainActivity filter 9e8d7c
+
+Packages:
+  Package [com.example] (3a4b5c6):
+    userId=10123
+    pkg=Package{7d8e9f0 com.example}
+    codePath=/data/app/~~q1w2/com.example-e3r4
+    versionCode=42 minSdk=24 targetSdk=34
+    versionName=1.2.3
+    signatures=PackageSignatures{5c1f2a version:2, signatures:[7f3e0a1b], past signatures:[]}
+    installerPackageName=com.android.vending
+    timeStamp=2024-01-02 03:04:05
+    lastUpdateTime=2024-02-03 04:05:06
+    requested permissions:
+      android.permission.INTERNET
+      android.permission.CAMERA
+      android.permission.POST_NOTIFICATIONS, restricted=true
+    install permissions:
+      android.permission.INTERNET: granted=true
+    User 0: ceDataInode=12345 installed=true hidden=false
+      firstInstallTime=2024-01-02 03:04:05
+      runtime permissions:
+        android.permission.CAMERA: granted=false, flags=[ USER_SET ]
+        android.permission.POST_NOTIFICATIONS: granted=true, flags=[ USER_SET ]
+
+Hidden system packages:
+  Package [com.example] (1a2b3c4):
+    versionName=1.0.0
+`
+
+func Test_parsePackageInfo(t *testing.T) {
+	info, err := parsePackageInfo("com.example", dumpsysPackageSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PackageInfo{
+		Name:                 "com.example",
+		VersionName:          "1.2.3",
+		VersionCode:          42,
+		UID:                  10123,
+		MinSDK:               24,
+		TargetSDK:            34,
+		CodePath:             "/data/app/~~q1w2/com.example-e3r4",
+		FirstInstallTime:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastUpdateTime:       time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+		Installer:            "com.android.vending",
+		RequestedPermissions: []string{"android.permission.INTERNET", "android.permission.CAMERA", "android.permission.POST_NOTIFICATIONS"},
+		GrantedPermissions:   []string{"android.permission.INTERNET", "android.permission.POST_NOTIFICATIONS"},
+		SigningCertDigests:   []string{"7f3e0a1b"},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Fatalf("unexpected info:\n got %+v\nwant %+v", info, want)
+	}
+
+	if _, err = parsePackageInfo("com.missing", "Unable to find package: com.missing\n"); !errors.Is(err, ErrPackageNotInstalled) {
+		t.Fatalf("expected ErrPackageNotInstalled, got %v", err)
+	}
+}
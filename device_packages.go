@@ -0,0 +1,203 @@
+package gadb
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PackageFilter selects and decorates the output of Packages. The zero value lists every
+// installed package by name.
+type PackageFilter struct {
+	// ThirdParty and System restrict the listing to third-party (-3) or system (-s) packages.
+	ThirdParty bool
+	System     bool
+	// Enabled and Disabled restrict the listing to enabled (-e) or disabled (-d) packages.
+	Enabled  bool
+	Disabled bool
+	// UID, if non-zero, lists only packages running under that uid (--uid).
+	UID int
+	// User lists packages of the given user id (--user); empty uses the device default.
+	User string
+
+	// IncludePath reports each package's base APK path (-f).
+	IncludePath bool
+	// IncludeVersionCode reports each package's version code (--show-versioncode, Android 9+).
+	IncludeVersionCode bool
+	// IncludeUID reports each package's uid (-U, Android 8+).
+	IncludeUID bool
+}
+
+func (f PackageFilter) args() string {
+	var b strings.Builder
+	for _, flag := range []struct {
+		set  bool
+		flag string
+	}{
+		{f.ThirdParty, " -3"},
+		{f.System, " -s"},
+		{f.Enabled, " -e"},
+		{f.Disabled, " -d"},
+		{f.IncludePath, " -f"},
+		{f.IncludeVersionCode, " --show-versioncode"},
+		{f.IncludeUID, " -U"},
+	} {
+		if flag.set {
+			b.WriteString(flag.flag)
+		}
+	}
+	if f.UID != 0 {
+		fmt.Fprintf(&b, " --uid %d", f.UID)
+	}
+	if f.User != "" {
+		b.WriteString(" --user " + shellQuote(f.User))
+	}
+	return b.String()
+}
+
+// Package is an installed package as listed by Packages. Path, VersionCode and UID are only
+// set when requested through the PackageFilter.
+type Package struct {
+	Name        string
+	Path        string
+	VersionCode int64
+	UID         int
+}
+
+// Packages lists installed packages matching filter, using `pm list packages`. With
+// WithCache the listing of each filter is kept for the CachePackages lifetime, or until a
+// package is installed, uninstalled or cleared through the Device.
+func (d Device) Packages(filter PackageFilter) ([]Package, error) {
+	args := filter.args()
+	pkgs, err := cached(d, CachePackages.subKey(strings.TrimSpace(args)), func() ([]Package, error) {
+		resp, err := d.RunShellCommand("pm list packages" + args)
+		if err != nil {
+			return nil, err
+		}
+		return parsePackageList(resp)
+	})
+	return slices.Clone(pkgs), err
+}
+
+// parsePackageList parses lines such as
+//
+//	package:/data/app/~~q1w2/com.example-e3r4/base.apk=com.example versionCode:42 uid:10123
+func parsePackageList(resp string) ([]Package, error) {
+	packages := make([]Package, 0)
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		rest, ok := strings.CutPrefix(line, "package:")
+		if !ok {
+			if line != "" {
+				return nil, fmt.Errorf("adb list packages: %s", line)
+			}
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		var pkg Package
+		pkg.Name = fields[0]
+		if i := strings.LastIndex(pkg.Name, "="); i >= 0 {
+			pkg.Path, pkg.Name = pkg.Name[:i], pkg.Name[i+1:]
+		}
+		for _, field := range fields[1:] {
+			key, val, _ := strings.Cut(field, ":")
+			switch key {
+			case "versionCode":
+				pkg.VersionCode, _ = strconv.ParseInt(val, 10, 64)
+			case "uid":
+				pkg.UID, _ = strconv.Atoi(val)
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// ClearAppData deletes all data of pkg, like a fresh install, for the given user (the device
+// default if omitted). Any running process of the app is killed. A failure is returned as a
+// *PackageError.
+func (d Device) ClearAppData(pkg string, user ...int) error {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return fmt.Errorf("adb clear: invalid package name %q", pkg)
+	}
+	cmd := "pm clear"
+	if len(user) != 0 {
+		cmd += fmt.Sprintf(" --user %d", user[0])
+	}
+	output, err := d.RunShellCommand(cmd, shellQuote(pkg))
+	d.InvalidateCache(CachePackages)
+	if err != nil {
+		return err
+	}
+	return parsePMResult("clear", output)
+}
+
+// APKPaths returns the on-device paths of the APKs making up pkg: the base APK followed by
+// any splits. The error wraps ErrPackageNotInstalled if the package isn't installed.
+func (d Device) APKPaths(pkg string) ([]string, error) {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return nil, fmt.Errorf("adb pm path: invalid package name %q", pkg)
+	}
+	resp, err := d.RunShellCommand("pm path", shellQuote(pkg))
+	if err != nil {
+		return nil, err
+	}
+	return parsePMPath(pkg, resp)
+}
+
+// parsePMPath parses the "package:/data/app/.../base.apk" lines of `pm path`.
+func parsePMPath(pkg, resp string) ([]string, error) {
+	var paths []string
+	for _, line := range strings.Split(resp, "\n") {
+		if p, ok := strings.CutPrefix(strings.TrimSpace(line), "package:"); ok && p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("adb pm path %s: %w", pkg, ErrPackageNotInstalled)
+	}
+	return paths, nil
+}
+
+// PullAPK copies every APK of the installed package pkg into destDir, which is created if
+// needed, and returns the local paths in the order reported by APKPaths.
+func (d Device) PullAPK(pkg string, destDir string) (files []string, err error) {
+	var remotes []string
+	if remotes, err = d.APKPaths(pkg); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	for _, remote := range remotes {
+		local := filepath.Join(destDir, path.Base(remote))
+		if err = d.pullToFile(remote, local); err != nil {
+			return files, err
+		}
+		files = append(files, local)
+	}
+	return files, nil
+}
+
+// pullToFile pulls remotePath into a newly created local file, removing it again on failure.
+func (d Device) pullToFile(remotePath, localPath string) (err error) {
+	var f *os.File
+	if f, err = os.Create(localPath); err != nil {
+		return err
+	}
+	if err = d.Pull(remotePath, f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(localPath)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,77 @@
+package gadb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_parsePackageList(t *testing.T) {
+	resp := "package:/data/app/~~q1w2==/com.example-e3r4==/base.apk=com.example versionCode:42 uid:10123\r\n" +
+		"package:com.android.shell\n\n"
+	got, err := parsePackageList(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Package{
+		{Name: "com.example", Path: "/data/app/~~q1w2==/com.example-e3r4==/base.apk", VersionCode: 42, UID: 10123},
+		{Name: "com.android.shell"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected packages: %+v", got)
+	}
+
+	if _, err = parsePackageList("Error: Unknown option: --show-versioncode"); err == nil {
+		t.Fatal("expected an error for pm failure output")
+	}
+}
+
+func TestPackageFilter_args(t *testing.T) {
+	got := PackageFilter{ThirdParty: true, IncludePath: true, UID: 10123}.args()
+	if got != " -3 -f --uid 10123" {
+		t.Fatalf("unexpected args: %q", got)
+	}
+}
+
+func Test_parsePMResult_clear(t *testing.T) {
+	if err := parsePMResult("clear", "Success\n"); err != nil {
+		t.Fatal(err)
+	}
+	for output, want := range map[string]string{
+		// pm clear prints a bare "Failed" for unknown packages and when the clear was refused.
+		"Failed\n": "Failed",
+		"Exception occurred while executing 'clear':\njava.lang.SecurityException: PID 1234 does not have permission android.permission.CLEAR_APP_USER_DATA\n": "Exception occurred while executing 'clear':\njava.lang.SecurityException: PID 1234 does not have permission android.permission.CLEAR_APP_USER_DATA",
+		"": "no output from package manager",
+	} {
+		err := parsePMResult("clear", output)
+		var pkgErr *PackageError
+		if !errors.As(err, &pkgErr) || pkgErr.Op != "clear" || pkgErr.Code != "" || pkgErr.Message != want {
+			t.Errorf("%q: unexpected error %+v", output, err)
+		}
+	}
+}
+
+func Test_parsePMPath(t *testing.T) {
+	resp := "package:/data/app/~~q1w2/com.example-e3r4/base.apk\r\n" +
+		"package:/data/app/~~q1w2/com.example-e3r4/split_config.arm64_v8a.apk\n" +
+		"package:/data/app/~~q1w2/com.example-e3r4/split_config.xxhdpi.apk\n"
+	paths, err := parsePMPath("com.example", resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/data/app/~~q1w2/com.example-e3r4/base.apk",
+		"/data/app/~~q1w2/com.example-e3r4/split_config.arm64_v8a.apk",
+		"/data/app/~~q1w2/com.example-e3r4/split_config.xxhdpi.apk",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("got %q, want %q", paths, want)
+	}
+
+	// pm path prints nothing, and exits with 1, for a package that isn't installed.
+	for _, resp := range []string{"", "package:\n"} {
+		if _, err = parsePMPath("com.example", resp); !errors.Is(err, ErrPackageNotInstalled) {
+			t.Errorf("%q: unexpected error %v", resp, err)
+		}
+	}
+}
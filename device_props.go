@@ -0,0 +1,182 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Props returns all system properties reported by getprop, parsed in one call. With
+// WithCache, they are fetched once per CacheProps lifetime.
+func (d Device) Props() (map[string]string, error) {
+	props, err := d.props()
+	return maps.Clone(props), err
+}
+
+// props is Props without the copy; the map must not be modified.
+func (d Device) props() (map[string]string, error) {
+	return cached(d, CacheProps, func() (map[string]string, error) {
+		resp, err := d.RunShellCommand("getprop")
+		if err != nil {
+			return nil, err
+		}
+		return parseGetprop(resp), nil
+	})
+}
+
+// GetProp returns the value of the system property key, or "" if it is unset. With
+// WithCache it is looked up in the cached Props instead of querying the device.
+func (d Device) GetProp(key string) (string, error) {
+	if d.cache == nil {
+		return d.getProp(key)
+	}
+	props, err := d.props()
+	return props[key], err
+}
+
+// getProp reads a property from the device, bypassing the cache, for values that change
+// while being waited on.
+func (d Device) getProp(key string) (string, error) {
+	resp, err := d.RunShellCommand("getprop", shellQuote(key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.ReplaceAll(resp, "\r\n", "\n"), "\n"), nil
+}
+
+// SetProp sets the system property key to value and drops the cached Props. Properties
+// other than ro.* ones are limited to 91 bytes, ro.* ones can only be set once, and many
+// namespaces need root; the device's refusal is returned as an error.
+func (d Device) SetProp(key, value string) error {
+	output, err := d.RunShellCommand("setprop", shellQuote(key), shellQuote(value))
+	d.InvalidateCache(CacheProps)
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb setprop %s: %s", key, output)
+	}
+	return nil
+}
+
+// PropBool returns the boolean property key, parsed like Android's GetBoolProperty: "1",
+// "y", "yes", "on" and "true" are true, "0", "n", "no", "off" and "false" are false, and
+// anything else, including an unset property, gives def.
+func (d Device) PropBool(key string, def bool) (bool, error) {
+	value, err := d.GetProp(key)
+	if err != nil {
+		return def, err
+	}
+	if b, ok := parseBoolProp(value); ok {
+		return b, nil
+	}
+	return def, nil
+}
+
+// PropInt returns the integer property key, or def if it is unset or not an integer.
+func (d Device) PropInt(key string, def int) (int, error) {
+	value, err := d.GetProp(key)
+	if err != nil {
+		return def, err
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return n, nil
+	}
+	return def, nil
+}
+
+// WaitForProperty polls until the system property key has the given value, waiting for the
+// device to come online first, or until ctx is done.
+func (d Device) WaitForProperty(ctx context.Context, key, value string) error {
+	return d.waitForProps(ctx, key, value)
+}
+
+// WaitBootComplete waits until the device is online and has finished booting, as reported by
+// sys.boot_completed, such as after a reboot or while an emulator starts. dev.bootcomplete is
+// not checked: it is set by init.rc scripts that some vendors and emulator images leave out.
+// The device cache is dropped once it has booted.
+func (d Device) WaitBootComplete(ctx context.Context) error {
+	if err := d.waitForProps(ctx, "sys.boot_completed", "1"); err != nil {
+		return err
+	}
+	d.InvalidateCache()
+	return nil
+}
+
+// waitForProps polls every second until the device is online and has each key, value pair
+// of properties set.
+func (d Device) waitForProps(ctx context.Context, pairs ...string) error {
+	online := func() bool {
+		state, err := d.State()
+		return err == nil && state == StateOnline
+	}
+	return pollProps(ctx, time.Second, online, d.getProp, pairs)
+}
+
+// pollProps polls every interval until online reports true and getProp returns each key,
+// value pair of pairs, or until ctx is done.
+func pollProps(ctx context.Context, interval time.Duration, online func() bool, getProp func(string) (string, error), pairs []string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if online() && hasProps(getProp, pairs) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("adb wait for %s=%s: %w", pairs[0], pairs[1], ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func hasProps(getProp func(string) (string, error), pairs []string) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if value, err := getProp(pairs[i]); err != nil || value != pairs[i+1] {
+			return false
+		}
+	}
+	return true
+}
+
+func parseBoolProp(value string) (b, ok bool) {
+	switch value {
+	case "1", "y", "yes", "on", "true":
+		return true, true
+	case "0", "n", "no", "off", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// parseGetprop parses "[key]: [value]" lines, joining values that span several lines.
+func parseGetprop(resp string) map[string]string {
+	props := map[string]string{}
+	var key string
+	var value strings.Builder
+	inValue := false
+	for _, line := range strings.Split(strings.ReplaceAll(resp, "\r\n", "\n"), "\n") {
+		if inValue {
+			value.WriteString("\n")
+		} else {
+			k, v, ok := strings.Cut(line, "]: [")
+			if !ok || !strings.HasPrefix(k, "[") {
+				continue
+			}
+			key, line = k[1:], v
+			value.Reset()
+		}
+		if strings.HasSuffix(line, "]") {
+			value.WriteString(strings.TrimSuffix(line, "]"))
+			props[key] = value.String()
+			inValue = false
+			continue
+		}
+		value.WriteString(line)
+		inValue = true
+	}
+	return props
+}
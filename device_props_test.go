@@ -0,0 +1,83 @@
+package gadb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_parseGetprop(t *testing.T) {
+	resp := "[ro.build.version.sdk]: [34]\r\n[ro.product.model]: [Pixel 8]\n[multi.line]: [first\nsecond]\n[empty]: []\n"
+	props := parseGetprop(resp)
+
+	expected := map[string]string{
+		"ro.build.version.sdk": "34",
+		"ro.product.model":     "Pixel 8",
+		"multi.line":           "first\nsecond",
+		"empty":                "",
+	}
+	if len(props) != len(expected) {
+		t.Fatalf("unexpected props: %v", props)
+	}
+	for k, v := range expected {
+		if props[k] != v {
+			t.Errorf("%s: got %q, want %q", k, props[k], v)
+		}
+	}
+}
+
+func Test_parseBoolProp(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, "yes": true, "on": true, "true": true, "0": false, "n": false, "off": false} {
+		if b, ok := parseBoolProp(value); !ok || b != want {
+			t.Errorf("%q: got %v, %v", value, b, ok)
+		}
+	}
+	for _, value := range []string{"", "TRUE", "2"} {
+		if _, ok := parseBoolProp(value); ok {
+			t.Errorf("%q: expected no boolean", value)
+		}
+	}
+}
+
+func Test_hasProps(t *testing.T) {
+	props := map[string]string{"sys.boot_completed": "1", "init.svc.bootanim": "running"}
+	getProp := func(key string) (string, error) { return props[key], nil }
+
+	if !hasProps(getProp, []string{"sys.boot_completed", "1"}) {
+		t.Fatal("expected sys.boot_completed=1")
+	}
+	if hasProps(getProp, []string{"sys.boot_completed", "1", "init.svc.bootanim", "stopped"}) {
+		t.Fatal("expected every pair to be checked")
+	}
+	if hasProps(getProp, []string{"dev.bootcomplete", "1"}) {
+		t.Fatal("an unset property matches nothing but the empty value")
+	}
+	failing := func(string) (string, error) { return "1", errors.New("closed") }
+	if hasProps(failing, []string{"sys.boot_completed", "1"}) {
+		t.Fatal("expected a failed getprop to count as unset")
+	}
+}
+
+func Test_pollProps(t *testing.T) {
+	// The device comes online on the second poll and finishes booting on the fourth.
+	polls := 0
+	online := func() bool { polls++; return polls >= 2 }
+	getProp := func(string) (string, error) {
+		if polls >= 4 {
+			return "1", nil
+		}
+		return "0", nil
+	}
+	if err := pollProps(context.Background(), time.Millisecond, online, getProp, []string{"sys.boot_completed", "1"}); err != nil || polls != 4 {
+		t.Fatalf("got %v after %d polls", err, polls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	never := func() bool { return false }
+	err := pollProps(ctx, time.Millisecond, never, getProp, []string{"sys.boot_completed", "1"})
+	if !errors.Is(err, context.DeadlineExceeded) || err.Error() != "adb wait for sys.boot_completed=1: context deadline exceeded" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
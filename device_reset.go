@@ -0,0 +1,116 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultResetTimeout bounds how long ResetConnection waits for the device to come back.
+var DefaultResetTimeout = 30 * time.Second
+
+// ResetOptions controls ResetConnection.
+type ResetOptions struct {
+	// RestartAdbd restarts adbd on the device in its current mode: usb: for USB devices,
+	// tcpip:<port> for devices connected over TCP. This also re-enumerates a USB connection.
+	RestartAdbd bool
+	// Timeout bounds waiting for the device to come back online; DefaultResetTimeout if zero.
+	Timeout time.Duration
+}
+
+// ResetConnection applies the usual recipe for unwedging a device connection: it removes the
+// device's forwards and reverses, optionally restarts adbd, reconnects the transport (adb
+// reconnect for USB, disconnect and connect for TCP), then waits for the device to be online
+// again. Cached device information is invalidated.
+func (d Device) ResetConnection(ctx context.Context, opts ...ResetOptions) (err error) {
+	var o ResetOptions
+	if len(opts) != 0 {
+		o = opts[0]
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultResetTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	host, port, tcp := d.tcpAddress()
+
+	// Reverses live on the device, so they can only be removed while it still answers.
+	_ = d.ReverseKillAll()
+	if _, err = d.adbClient.executeCommand(fmt.Sprintf("host-serial:%s:killforward-all", d.serial), true); err != nil {
+		return fmt.Errorf("adb reset %s: kill forwards: %w", d.serial, err)
+	}
+
+	if o.RestartAdbd {
+		service := "usb:"
+		if tcp {
+			service = fmt.Sprintf("tcpip:%d", port)
+		}
+		// adbd drops the connection as it restarts, so a broken response is expected.
+		_, _ = d.executeCommand(service, true)
+		_ = d.waitForDisconnect(ctx)
+	}
+
+	if tcp {
+		_ = d.adbClient.Disconnect(host, port)
+		if err = d.reconnectTCP(ctx, host, port); err != nil {
+			return fmt.Errorf("adb reset %s: %w", d.serial, err)
+		}
+	} else if _, err = d.adbClient.executeCommand(fmt.Sprintf("host-serial:%s:reconnect", d.serial)); err != nil {
+		return fmt.Errorf("adb reset %s: reconnect: %w", d.serial, err)
+	}
+
+	if err = d.waitForState(ctx, StateOnline); err != nil {
+		return fmt.Errorf("adb reset %s: %w", d.serial, err)
+	}
+	d.InvalidateCache()
+	return nil
+}
+
+// tcpAddress reports whether the device is connected over TCP, i.e. its serial is host:port.
+func (d Device) tcpAddress() (host string, port int, ok bool) {
+	host, p, err := net.SplitHostPort(d.serial)
+	if err != nil {
+		return "", 0, false
+	}
+	if port, err = strconv.Atoi(p); err != nil {
+		return "", 0, false
+	}
+	return host, port, true
+}
+
+// reconnectTCP retries adb connect until it succeeds or ctx is done; adbd may take a few
+// seconds to listen again after a restart.
+func (d Device) reconnectTCP(ctx context.Context, host string, port int) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		err := d.adbClient.Connect(host, port)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("connect: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForState polls until the device reports want.
+func (d Device) waitForState(ctx context.Context, want DeviceState) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if state, err := d.State(); err == nil && state == want {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %w", want, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package gadb
+
+import "testing"
+
+func Test_tcpAddress(t *testing.T) {
+	if host, port, ok := (Device{serial: "192.168.1.20:5555"}).tcpAddress(); !ok || host != "192.168.1.20" || port != 5555 {
+		t.Fatalf("unexpected address: %s %d %v", host, port, ok)
+	}
+	for _, serial := range []string{"emulator-5554", "0123456789ABCDEF", "adb-R58N._adb-tls-connect._tcp"} {
+		if _, _, ok := (Device{serial: serial}).tcpAddress(); ok {
+			t.Errorf("%s: unexpectedly treated as TCP", serial)
+		}
+	}
+}
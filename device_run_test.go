@@ -0,0 +1,21 @@
+package gadb
+
+import "testing"
+
+func Test_execCommandLine(t *testing.T) {
+	got, err := execCommandLine("/data/local/tmp/pkg.test", ExecOptions{
+		Args: []string{"-test.v", "-test.run", "TestFoo|TestBar"},
+		Env:  []string{"GODEBUG=gctrace=1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `cd '/data/local/tmp' && env 'GODEBUG=gctrace=1' '/data/local/tmp/pkg.test' '-test.v' '-test.run' 'TestFoo|TestBar'`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	if _, err = execCommandLine("/bin/true", ExecOptions{Env: []string{"NOVALUE"}}); err == nil {
+		t.Fatal("expected an error for an entry without '='")
+	}
+}
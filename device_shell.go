@@ -0,0 +1,135 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrShellTimeout is returned when a bounded shell command does not finish within its timeout.
+var ErrShellTimeout = errors.New("adb shell: command timed out")
+
+// ShellResult holds the outcome of a shell command run with RunShellBounded.
+type ShellResult struct {
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the remote exit status, or -1 if the command was stopped before reporting one.
+	ExitCode int
+	// Signal is the signal that killed the remote command, or 0 if it exited on its own.
+	Signal syscall.Signal
+	// Truncated is set when output exceeded the limit and the remote command was killed.
+	Truncated bool
+	// TimedOut is set when the command was killed because it ran past its timeout.
+	TimedOut bool
+}
+
+// RunShellBounded runs cmd over the shell v2 protocol, keeping at most maxOutput bytes of
+// combined stdout/stderr and waiting at most timeout for the command to exit.
+// If either limit is hit, the connection is closed, which kills the remote command.
+// A non-positive maxOutput or timeout disables the corresponding limit.
+//
+// Hitting the output limit is not an error; check ShellResult.Truncated. Otherwise the error
+// tells apart why the command did not succeed, and is returned alongside the partial result:
+//
+//	ErrShellTimeout    the command ran past timeout and was killed
+//	*SignalError       the command was killed by a signal on the device (e.g. by the OOM killer);
+//	                   it also unwraps to the *ExitError of its status
+//	*ExitError         the command exited with a non-zero status
+//	*TransportError    the connection to the device broke
+//	*ExitMissingError  the connection closed cleanly without an exit status
+func (d Device) RunShellBounded(cmd string, maxOutput int, timeout time.Duration) (result ShellResult, err error) {
+	result.ExitCode = -1
+	if d.normalizeOutput {
+		defer func() {
+			result.Stdout = NormalizeOutput(result.Stdout)
+			result.Stderr = NormalizeOutput(result.Stderr)
+		}()
+	}
+	if strings.TrimSpace(cmd) == "" {
+		return result, errors.New("adb shell: command cannot be empty")
+	}
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return result, err
+	}
+	defer func() { _ = tp.Close() }()
+
+	if err = tp.Send(fmt.Sprintf("shell,v2,raw:%s", cmd)); err != nil {
+		return result, err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		return result, err
+	}
+
+	var shTp shellTransport
+	if shTp, err = tp.CreateShellTransport(); err != nil {
+		return result, err
+	}
+	if err = shTp.Send(ShellCloseStdin, []byte{}); err != nil {
+		return result, err
+	}
+
+	var timedOut atomic.Bool
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			_ = shTp.Close()
+		})
+		defer timer.Stop()
+	}
+
+	for {
+		msgType, data, rErr := shTp.Read()
+		if rErr != nil {
+			if timedOut.Load() {
+				result.TimedOut = true
+				return result, ErrShellTimeout
+			}
+			if rErr == io.EOF {
+				return result, &ExitMissingError{}
+			}
+			return result, &TransportError{Err: rErr}
+		}
+
+		switch msgType {
+		case ShellStdout:
+			result.Stdout = result.appendBounded(result.Stdout, data, maxOutput)
+		case ShellStderr:
+			result.Stderr = result.appendBounded(result.Stderr, data, maxOutput)
+		case ShellExit:
+			if len(data) == 0 {
+				return result, &ExitMissingError{}
+			}
+			result.ExitCode = int(data[0])
+			result.Signal = Waitmsg{exitStatus: result.ExitCode}.Signal()
+			return result, exitStatusError(result.ExitCode)
+		default:
+			if _, err = handleShellPacket(msgType, data); err != nil {
+				return result, err
+			}
+		}
+
+		if result.Truncated {
+			debugLog(fmt.Sprintf("shell output exceeded %d bytes, killing: %s", maxOutput, cmd))
+			return result, nil
+		}
+	}
+}
+
+// appendBounded appends data to dst without letting the combined output grow past maxOutput.
+func (r *ShellResult) appendBounded(dst, data []byte, maxOutput int) []byte {
+	if maxOutput <= 0 {
+		return append(dst, data...)
+	}
+	remaining := maxOutput - len(r.Stdout) - len(r.Stderr)
+	if len(data) > remaining {
+		r.Truncated = true
+		data = data[:max(remaining, 0)]
+	}
+	return append(dst, data...)
+}
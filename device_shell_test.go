@@ -0,0 +1,48 @@
+package gadb
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestShellResult_appendBounded(t *testing.T) {
+	var result ShellResult
+	result.Stdout = result.appendBounded(result.Stdout, []byte("hello"), 8)
+	result.Stderr = result.appendBounded(result.Stderr, []byte("world"), 8)
+
+	if string(result.Stdout) != "hello" || string(result.Stderr) != "wor" {
+		t.Fatalf("unexpected output: %q %q", result.Stdout, result.Stderr)
+	}
+	if !result.Truncated {
+		t.Fatal("expected result to be truncated")
+	}
+
+	unbounded := ShellResult{}
+	unbounded.Stdout = unbounded.appendBounded(unbounded.Stdout, []byte("hello"), 0)
+	if unbounded.Truncated || string(unbounded.Stdout) != "hello" {
+		t.Fatalf("unexpected unbounded output: %q", unbounded.Stdout)
+	}
+}
+
+func Test_exitStatusError(t *testing.T) {
+	if err := exitStatusError(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var exitErr *ExitError
+	if err := exitStatusError(1); !errors.As(err, &exitErr) || exitErr.ExitStatus() != 1 {
+		t.Fatalf("expected *ExitError, got %v", err)
+	}
+
+	var signalErr *SignalError
+	if err := exitStatusError(137); !errors.As(err, &signalErr) || signalErr.Signal() != syscall.SIGKILL {
+		t.Fatalf("expected *SignalError for SIGKILL, got %v", err)
+	}
+
+	// A script that exits 130 itself is indistinguishable from one killed by SIGINT, and
+	// callers checking for *ExitError must still see its status.
+	if err := exitStatusError(130); !errors.As(err, &exitErr) || exitErr.ExitStatus() != 130 {
+		t.Fatalf("expected *ExitError for status 130, got %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLargeFileUnsupported is returned when the device supports neither the sync v2
+// stat/list requests nor a shell stat, so file sizes above 4 GiB cannot be represented.
+var ErrLargeFileUnsupported = errors.New("device cannot report file sizes above 4 GiB")
+
+// shellStatFormat matches the fields of DeviceFileInfoV2, in order, for toybox stat -c.
+const shellStatFormat = "%s %f %X %Y %Z %u %g %i %h %d %n"
+
+// statBatchSize bounds the number of paths passed to a single stat invocation.
+const statBatchSize = 64
+
+// DeviceFileInfoV2 is the 64-bit counterpart of DeviceFileInfo, as reported by the
+// sync v2 STA2/LIS2 requests. Unlike DeviceFileInfo, its Size is exact for files over 4 GiB.
+type DeviceFileInfoV2 struct {
+	Name string
+	// Mode is the raw Unix mode, like DeviceFileInfo.Mode; use FileMode for an fs.FileMode.
+	Mode         os.FileMode
+	Size         uint64
+	UID          uint32
+	GID          uint32
+	Device       uint64
+	Inode        uint64
+	Links        uint32
+	LastAccessed time.Time
+	LastModified time.Time
+	LastChanged  time.Time
+}
+
+func (info DeviceFileInfoV2) IsDir() bool {
+	return uint32(info.Mode)&unixModeType == unixModeDir
+}
+
+// FileMode converts the raw Unix mode into an fs.FileMode.
+func (info DeviceFileInfoV2) FileMode() fs.FileMode {
+	return DeviceFileInfo{Mode: info.Mode}.FileMode()
+}
+
+// StatV2 returns 64-bit file information about remotePath, following symlinks. It uses STA2
+// when the device supports it and falls back to the device's stat -L command otherwise.
+// The returned error wraps fs.ErrNotExist if the path does not exist.
+func (d Device) StatV2(remotePath string) (info DeviceFileInfoV2, err error) {
+	var ok bool
+	if ok, err = d.HasFeature("stat_v2"); err != nil {
+		return DeviceFileInfoV2{}, err
+	}
+	if !ok {
+		return d.shellStat(remotePath)
+	}
+	return d.syncStatV2(remotePath)
+}
+
+// syncStatV2 stats remotePath with STA2, which needs the stat_v2 feature.
+func (d Device) syncStatV2(remotePath string) (info DeviceFileInfoV2, err error) {
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return DeviceFileInfoV2{}, err
+	}
+	defer func() { _ = tp.Close() }()
+
+	var sync syncTransport
+	if sync, err = tp.CreateSyncTransport(); err != nil {
+		return DeviceFileInfoV2{}, err
+	}
+	defer func() { _ = sync.Close() }()
+
+	if err = sync.Send("STA2", remotePath); err != nil {
+		return DeviceFileInfoV2{}, err
+	}
+	var st syncStatV2
+	if st, err = sync.ReadStatV2(); err != nil {
+		return DeviceFileInfoV2{}, err
+	}
+	if st.Error != 0 {
+		return DeviceFileInfoV2{}, &fs.PathError{Op: "stat", Path: remotePath, Err: errnoError(st.Error)}
+	}
+	return st.fileInfo(path.Base(remotePath)), nil
+}
+
+// ListV2 lists remotePath with 64-bit file information, using LIS2 when the device
+// supports it and falling back to LIST plus the device's stat command otherwise.
+func (d Device) ListV2(remotePath string) (infos []DeviceFileInfoV2, err error) {
+	var ok bool
+	if ok, err = d.HasFeature("ls_v2"); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return d.shellListV2(remotePath)
+	}
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = tp.Close() }()
+
+	var sync syncTransport
+	if sync, err = tp.CreateSyncTransport(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = sync.Close() }()
+
+	if err = sync.Send("LIS2", remotePath); err != nil {
+		return nil, err
+	}
+
+	infos = make([]DeviceFileInfoV2, 0)
+	for {
+		entry, done, err := sync.ReadDirectoryEntryV2()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return infos, nil
+		}
+		infos = append(infos, entry)
+	}
+}
+
+func (d Device) shellStat(remotePath string) (DeviceFileInfoV2, error) {
+	// -L follows symlinks, as STA2 does; LIST and LIS2 entries, like shellListV2's, don't.
+	result, err := d.RunShellBounded(fmt.Sprintf("stat -L -c %s -- %s", shellQuote(shellStatFormat), shellQuote(remotePath)), 0, 0)
+	if err != nil {
+		return DeviceFileInfoV2{}, d.shellStatError(remotePath, result, err)
+	}
+	info, err := parseShellStat(strings.TrimSpace(string(result.Stdout)))
+	if err != nil {
+		return DeviceFileInfoV2{}, fmt.Errorf("adb stat: %w", err)
+	}
+	info.Name = path.Base(remotePath)
+	return info, nil
+}
+
+func (d Device) shellListV2(remotePath string) (infos []DeviceFileInfoV2, err error) {
+	var entries []DeviceFileInfo
+	if entries, err = d.List(remotePath); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name != "." && entry.Name != ".." {
+			names = append(names, entry.Name)
+		}
+	}
+
+	infos = make([]DeviceFileInfoV2, 0, len(names))
+	for len(names) > 0 {
+		n := min(len(names), statBatchSize)
+		quoted := make([]string, n)
+		for i := range quoted {
+			quoted[i] = shellQuote(names[i])
+		}
+		names = names[n:]
+
+		cmd := fmt.Sprintf("cd %s && stat -c %s -- %s", shellQuote(remotePath), shellQuote(shellStatFormat), strings.Join(quoted, " "))
+		result, err := d.RunShellBounded(cmd, 0, 0)
+		if err != nil {
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || len(result.Stdout) == 0 {
+				return nil, d.shellStatError(remotePath, result, err)
+			}
+			// Entries that vanished between LIST and stat are simply skipped.
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(result.Stdout)), "\n") {
+			if info, err := parseShellStat(line); err == nil {
+				infos = append(infos, info)
+			}
+		}
+	}
+	return infos, nil
+}
+
+func (d Device) shellStatError(remotePath string, result ShellResult, err error) error {
+	if strings.Contains(string(result.Stderr), "not found") || strings.Contains(string(result.Stderr), "Unknown option") {
+		return fmt.Errorf("adb stat %s: %w", remotePath, ErrLargeFileUnsupported)
+	}
+	return shellPathError("stat", remotePath, result, err)
+}
+
+// parseShellStat parses one line of stat -c output produced with shellStatFormat.
+func parseShellStat(line string) (info DeviceFileInfoV2, err error) {
+	fields := strings.SplitN(line, " ", 11)
+	if len(fields) != 11 {
+		return DeviceFileInfoV2{}, fmt.Errorf("unexpected stat output: %q", line)
+	}
+
+	var n [10]uint64
+	for i := range n {
+		base := 10
+		if i == 1 {
+			base = 16
+		}
+		if n[i], err = strconv.ParseUint(fields[i], base, 64); err != nil {
+			return DeviceFileInfoV2{}, fmt.Errorf("unexpected stat output: %q", line)
+		}
+	}
+
+	return DeviceFileInfoV2{
+		Name:         fields[10],
+		Size:         n[0],
+		Mode:         os.FileMode(n[1]),
+		LastAccessed: time.Unix(int64(n[2]), 0),
+		LastModified: time.Unix(int64(n[3]), 0),
+		LastChanged:  time.Unix(int64(n[4]), 0),
+		UID:          uint32(n[5]),
+		GID:          uint32(n[6]),
+		Inode:        n[7],
+		Links:        uint32(n[8]),
+		Device:       n[9],
+	}, nil
+}
+
+// errnoError maps the Linux errno values reported by sync v2 to fs errors where possible.
+func errnoError(errno uint32) error {
+	switch errno {
+	case 1, 13: // EPERM, EACCES
+		return fs.ErrPermission
+	case 2: // ENOENT
+		return fs.ErrNotExist
+	case 20: // ENOTDIR
+		return ErrNotDirectory
+	default:
+		return fmt.Errorf("errno %d", errno)
+	}
+}
+
+// Exists reports whether remotePath exists on the device, with a single sync stat request;
+// see followStat for how symlinks and unreadable paths are treated.
+func (d Device) Exists(remotePath string) (bool, error) {
+	_, err := d.followStat(remotePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IsDir reports whether remotePath exists and is a directory, or on devices with stat_v2 a
+// symlink to one, such as /sdcard.
+func (d Device) IsDir(remotePath string) (bool, error) {
+	mode, err := d.followStat(remotePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil && mode.IsDir(), err
+}
+
+// IsFile reports whether remotePath exists and is a regular file, or on devices with stat_v2
+// a symlink to one.
+func (d Device) IsFile(remotePath string) (bool, error) {
+	mode, err := d.followStat(remotePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil && mode.IsRegular(), err
+}
+
+// followStat returns the mode of remotePath with STA2 where the device has stat_v2
+// (Android 10+), which follows symlinks and reports unreadable paths as an error wrapping
+// fs.ErrPermission. Older devices only have the sync v1 STAT, which is lstat: a symlink,
+// like /sdcard on many of them, is neither a directory nor a file, and a path that can't be
+// read counts as missing.
+func (d Device) followStat(remotePath string) (fs.FileMode, error) {
+	ok, err := d.HasFeature("stat_v2")
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		info, err := d.syncStatV2(remotePath)
+		return info.FileMode(), err
+	}
+	v1, err := d.Stat(remotePath)
+	return v1.FileMode(), err
+}
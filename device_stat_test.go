@@ -0,0 +1,22 @@
+package gadb
+
+import (
+	"testing"
+)
+
+func Test_parseShellStat(t *testing.T) {
+	info, err := parseShellStat("6442450944 81b4 1700000000 1700000100 1700000200 10123 1015 4242 1 64773 big file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 6442450944 || info.Name != "big file.bin" || info.IsDir() {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.FileMode().Perm() != 0664 || info.UID != 10123 || info.LastModified.Unix() != 1700000100 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+
+	if _, err = parseShellStat("garbage"); err == nil {
+		t.Fatal("expected error for malformed output")
+	}
+}
@@ -0,0 +1,117 @@
+package gadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StorageStats describes the usage of a mounted filesystem, in bytes.
+type StorageStats struct {
+	Filesystem string
+	MountPoint string
+	Total      uint64
+	Used       uint64
+	Free       uint64
+}
+
+// StorageStats returns the usage of every mounted filesystem as reported by df.
+func (d Device) StorageStats() ([]StorageStats, error) {
+	resp, err := d.RunShellCommand("df -k")
+	if err == nil && !strings.Contains(resp, "1K-blocks") {
+		// toolbox df (pre-Marshmallow) does not understand -k and prints human-readable sizes.
+		resp, err = d.RunShellCommand("df")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDf(resp)
+}
+
+// parseDf parses both toybox `df -k` output (Filesystem 1K-blocks Used Available Use% Mounted on)
+// and legacy toolbox `df` output (Filesystem Size Used Free Blksize).
+func parseDf(resp string) (stats []StorageStats, err error) {
+	lines := strings.Split(strings.ReplaceAll(resp, "\r\n", "\n"), "\n")
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("adb df: empty output")
+	}
+	header := strings.Fields(lines[0])
+	toybox := len(header) > 1 && header[1] == "1K-blocks"
+
+	var pending string
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Long device names make toybox wrap the remaining columns onto the next line.
+		if len(fields) == 1 && toybox {
+			pending = fields[0]
+			continue
+		}
+		if pending != "" {
+			fields = append([]string{pending}, fields...)
+			pending = ""
+		}
+
+		var s StorageStats
+		if toybox {
+			if len(fields) < 6 {
+				continue
+			}
+			s.Filesystem, s.MountPoint = fields[0], strings.Join(fields[5:], " ")
+			var total, used, free uint64
+			if total, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+			if used, err = strconv.ParseUint(fields[2], 10, 64); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+			if free, err = strconv.ParseUint(fields[3], 10, 64); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+			s.Total, s.Used, s.Free = total*1024, used*1024, free*1024
+		} else {
+			if len(fields) < 4 {
+				continue
+			}
+			s.Filesystem, s.MountPoint = fields[0], fields[0]
+			if s.Total, err = parseHumanSize(fields[1]); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+			if s.Used, err = parseHumanSize(fields[2]); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+			if s.Free, err = parseHumanSize(fields[3]); err != nil {
+				return nil, fmt.Errorf("adb df: %q: %w", line, err)
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// parseHumanSize parses sizes such as "512K", "1.9G" or "1024" (bytes).
+func parseHumanSize(s string) (uint64, error) {
+	multiplier := 1.0
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K', 'k':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier != 1 {
+			s = s[:n-1]
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(v * multiplier), nil
+}
@@ -0,0 +1,39 @@
+package gadb
+
+import (
+	"testing"
+)
+
+func Test_parseDf(t *testing.T) {
+	toybox := `Filesystem                                             1K-blocks     Used Available Use% Mounted on
+/dev/block/dm-5                                        115249236 23563612  91554552  21% /data
+/dev/block/by-name/a-very-long-partition-name-that-wraps
+                                                          10240     2048      8192  20% /mnt/vendor/persist
+tmpfs                                                    1914024     1140   1912884   1% /dev
+`
+	stats, err := parseDf(toybox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats[0].MountPoint != "/data" || stats[0].Total != 115249236*1024 || stats[0].Free != 91554552*1024 {
+		t.Errorf("unexpected /data stats: %+v", stats[0])
+	}
+	if stats[1].Filesystem != "/dev/block/by-name/a-very-long-partition-name-that-wraps" || stats[1].MountPoint != "/mnt/vendor/persist" {
+		t.Errorf("unexpected wrapped stats: %+v", stats[1])
+	}
+
+	toolbox := `Filesystem               Size     Used     Free   Blksize
+/dev                   921.4M    32.0K   921.4M   4096
+/data                   12.5G     3.1G     9.4G   4096
+`
+	stats, err = parseDf(toolbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[1].MountPoint != "/data" || stats[0].Used != 32*1024 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
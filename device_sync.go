@@ -0,0 +1,171 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// syncRemoveBatchSize bounds the number of paths passed to a single rm invocation.
+const syncRemoveBatchSize = 64
+
+// Sync pushes the contents of localDir to remoteDir, the way `adb sync` does:
+// files whose size and modification time already match the remote copy are skipped.
+// When deleteRemote is true, remote files and directories that no longer exist
+// locally are removed as well.
+func (d Device) Sync(localDir, remoteDir string, deleteRemote ...bool) (err error) {
+	remoteDir = path.Clean(remoteDir)
+
+	localFiles := map[string]fs.FileInfo{}
+	localDirs := map[string]bool{}
+	err = filepath.WalkDir(localDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			localDirs[rel] = true
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			debugLog(fmt.Sprintf("sync: skipping non-regular file %s", p))
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		localFiles[rel] = info
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("adb sync: %w", err)
+	}
+
+	var remoteEntries map[string]DeviceFileInfoV2
+	if remoteEntries, err = d.remoteTree(remoteDir); err != nil {
+		return fmt.Errorf("adb sync: %w", err)
+	}
+
+	plan := planSync(localFiles, localDirs, remoteEntries, len(deleteRemote) != 0 && deleteRemote[0])
+	if err = d.syncRemove(remoteDir, plan.remove); err != nil {
+		return fmt.Errorf("adb sync: %w", err)
+	}
+	for _, rel := range plan.push {
+		if err = d.syncPushFile(filepath.Join(localDir, filepath.FromSlash(rel)), path.Join(remoteDir, rel), localFiles[rel]); err != nil {
+			return fmt.Errorf("adb sync: %w", err)
+		}
+	}
+
+	return
+}
+
+// syncPlan is what Sync does to make the remote tree match the local one, as sorted paths
+// relative to both roots.
+type syncPlan struct {
+	push   []string
+	remove []string
+}
+
+// planSync compares the local files and directories with the remote tree. A local file is
+// pushed unless a remote file with the same size and modification time, to the second,
+// exists. With deleteRemote, remote entries with no local counterpart are removed, except
+// inside directories that are removed themselves.
+func planSync(localFiles map[string]fs.FileInfo, localDirs map[string]bool, remoteEntries map[string]DeviceFileInfoV2, deleteRemote bool) (plan syncPlan) {
+	for rel, info := range localFiles {
+		if remote, ok := remoteEntries[rel]; ok && !remote.IsDir() &&
+			remote.Size == uint64(info.Size()) && remote.LastModified.Unix() == info.ModTime().Unix() {
+			continue
+		}
+		plan.push = append(plan.push, rel)
+	}
+	sort.Strings(plan.push)
+
+	if !deleteRemote {
+		return plan
+	}
+	for rel, remote := range remoteEntries {
+		if parentRemoved(rel, remoteEntries, localDirs) {
+			continue
+		}
+		if remote.IsDir() && !localDirs[rel] || !remote.IsDir() && localFiles[rel] == nil {
+			plan.remove = append(plan.remove, rel)
+		}
+	}
+	sort.Strings(plan.remove)
+	return plan
+}
+
+// syncRemove removes the entries rels of remoteDir.
+func (d Device) syncRemove(remoteDir string, rels []string) (err error) {
+	for len(rels) > 0 {
+		n := min(len(rels), syncRemoveBatchSize)
+		quoted := make([]string, n)
+		for i := range quoted {
+			quoted[i] = shellQuote(path.Join(remoteDir, rels[i]))
+		}
+		rels = rels[n:]
+
+		var output string
+		if output, err = d.RunShellCommand("rm -rf", quoted...); err != nil {
+			return err
+		}
+		if output = strings.TrimSpace(output); output != "" {
+			return fmt.Errorf("remove: %s", output)
+		}
+	}
+	return
+}
+
+func (d Device) syncPushFile(localPath, remotePath string, info fs.FileInfo) (err error) {
+	debugLog(fmt.Sprintf("sync: %s -> %s", localPath, remotePath))
+
+	var f *os.File
+	if f, err = os.Open(localPath); err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	return d.Push(f, remotePath, info.ModTime(), info.Mode().Perm())
+}
+
+// remoteTree lists remoteDir recursively, keyed by path relative to remoteDir.
+// A missing remoteDir yields an empty tree.
+func (d Device) remoteTree(remoteDir string) (entries map[string]DeviceFileInfoV2, err error) {
+	entries = map[string]DeviceFileInfoV2{}
+	err = d.Walk(remoteDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry == nil && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if p == remoteDir {
+			return nil
+		}
+		info, _ := entry.Info()
+		entries[strings.TrimPrefix(strings.TrimPrefix(p, remoteDir), "/")] = info.Sys().(DeviceFileInfoV2)
+		return nil
+	})
+	return
+}
+
+// parentRemoved reports whether some ancestor directory of rel is itself going to be removed,
+// in which case rel doesn't need to be removed separately.
+func parentRemoved(rel string, remoteEntries map[string]DeviceFileInfoV2, localDirs map[string]bool) bool {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if remote, ok := remoteEntries[dir]; ok && remote.IsDir() && !localDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package gadb
+
+import (
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// syncFileInfo is a local file of the given size and modification time.
+type syncFileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (fi syncFileInfo) Name() string       { return "" }
+func (fi syncFileInfo) Size() int64        { return fi.size }
+func (fi syncFileInfo) Mode() fs.FileMode  { return 0o644 }
+func (fi syncFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi syncFileInfo) IsDir() bool        { return false }
+func (fi syncFileInfo) Sys() any           { return nil }
+
+func Test_planSync(t *testing.T) {
+	mtime := time.Unix(1700000000, 0)
+	file := func(size uint64, mtime time.Time) DeviceFileInfoV2 {
+		return DeviceFileInfoV2{Mode: os.FileMode(unixModeRegular | 0o644), Size: size, LastModified: mtime}
+	}
+	dir := DeviceFileInfoV2{Mode: os.FileMode(unixModeDir | 0o755)}
+
+	local := map[string]fs.FileInfo{
+		"same":     syncFileInfo{3, mtime.Add(400 * time.Millisecond)},
+		"resized":  syncFileInfo{4, mtime},
+		"touched":  syncFileInfo{3, mtime.Add(time.Second)},
+		"new":      syncFileInfo{3, mtime},
+		"was-dir":  syncFileInfo{3, mtime},
+		"keep/a":   syncFileInfo{3, mtime},
+		"keep/new": syncFileInfo{3, mtime},
+	}
+	localDirs := map[string]bool{".": true, "keep": true}
+	remote := map[string]DeviceFileInfoV2{
+		"same":       file(3, mtime),
+		"resized":    file(3, mtime),
+		"touched":    file(3, mtime),
+		"was-dir":    dir,
+		"was-dir/x":  file(1, mtime),
+		"keep":       dir,
+		"keep/a":     file(3, mtime),
+		"keep/stale": file(1, mtime),
+		"gone":       dir,
+		"gone/sub":   dir,
+		"gone/sub/y": file(1, mtime),
+		"stale":      file(1, mtime),
+	}
+
+	for _, tt := range []struct {
+		name         string
+		deleteRemote bool
+		want         syncPlan
+	}{
+		{"push only", false, syncPlan{push: []string{"keep/new", "new", "resized", "touched", "was-dir"}}},
+		{"delete remote", true, syncPlan{
+			push:   []string{"keep/new", "new", "resized", "touched", "was-dir"},
+			remove: []string{"gone", "keep/stale", "stale", "was-dir"},
+		}},
+	} {
+		if got := planSync(local, localDirs, remote, tt.deleteRemote); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// An empty remote tree gets every file.
+	if got := planSync(local, localDirs, nil, true); len(got.push) != len(local) || got.remove != nil {
+		t.Fatalf("unexpected plan %+v", got)
+	}
+}
@@ -64,7 +64,7 @@ func TestDevice_Product(t *testing.T) {
 
 	for i := range devices {
 		dev := devices[i]
-		product := dev.Product()
+		product, _ := dev.Product()
 		t.Log(dev.Serial(), product)
 	}
 }
@@ -82,7 +82,8 @@ func TestDevice_Model(t *testing.T) {
 
 	for i := range devices {
 		dev := devices[i]
-		t.Log(dev.Serial(), dev.Model())
+		model, _ := dev.Model()
+		t.Log(dev.Serial(), model)
 	}
 }
 
@@ -99,7 +100,9 @@ func TestDevice_Usb(t *testing.T) {
 
 	for i := range devices {
 		dev := devices[i]
-		t.Log(dev.Serial(), dev.Usb(), dev.IsUsb())
+		usb, _ := dev.Usb()
+		isUsb, _ := dev.IsUsb()
+		t.Log(dev.Serial(), usb, isUsb)
 	}
 
 }
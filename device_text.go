@@ -0,0 +1,161 @@
+package gadb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding is the character set of a text file read by PullText.
+type TextEncoding int
+
+const (
+	// EncodingAuto detects the encoding from a byte order mark, falling back to UTF-16 when
+	// the content looks like it, UTF-8 when it is valid, and Latin-1 otherwise.
+	EncodingAuto TextEncoding = iota
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+	EncodingLatin1
+)
+
+// NewlineMode selects how PullText rewrites line endings.
+type NewlineMode int
+
+const (
+	// NewlineKeep leaves line endings untouched.
+	NewlineKeep NewlineMode = iota
+	// NewlineLF converts CRLF and lone CR to LF.
+	NewlineLF
+	// NewlineCRLF converts every line ending to CRLF.
+	NewlineCRLF
+)
+
+// TextOptions controls how PullText decodes a file. The zero value detects the encoding
+// and keeps line endings.
+type TextOptions struct {
+	Encoding TextEncoding
+	Newlines NewlineMode
+}
+
+// PullText reads remotePath as text and returns it decoded to UTF-8 with its line endings
+// rewritten according to opts. A byte order mark is removed.
+func (d Device) PullText(remotePath string, opts ...TextOptions) (string, error) {
+	raw, err := d.PullBytes(remotePath)
+	if err != nil {
+		return "", err
+	}
+	if len(opts) == 0 {
+		opts = []TextOptions{{}}
+	}
+	return DecodeText(raw, opts[0])
+}
+
+// DecodeText decodes raw to UTF-8 and normalizes its line endings, as PullText does.
+func DecodeText(raw []byte, opts TextOptions) (string, error) {
+	enc := opts.Encoding
+	if enc == EncodingAuto {
+		enc, raw = detectEncoding(raw)
+	} else {
+		raw = trimBOM(raw, enc)
+	}
+
+	var text string
+	switch enc {
+	case EncodingUTF8:
+		text = string(raw)
+	case EncodingUTF16LE:
+		text = decodeUTF16(raw, binary.LittleEndian)
+	case EncodingUTF16BE:
+		text = decodeUTF16(raw, binary.BigEndian)
+	case EncodingLatin1:
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	default:
+		return "", errors.New("decode text: unknown encoding")
+	}
+
+	switch opts.Newlines {
+	case NewlineLF:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+	case NewlineCRLF:
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text, nil
+}
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// detectEncoding guesses the encoding of raw and strips its byte order mark.
+func detectEncoding(raw []byte) (TextEncoding, []byte) {
+	switch {
+	case bytes.HasPrefix(raw, bomUTF8):
+		return EncodingUTF8, raw[len(bomUTF8):]
+	case bytes.HasPrefix(raw, bomUTF16LE):
+		return EncodingUTF16LE, raw[len(bomUTF16LE):]
+	case bytes.HasPrefix(raw, bomUTF16BE):
+		return EncodingUTF16BE, raw[len(bomUTF16BE):]
+	}
+
+	// ASCII-range text in UTF-16 has a NUL in every other byte.
+	if len(raw) >= 2 && len(raw)%2 == 0 {
+		var even, odd int
+		for i := 0; i < len(raw); i += 2 {
+			if raw[i] == 0 {
+				even++
+			}
+			if raw[i+1] == 0 {
+				odd++
+			}
+		}
+		half := len(raw) / 2
+		switch {
+		case odd > half*3/4 && even == 0:
+			return EncodingUTF16LE, raw
+		case even > half*3/4 && odd == 0:
+			return EncodingUTF16BE, raw
+		}
+	}
+
+	if utf8.Valid(raw) {
+		return EncodingUTF8, raw
+	}
+	return EncodingLatin1, raw
+}
+
+func trimBOM(raw []byte, enc TextEncoding) []byte {
+	switch enc {
+	case EncodingUTF8:
+		return bytes.TrimPrefix(raw, bomUTF8)
+	case EncodingUTF16LE:
+		return bytes.TrimPrefix(raw, bomUTF16LE)
+	case EncodingUTF16BE:
+		return bytes.TrimPrefix(raw, bomUTF16BE)
+	}
+	return raw
+}
+
+func decodeUTF16(raw []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = order.Uint16(raw[2*i:])
+	}
+	text := string(utf16.Decode(units))
+	if len(raw)%2 != 0 {
+		text += string(utf8.RuneError)
+	}
+	return text
+}
@@ -0,0 +1,34 @@
+package gadb
+
+import (
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		opts TextOptions
+		want string
+	}{
+		{"utf8 bom crlf", []byte("\xef\xbb\xbfa\r\nb\r\n"), TextOptions{Newlines: NewlineLF}, "a\nb\n"},
+		{"utf16le bom", []byte{0xff, 0xfe, 'h', 0, 'i', 0, '\r', 0, '\n', 0}, TextOptions{Newlines: NewlineLF}, "hi\n"},
+		{"utf16le no bom", []byte{'o', 0, 'k', 0}, TextOptions{}, "ok"},
+		{"utf16be no bom", []byte{0, 'o', 0, 'k'}, TextOptions{}, "ok"},
+		{"latin1 fallback", []byte("caf\xe9"), TextOptions{}, "café"},
+		{"to crlf", []byte("a\nb\r\nc\r"), TextOptions{Newlines: NewlineCRLF}, "a\r\nb\r\nc\r\n"},
+		{"forced utf16be", []byte{0xfe, 0xff, 0x00, 0xe9}, TextOptions{Encoding: EncodingUTF16BE}, "é"},
+		{"keep newlines", []byte("a\r\n"), TextOptions{Encoding: EncodingUTF8}, "a\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeText(tt.raw, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPackageNotInstalled is matched by a *PackageError for a package that isn't installed.
+var ErrPackageNotInstalled = errors.New("package not installed")
+
+// Is reports whether the failure means the package isn't installed, so that
+// errors.Is(err, ErrPackageNotInstalled) works on package manager failures.
+func (e *PackageError) Is(target error) bool {
+	if target != ErrPackageNotInstalled {
+		return false
+	}
+	reason := e.Code + " " + e.Message
+	return strings.Contains(reason, "not installed for") || strings.Contains(reason, "Unknown package") ||
+		(e.Op == "uninstall" && strings.Contains(reason, "DELETE_FAILED_INTERNAL_ERROR"))
+}
+
+type uninstallOptions struct {
+	keepData bool
+	user     string
+}
+
+// UninstallOption configures Uninstall.
+type UninstallOption func(*uninstallOptions)
+
+// UninstallKeepData keeps the app's data and cache directories (-k).
+func UninstallKeepData() UninstallOption {
+	return func(o *uninstallOptions) { o.keepData = true }
+}
+
+// UninstallUser removes the app only for the given user (--user).
+func UninstallUser(user int) UninstallOption {
+	return func(o *uninstallOptions) { o.user = fmt.Sprint(user) }
+}
+
+// Uninstall removes pkg from the device. A failure is returned as a *PackageError; use
+// errors.Is(err, ErrPackageNotInstalled) to tell a missing package from other failures.
+func (d Device) Uninstall(pkg string, opts ...UninstallOption) error {
+	if pkg == "" || strings.ContainsAny(pkg, "/ ") {
+		return fmt.Errorf("adb uninstall: invalid package name %q", pkg)
+	}
+
+	var o uninstallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cmd := "pm uninstall"
+	if o.keepData {
+		cmd += " -k"
+	}
+	if o.user != "" {
+		cmd += " --user " + o.user
+	}
+
+	output, err := d.RunShellCommand(cmd, shellQuote(pkg))
+	d.InvalidateCache(CachePackages)
+	if err != nil {
+		return err
+	}
+	return parsePMResult("uninstall", output)
+}
@@ -0,0 +1,194 @@
+package gadb
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Unix file type bits as reported by the sync protocol.
+const (
+	unixModeType    = 0170000
+	unixModeSocket  = 0140000
+	unixModeSymlink = 0120000
+	unixModeRegular = 0100000
+	unixModeBlock   = 0060000
+	unixModeDir     = 0040000
+	unixModeChar    = 0020000
+	unixModeFifo    = 0010000
+)
+
+// FileMode converts the raw Unix mode reported by the device into an fs.FileMode.
+func (info DeviceFileInfo) FileMode() fs.FileMode {
+	raw := uint32(info.Mode)
+	mode := fs.FileMode(raw & 0777)
+	switch raw & unixModeType {
+	case unixModeDir:
+		mode |= fs.ModeDir
+	case unixModeSymlink:
+		mode |= fs.ModeSymlink
+	case unixModeSocket:
+		mode |= fs.ModeSocket
+	case unixModeBlock:
+		mode |= fs.ModeDevice
+	case unixModeChar:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case unixModeFifo:
+		mode |= fs.ModeNamedPipe
+	}
+	if raw&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if raw&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if raw&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// deviceFileInfo adapts DeviceFileInfoV2 to fs.FileInfo and fs.DirEntry.
+type deviceFileInfo struct {
+	info DeviceFileInfoV2
+}
+
+func (fi deviceFileInfo) Name() string               { return fi.info.Name }
+func (fi deviceFileInfo) Size() int64                { return int64(fi.info.Size) }
+func (fi deviceFileInfo) Mode() fs.FileMode          { return fi.info.FileMode() }
+func (fi deviceFileInfo) ModTime() time.Time         { return fi.info.LastModified }
+func (fi deviceFileInfo) IsDir() bool                { return fi.Mode().IsDir() }
+func (fi deviceFileInfo) Sys() any                   { return fi.info }
+func (fi deviceFileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi deviceFileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// Walk walks the device file tree rooted at root, calling fn for each file or directory,
+// with the same semantics as filepath.WalkDir: entries are visited in lexical order,
+// symbolic links are not followed, and fn may return fs.SkipDir or fs.SkipAll.
+// The fs.FileInfo returned by the entries' Info method carries the DeviceFileInfoV2 as Sys().
+func (d Device) Walk(root string, fn fs.WalkDirFunc) error {
+	info, err := d.StatV2(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		info.Name = path.Base(root)
+		err = d.walkDir(root, deviceFileInfo{info}, fn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (d Device) walkDir(name string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, entry, nil); err != nil || !entry.IsDir() {
+		if err == fs.SkipDir && entry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	infos, err := d.ListV2(name)
+	if err != nil {
+		if err = fn(name, entry, err); err != nil {
+			if err == fs.SkipDir && entry.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	for _, info := range infos {
+		if info.Name == "." || info.Name == ".." {
+			continue
+		}
+		if err = d.walkDir(path.Join(name, info.Name), deviceFileInfo{info}, fn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Glob returns the device paths matching pattern, using the syntax of path.Match for each
+// path element, e.g. "/sdcard/DCIM/Camera/*.jpg". Matching happens client-side over sync
+// LIST, so no shell quoting is involved. As with filepath.Glob, "*" also matches names
+// starting with a dot, the result is sorted, and paths that don't exist or can't be read
+// are skipped; other errors, such as a broken connection, and path.ErrBadPattern are
+// returned.
+func (d Device) Glob(pattern string) (matches []string, err error) {
+	if _, err = path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	pattern = path.Clean(pattern)
+
+	root := "."
+	if strings.HasPrefix(pattern, "/") {
+		root = "/"
+	}
+	parts := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	if matches, err = d.glob(root, parts); err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (d Device) glob(dir string, parts []string) (matches []string, err error) {
+	part, rest := parts[0], parts[1:]
+
+	var candidates []string
+	if !hasGlobMeta(part) {
+		candidate := path.Join(dir, part)
+		if len(rest) == 0 {
+			if _, err = d.Stat(candidate); err != nil {
+				return nil, skipGlobError(err)
+			}
+		}
+		candidates = []string{candidate}
+	} else {
+		entries, err := d.List(dir)
+		if err != nil {
+			return nil, skipGlobError(err)
+		}
+		for _, entry := range entries {
+			if entry.Name == "." || entry.Name == ".." {
+				continue
+			}
+			if ok, _ := path.Match(part, entry.Name); ok && (len(rest) == 0 || entry.FileMode().IsDir() || entry.FileMode().Type() == fs.ModeSymlink) {
+				candidates = append(candidates, path.Join(dir, entry.Name))
+			}
+		}
+	}
+
+	if len(rest) == 0 {
+		return candidates, nil
+	}
+	for _, candidate := range candidates {
+		sub, err := d.glob(candidate, rest)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, sub...)
+	}
+	return matches, nil
+}
+
+// skipGlobError returns nil for the errors of paths Glob doesn't match, which are those that
+// don't exist or can't be read, and err otherwise.
+func skipGlobError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return nil
+	}
+	return err
+}
+
+func hasGlobMeta(part string) bool {
+	return strings.ContainsAny(part, `*?[\`)
+}
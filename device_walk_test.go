@@ -0,0 +1,42 @@
+package gadb
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func TestDeviceFileInfo_FileMode(t *testing.T) {
+	cases := []struct {
+		raw  uint32
+		want fs.FileMode
+	}{
+		{0100644, 0644},
+		{0040755, fs.ModeDir | 0755},
+		{0120777, fs.ModeSymlink | 0777},
+		{0140755, fs.ModeSocket | 0755},
+		{0020660, fs.ModeDevice | fs.ModeCharDevice | 0660},
+		{0104755, fs.ModeSetuid | 0755},
+		{0041777, fs.ModeDir | fs.ModeSticky | 0777},
+	}
+	for _, c := range cases {
+		info := DeviceFileInfo{Mode: fs.FileMode(c.raw)}
+		if got := info.FileMode(); got != c.want {
+			t.Errorf("%o: got %v, want %v", c.raw, got, c.want)
+		}
+	}
+}
+
+func Test_skipGlobError(t *testing.T) {
+	for _, err := range []error{
+		&fs.PathError{Op: "stat", Path: "/data/x", Err: fs.ErrNotExist},
+		&fs.PathError{Op: "list", Path: "/data", Err: fs.ErrPermission},
+	} {
+		if skipGlobError(err) != nil {
+			t.Errorf("%v: expected it to be skipped", err)
+		}
+	}
+	if err := (&TransportError{Err: io.ErrUnexpectedEOF}); skipGlobError(err) != err {
+		t.Error("expected a transport error to be returned")
+	}
+}
@@ -0,0 +1,239 @@
+package gadb
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Display describes a logical display of the device. Foldables and devices with external or
+// virtual displays report several.
+type Display struct {
+	ID   int
+	Name string
+	// Width and Height are the current resolution in pixels, in the current rotation.
+	Width, Height int
+	// Density is the current density in dpi.
+	Density int
+	// Rotation is the number of quarter turns counterclockwise from the natural orientation.
+	Rotation int
+	// State is the power state, such as "ON", "OFF" or "DOZE".
+	State string
+
+	// PhysicalWidth, PhysicalHeight and PhysicalDensity are the panel's native values in its
+	// natural orientation; the Override values are set by `wm size` and `wm density` and
+	// are zero when not overridden.
+	PhysicalWidth, PhysicalHeight int
+	OverrideWidth, OverrideHeight int
+	PhysicalDensity               int
+	OverrideDensity               int
+}
+
+// Displays returns the device's logical displays, the default display first.
+func (d Device) Displays() ([]Display, error) {
+	output, err := d.Dumpsys("display")
+	if err != nil {
+		return nil, err
+	}
+	displays := parseDisplays(output)
+	if len(displays) == 0 {
+		displays = []Display{{ID: 0}}
+	}
+
+	for i := range displays {
+		id := displays[i].ID
+		sizes, err := d.wmValues("size", id)
+		if err != nil {
+			return nil, err
+		}
+		densities, err := d.wmValues("density", id)
+		if err != nil {
+			return nil, err
+		}
+		displays[i].applyWM(sizes, densities)
+	}
+	return displays, nil
+}
+
+// wmValues runs `wm size` or `wm density` for the display. Displays other than the default
+// one need -d, which older releases don't understand; those displays stay without values.
+func (d Device) wmValues(cmd string, id int) (map[string]string, error) {
+	var args []string
+	if id != 0 {
+		args = append(args, "-d", strconv.Itoa(id))
+	}
+	output, err := d.RunShellCommand("wm "+cmd, args...)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values, nil
+}
+
+func (display *Display) applyWM(sizes, densities map[string]string) {
+	if w, h, ok := parseDimensions(sizes["Physical size"], "x"); ok {
+		display.PhysicalWidth, display.PhysicalHeight = w, h
+	}
+	if w, h, ok := parseDimensions(sizes["Override size"], "x"); ok {
+		display.OverrideWidth, display.OverrideHeight = w, h
+	}
+	display.PhysicalDensity, _ = strconv.Atoi(densities["Physical density"])
+	display.OverrideDensity, _ = strconv.Atoi(densities["Override density"])
+
+	// Without dumpsys display information, derive the current values from wm.
+	if display.Width == 0 {
+		display.Width, display.Height = display.PhysicalWidth, display.PhysicalHeight
+		if display.OverrideWidth != 0 {
+			display.Width, display.Height = display.OverrideWidth, display.OverrideHeight
+		}
+	}
+	if display.Density == 0 {
+		display.Density = cmp.Or(display.OverrideDensity, display.PhysicalDensity)
+	}
+}
+
+// parseDisplays reads the "Logical Displays" section of dumpsys display. Each display has an
+// mBaseDisplayInfo and, once configured, an mOverrideDisplayInfo with the current state:
+//
+//	mOverrideDisplayInfo=DisplayInfo{"Built-in Screen", displayId 0, ..., real 1080 x 2400, ..., rotation 1, ..., density 420 (409.4 x 411.9) dpi, ..., state ON, ...}
+func parseDisplays(output string) []Display {
+	var displays []Display
+	index := map[int]int{}
+	overridden := map[int]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		info, override := strings.CutPrefix(line, "mOverrideDisplayInfo=DisplayInfo{")
+		if !override {
+			var ok bool
+			if info, ok = strings.CutPrefix(line, "mBaseDisplayInfo=DisplayInfo{"); !ok {
+				continue
+			}
+		}
+
+		display := parseDisplayInfo(info)
+		i, ok := index[display.ID]
+		switch {
+		case !ok:
+			index[display.ID] = len(displays)
+			displays = append(displays, display)
+		case override || !overridden[display.ID]:
+			displays[i] = display
+		}
+		overridden[display.ID] = overridden[display.ID] || override
+	}
+	return displays
+}
+
+func parseDisplayInfo(info string) (display Display) {
+	if name, _, ok := strings.Cut(strings.TrimPrefix(info, `"`), `"`); ok && strings.HasPrefix(info, `"`) {
+		display.Name = name
+	}
+	for _, field := range strings.Split(info, ", ") {
+		key, value, _ := strings.Cut(field, " ")
+		switch key {
+		case "displayId":
+			display.ID, _ = strconv.Atoi(value)
+		case "real":
+			display.Width, display.Height, _ = parseDimensions(value, " x ")
+		case "rotation":
+			display.Rotation, _ = strconv.Atoi(value)
+		case "density":
+			density, _, _ := strings.Cut(value, " ")
+			display.Density, _ = strconv.Atoi(density)
+		case "state":
+			display.State = strings.TrimRight(value, "}")
+		}
+	}
+	return display
+}
+
+func parseDimensions(s, sep string) (width, height int, ok bool) {
+	w, h, found := strings.Cut(strings.TrimSpace(s), sep)
+	if !found {
+		return 0, 0, false
+	}
+	var err error
+	if width, err = strconv.Atoi(w); err != nil {
+		return 0, 0, false
+	}
+	if height, err = strconv.Atoi(h); err != nil {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// screenStateTimeout bounds how long ScreenOn and ScreenOff wait for the display to follow.
+const screenStateTimeout = 3 * time.Second
+
+// IsScreenOn reports whether the device is awake with its screen on, according to the power
+// manager. A dozing device showing an always-on display counts as off.
+func (d Device) IsScreenOn() (bool, error) {
+	output, err := d.Dumpsys("power")
+	if err != nil {
+		return false, err
+	}
+	on, ok := parseScreenOn(output)
+	if !ok {
+		return false, errors.New("adb dumpsys power: wakefulness not found")
+	}
+	return on, nil
+}
+
+// ScreenOn wakes the device up and waits for the screen to turn on. It uses KeyWakeup rather
+// than KeyPower, so a screen that is already on stays on.
+func (d Device) ScreenOn() error {
+	return d.setScreen(true, KeyWakeup)
+}
+
+// ScreenOff puts the device to sleep and waits for the screen to turn off.
+func (d Device) ScreenOff() error {
+	return d.setScreen(false, KeySleep)
+}
+
+func (d Device) setScreen(on bool, key KeyCode) error {
+	if err := d.Input().KeyEvent(key); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(screenStateTimeout)
+	for {
+		state, err := d.IsScreenOn()
+		if err != nil || state == on {
+			return err
+		}
+		if time.Now().After(deadline) {
+			want := "off"
+			if on {
+				want = "on"
+			}
+			return fmt.Errorf("adb: screen did not turn %s", want)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// parseScreenOn reads the wakefulness from dumpsys power output ("mWakefulness=Awake", or
+// "getWakefulnessLocked()=Awake" on newer releases), falling back to the display power state.
+func parseScreenOn(output string) (on, ok bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, key := range []string{"mWakefulness=", "getWakefulnessLocked()="} {
+			if value, found := strings.CutPrefix(line, key); found {
+				return value == "Awake", true
+			}
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "Display Power: state="); found {
+			return value == "ON", true
+		}
+	}
+	return false, false
+}
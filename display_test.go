@@ -0,0 +1,56 @@
+package gadb
+
+import "testing"
+
+const dumpsysDisplayOutput = `DISPLAY MANAGER (dumpsys display)
+Logical Displays: size=2
+  Display 0:
+    mDisplayId=0
+    mBaseDisplayInfo=DisplayInfo{"Built-in Screen", displayId 0, displayGroupId 0, FLAG_SECURE, FLAG_SUPPORTS_PROTECTED_BUFFERS, real 1080 x 2400, largest app 1080 x 2400, smallest app 1080 x 2400, rotation 0, density 420 (409.432 x 411.891) dpi, state ON, type INTERNAL}
+    mOverrideDisplayInfo=DisplayInfo{"Built-in Screen", displayId 0, displayGroupId 0, FLAG_SECURE, real 2400 x 1080, largest app 2400 x 2340, smallest app 1080 x 1017, rotation 1, density 420 (409.432 x 411.891) dpi, state ON, type INTERNAL}
+  Display 2:
+    mDisplayId=2
+    mBaseDisplayInfo=DisplayInfo{"Virtual display", displayId 2, real 720 x 480, rotation 0, density 160 (160.0 x 160.0) dpi, state OFF}
+`
+
+func Test_parseDisplays(t *testing.T) {
+	displays := parseDisplays(dumpsysDisplayOutput)
+	if len(displays) != 2 {
+		t.Fatalf("expected 2 displays, got %+v", displays)
+	}
+	want := Display{ID: 0, Name: "Built-in Screen", Width: 2400, Height: 1080, Density: 420, Rotation: 1, State: "ON"}
+	if displays[0] != want {
+		t.Fatalf("got %+v, want %+v", displays[0], want)
+	}
+	want = Display{ID: 2, Name: "Virtual display", Width: 720, Height: 480, Density: 160, State: "OFF"}
+	if displays[1] != want {
+		t.Fatalf("got %+v, want %+v", displays[1], want)
+	}
+}
+
+func TestDisplay_applyWM(t *testing.T) {
+	var display Display
+	display.applyWM(map[string]string{"Physical size": "1080x2400", "Override size": "720x1600"},
+		map[string]string{"Physical density": "420", "Override density": "320"})
+	want := Display{Width: 720, Height: 1600, Density: 320, PhysicalWidth: 1080, PhysicalHeight: 2400,
+		OverrideWidth: 720, OverrideHeight: 1600, PhysicalDensity: 420, OverrideDensity: 320}
+	if display != want {
+		t.Fatalf("got %+v, want %+v", display, want)
+	}
+}
+
+func Test_parseScreenOn(t *testing.T) {
+	for output, want := range map[string]bool{
+		"POWER MANAGER (dumpsys power)\n  mWakefulness=Awake\n  mWakefulnessChanging=false\n": true,
+		"  getWakefulnessLocked()=Dozing\n  mWakefulnessChanging=false\n":                     false,
+		"Display Power: state=OFF\n": false,
+	} {
+		on, ok := parseScreenOn(output)
+		if !ok || on != want {
+			t.Errorf("%q: got %v (found %v), want %v", output, on, ok, want)
+		}
+	}
+	if _, ok := parseScreenOn("Power Manager State:\n"); ok {
+		t.Fatal("expected no state")
+	}
+}
@@ -0,0 +1,126 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KernelLevel is the syslog severity of a kernel log entry, from KernEmerg, the most severe,
+// to KernDebug.
+type KernelLevel int
+
+const (
+	KernEmerg KernelLevel = iota
+	KernAlert
+	KernCrit
+	KernErr
+	KernWarning
+	KernNotice
+	KernInfo
+	KernDebug
+)
+
+var kernelLevelNames = []string{"emerg", "alert", "crit", "err", "warn", "notice", "info", "debug"}
+
+// String returns the name dmesg uses for the level, such as "warn".
+func (l KernelLevel) String() string {
+	if l < KernEmerg || l > KernDebug {
+		return strconv.Itoa(int(l))
+	}
+	return kernelLevelNames[l]
+}
+
+// KernelLogEntry is one line of the kernel log.
+type KernelLogEntry struct {
+	// Uptime is the time since boot at which the entry was logged.
+	Uptime time.Duration
+	// Facility is the syslog facility: 0 for the kernel itself, 1 for user space writing to
+	// /dev/kmsg, such as init.
+	Facility int
+	Level    KernelLevel
+	Message  string
+}
+
+// Dmesg streams the kernel log as parsed entries: the current contents of the ring buffer
+// and, with follow, new entries as they are logged until ctx is done. Reading the kernel log
+// needs root or, on some builds, the shell's dmesg permission; Dmesg returns the device's
+// error when it is refused.
+func (d Device) Dmesg(ctx context.Context, follow bool) (<-chan KernelLogEntry, error) {
+	cmd := "dmesg -r"
+	if follow {
+		cmd += " -w"
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	conn, err := d.openExec(ctx, cmd+" 2>&1")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// A refused dmesg prints its error instead of the first entry.
+	br := bufio.NewReader(conn)
+	first, err := br.ReadString('\n')
+	entry, parseErr := parseKernelLogLine(strings.TrimRight(first, "\r\n"))
+	if parseErr != nil && first != "" {
+		cancel()
+		_ = conn.Close()
+		return nil, fmt.Errorf("adb dmesg: %s", strings.TrimSpace(first))
+	}
+
+	entries := make(chan KernelLogEntry, 64)
+	go func() {
+		defer close(entries)
+		defer cancel()
+		defer func() { _ = conn.Close() }()
+		line := first
+		for line != "" || err == nil {
+			if parseErr == nil {
+				select {
+				case entries <- entry:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+			line, err = br.ReadString('\n')
+			entry, parseErr = parseKernelLogLine(strings.TrimRight(line, "\r\n"))
+		}
+	}()
+	return entries, nil
+}
+
+var errNotKernelLogLine = errors.New("not a kernel log line")
+
+// parseKernelLogLine parses a line of `dmesg -r`, which prefixes the message with the
+// facility and level, combined as facility*8+level, and the uptime in seconds:
+//
+//	<6>[   12.345678] binder: 1234:1234 transaction failed 29189/-22
+func parseKernelLogLine(line string) (entry KernelLogEntry, err error) {
+	prefix, rest, ok := strings.Cut(strings.TrimPrefix(line, "<"), ">")
+	if !ok || !strings.HasPrefix(line, "<") {
+		return KernelLogEntry{}, errNotKernelLogLine
+	}
+	priority, err := strconv.Atoi(prefix)
+	if err != nil {
+		return KernelLogEntry{}, errNotKernelLogLine
+	}
+	entry.Facility, entry.Level = priority>>3, KernelLevel(priority&7)
+
+	if stamp, message, ok := strings.Cut(strings.TrimPrefix(rest, "["), "]"); ok && strings.HasPrefix(rest, "[") {
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(stamp), 64)
+		if err != nil {
+			return KernelLogEntry{}, errNotKernelLogLine
+		}
+		entry.Uptime = time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)
+		rest = strings.TrimPrefix(message, " ")
+	}
+	entry.Message = rest
+	return entry, nil
+}
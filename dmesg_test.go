@@ -0,0 +1,28 @@
+package gadb
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseKernelLogLine(t *testing.T) {
+	entry, err := parseKernelLogLine("<6>[   12.345678] binder: 1234:1234 transaction failed 29189/-22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := KernelLogEntry{Uptime: 12*time.Second + 345678*time.Microsecond, Facility: 0, Level: KernInfo, Message: "binder: 1234:1234 transaction failed 29189/-22"}
+	if entry != want {
+		t.Fatalf("got %+v, want %+v", entry, want)
+	}
+
+	entry, err = parseKernelLogLine("<12>[    1.000000] init: starting service 'adbd'...")
+	if err != nil || entry.Facility != 1 || entry.Level != KernWarning || entry.Level.String() != "warn" || entry.Message != "init: starting service 'adbd'..." {
+		t.Fatalf("unexpected entry %+v, %v", entry, err)
+	}
+
+	for _, line := range []string{"dmesg: klogctl: Permission denied", "<x>[ 1.0] msg", "<3>[ bad] msg", ""} {
+		if _, err = parseKernelLogLine(line); err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrServiceNotFound is returned by Dumpsys for a service the device doesn't run.
+var ErrServiceNotFound = errors.New("service not found")
+
+// DefaultDumpsysTimeout bounds a Dumpsys call, so that a hung service doesn't block its
+// caller; dumpsys itself gives up on a service after 10 seconds.
+const DefaultDumpsysTimeout = 10 * time.Second
+
+// Dumpsys returns the dump of service, such as "battery" or "activity", given args. It fails
+// with ErrShellTimeout after DefaultDumpsysTimeout, and with ErrServiceNotFound for unknown
+// services; Services lists the known ones.
+func (d Device) Dumpsys(service string, args ...string) (string, error) {
+	return d.DumpsysWithTimeout(DefaultDumpsysTimeout, service, args...)
+}
+
+// DumpsysWithTimeout is Dumpsys with its own timeout, for large dumps such as meminfo of all
+// processes. Devices without shell v2 can't be bounded, so there the call runs to completion.
+func (d Device) DumpsysWithTimeout(timeout time.Duration, service string, args ...string) (string, error) {
+	if service == "" || strings.HasPrefix(service, "-") {
+		return "", fmt.Errorf("adb dumpsys: invalid service %q", service)
+	}
+	quoted := []string{"dumpsys", shellQuote(service)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	cmd := strings.Join(quoted, " ")
+
+	v2, err := d.HasFeature("shell_v2")
+	if err != nil {
+		return "", err
+	}
+	var output, stderr string
+	if v2 {
+		result, err := d.RunShellBounded(cmd, 0, timeout)
+		output, stderr = string(result.Stdout), string(result.Stderr)
+		if err != nil && !notFoundService(stderr) {
+			if stderr = strings.TrimSpace(stderr); stderr != "" {
+				return output, fmt.Errorf("adb dumpsys %s: %s: %w", service, stderr, err)
+			}
+			return output, fmt.Errorf("adb dumpsys %s: %w", service, err)
+		}
+	} else if output, err = d.RunShellCommand(cmd); err != nil {
+		return "", err
+	}
+
+	// Without shell v2, stderr is part of output.
+	if notFoundService(stderr) || notFoundService(output) {
+		return "", fmt.Errorf("adb dumpsys %s: %w", service, ErrServiceNotFound)
+	}
+	return output, nil
+}
+
+// notFoundService reports whether output is dumpsys's complaint about an unknown service.
+func notFoundService(output string) bool {
+	return strings.HasPrefix(strings.TrimSpace(output), "Can't find service: ")
+}
+
+// Services lists the services dumpsys can dump, as listed by `dumpsys -l`.
+func (d Device) Services() ([]string, error) {
+	output, err := d.RunShellCommand("dumpsys -l")
+	if err != nil {
+		return nil, err
+	}
+	services := parseServices(output)
+	if len(services) == 0 {
+		return nil, errors.New("adb dumpsys -l: unexpected output " + strings.TrimSpace(output))
+	}
+	return services, nil
+}
+
+// parseServices parses `dumpsys -l` output:
+//
+//	Currently running services:
+//	  SurfaceFlinger
+//	  activity
+func parseServices(output string) (services []string) {
+	_, list, ok := strings.Cut(output, "Currently running services:")
+	if !ok {
+		return nil
+	}
+	for _, line := range strings.Split(list, "\n") {
+		if service := strings.TrimSpace(line); service != "" {
+			services = append(services, service)
+		}
+	}
+	return services
+}
@@ -0,0 +1,22 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseServices(t *testing.T) {
+	services := parseServices("Currently running services:\n  SurfaceFlinger\n  activity\r\n  battery\n\n")
+	if want := []string{"SurfaceFlinger", "activity", "battery"}; !reflect.DeepEqual(services, want) {
+		t.Fatalf("got %q, want %q", services, want)
+	}
+	if services = parseServices("dumpsys: unknown option -l\n"); services != nil {
+		t.Fatalf("unexpected services %q", services)
+	}
+}
+
+func Test_notFoundService(t *testing.T) {
+	if !notFoundService("Can't find service: nope\n") || notFoundService("DUMP OF SERVICE battery:\n") {
+		t.Fatal("unexpected notFoundService result")
+	}
+}
@@ -0,0 +1,183 @@
+// Package exporter exposes metrics about the devices attached to an adb server
+// in the Prometheus text exposition format.
+//
+// It has no dependencies beyond gadb; mount an *Exporter on any http.ServeMux:
+//
+//	client, _ := gadb.NewClient()
+//	http.Handle("/metrics", exporter.New(client))
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Tryanks/gadb"
+)
+
+// Exporter collects per-device metrics on every scrape.
+type Exporter struct {
+	client gadb.Client
+
+	// Mounts lists the device mount points whose storage usage is reported.
+	Mounts []string
+}
+
+// New returns an Exporter collecting metrics for every device known to client.
+func New(client gadb.Client) *Exporter {
+	return &Exporter{client: client, Mounts: []string{"/data"}}
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.WriteMetrics(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// WriteMetrics collects the current metrics and writes them to w.
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	devices, err := e.client.DeviceList()
+	if err != nil {
+		return fmt.Errorf("exporter: %w", err)
+	}
+
+	set := newMetricSet()
+	var wg sync.WaitGroup
+	for _, dev := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.collect(dev, set)
+		}()
+	}
+	wg.Wait()
+
+	_, err = io.WriteString(w, set.String())
+	return err
+}
+
+func (e *Exporter) collect(dev gadb.Device, set *metricSet) {
+	serial := dev.Serial()
+	labels := []string{"serial", serial}
+
+	state, err := dev.State()
+	up := 0.0
+	if err == nil && state == gadb.StateOnline {
+		up = 1
+	}
+	set.add("gadb_device_up", gaugeType, "Whether the device is online.", up, labels...)
+	set.add("gadb_device_state", gaugeType, "Current device state.", 1, "serial", serial, "state", string(state))
+
+	stats := gadb.StatsFor(serial)
+	set.add("gadb_device_commands_total", counterType, "Commands issued to the device.", float64(stats.Commands), labels...)
+	set.add("gadb_device_command_errors_total", counterType, "Commands issued to the device that failed.", float64(stats.CommandErrors), labels...)
+	set.add("gadb_device_sync_sent_bytes_total", counterType, "Bytes pushed to the device over sync.", float64(stats.SyncBytesSent), labels...)
+	set.add("gadb_device_sync_received_bytes_total", counterType, "Bytes pulled from the device over sync.", float64(stats.SyncBytesReceived), labels...)
+	set.add("gadb_device_sync_seconds_total", counterType, "Time spent in sync transfers.", stats.SyncDuration.Seconds(), labels...)
+
+	if up == 0 {
+		return
+	}
+
+	if battery, err := dev.BatteryInfo(); err == nil {
+		set.add("gadb_device_battery_level_percent", gaugeType, "Battery charge level.", float64(battery.Level), labels...)
+		set.add("gadb_device_battery_temperature_celsius", gaugeType, "Battery temperature.", battery.Temperature, labels...)
+	}
+
+	if storage, err := dev.StorageStats(); err == nil {
+		for _, s := range storage {
+			if !slices.Contains(e.Mounts, s.MountPoint) {
+				continue
+			}
+			mountLabels := []string{"serial", serial, "mount", s.MountPoint}
+			set.add("gadb_device_storage_total_bytes", gaugeType, "Size of the filesystem.", float64(s.Total), mountLabels...)
+			set.add("gadb_device_storage_free_bytes", gaugeType, "Free space available on the filesystem.", float64(s.Free), mountLabels...)
+		}
+	}
+}
+
+type metricType string
+
+const (
+	gaugeType   metricType = "gauge"
+	counterType metricType = "counter"
+)
+
+type metricFamily struct {
+	help    string
+	typ     metricType
+	samples []string
+}
+
+// metricSet accumulates samples grouped by family so they can be rendered in text format.
+type metricSet struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{families: map[string]*metricFamily{}}
+}
+
+func (s *metricSet) add(name string, typ metricType, help string, value float64, labels ...string) {
+	var sample strings.Builder
+	sample.WriteString(name)
+	if len(labels) > 0 {
+		sample.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sample.WriteString(",")
+			}
+			fmt.Fprintf(&sample, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		sample.WriteString("}")
+	}
+	sample.WriteString(" ")
+	sample.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	family, ok := s.families[name]
+	if !ok {
+		family = &metricFamily{help: help, typ: typ}
+		s.families[name] = family
+	}
+	family.samples = append(family.samples, sample.String())
+}
+
+func (s *metricSet) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.families))
+	for name := range s.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		family := s.families[name]
+		sort.Strings(family.samples)
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", name, family.help, name, family.typ)
+		for _, sample := range family.samples {
+			out.WriteString(sample)
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// labelEscaper escapes label values as required by the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,24 @@
+package exporter
+
+import (
+	"testing"
+)
+
+func Test_metricSet_String(t *testing.T) {
+	set := newMetricSet()
+	set.add("gadb_device_up", gaugeType, "Whether the device is online.", 1, "serial", "b")
+	set.add("gadb_device_up", gaugeType, "Whether the device is online.", 0, "serial", `a"1`)
+	set.add("gadb_device_commands_total", counterType, "Commands issued to the device.", 42, "serial", "a")
+
+	expected := `# HELP gadb_device_commands_total Commands issued to the device.
+# TYPE gadb_device_commands_total counter
+gadb_device_commands_total{serial="a"} 42
+# HELP gadb_device_up Whether the device is online.
+# TYPE gadb_device_up gauge
+gadb_device_up{serial="a\"1"} 0
+gadb_device_up{serial="b"} 1
+`
+	if got := set.String(); got != expected {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
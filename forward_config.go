@@ -0,0 +1,178 @@
+package gadb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// forwardEndpointTypes are the socket specs adb accepts on either side of a forward.
+var forwardEndpointTypes = []string{"tcp", "localabstract", "localreserved", "localfilesystem", "dev", "jdwp", "vsock", "acceptfd"}
+
+// ForwardSpec is one forward or reverse, e.g. {"local": "tcp:8080", "remote": "localabstract:agent"}.
+// For a forward Local is on the host; for a reverse Local is on the device.
+type ForwardSpec struct {
+	Local  Port `json:"local" yaml:"local"`
+	Remote Port `json:"remote" yaml:"remote"`
+}
+
+// ForwardConfig declares the forwards and reverses a device should have. It can be decoded
+// from JSON with LoadForwardConfig, or from YAML with any decoder honouring the yaml tags.
+type ForwardConfig struct {
+	Forward []ForwardSpec `json:"forward,omitempty" yaml:"forward,omitempty"`
+	Reverse []ForwardSpec `json:"reverse,omitempty" yaml:"reverse,omitempty"`
+}
+
+// LoadForwardConfig decodes and validates a JSON ForwardConfig.
+func LoadForwardConfig(r io.Reader) (cfg ForwardConfig, err error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&cfg); err != nil {
+		return ForwardConfig{}, fmt.Errorf("forward config: %w", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return ForwardConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every endpoint has a known type and that no local endpoint is used twice.
+func (cfg ForwardConfig) Validate() error {
+	for _, set := range []struct {
+		kind  string
+		specs []ForwardSpec
+	}{{"forward", cfg.Forward}, {"reverse", cfg.Reverse}} {
+		seen := map[Port]bool{}
+		for _, spec := range set.specs {
+			for _, endpoint := range []Port{spec.Local, spec.Remote} {
+				kind, value, ok := strings.Cut(endpoint, ":")
+				if !ok || value == "" || !slices.Contains(forwardEndpointTypes, kind) {
+					return fmt.Errorf("forward config: %s %s;%s: invalid endpoint %q", set.kind, spec.Local, spec.Remote, endpoint)
+				}
+			}
+			if seen[spec.Local] {
+				return fmt.Errorf("forward config: %s %s declared twice", set.kind, spec.Local)
+			}
+			seen[spec.Local] = true
+		}
+	}
+	return nil
+}
+
+// ForwardDrift lists how a device's actual forwards and reverses differ from a ForwardConfig.
+type ForwardDrift struct {
+	// Missing specs are declared but not set up; Changed specs are set up with another remote.
+	Missing []ForwardSpec
+	Changed []ForwardSpec
+	// Extra specs are set up on the device but not declared.
+	Extra []ForwardSpec
+
+	MissingReverse []ForwardSpec
+	ChangedReverse []ForwardSpec
+	ExtraReverse   []ForwardSpec
+}
+
+// Empty reports whether the device matches the configuration.
+func (drift ForwardDrift) Empty() bool {
+	return len(drift.Missing)+len(drift.Changed)+len(drift.Extra)+
+		len(drift.MissingReverse)+len(drift.ChangedReverse)+len(drift.ExtraReverse) == 0
+}
+
+// ForwardDrift compares the device's forwards and reverses with cfg without changing anything.
+func (d Device) ForwardDrift(cfg ForwardConfig) (drift ForwardDrift, err error) {
+	var forwards, reverses []DeviceForward
+	if forwards, err = d.ForwardList(); err != nil {
+		return ForwardDrift{}, err
+	}
+	if reverses, err = d.ReverseList(); err != nil {
+		return ForwardDrift{}, err
+	}
+	drift.Missing, drift.Changed, drift.Extra = diffForwards(cfg.Forward, forwards)
+	drift.MissingReverse, drift.ChangedReverse, drift.ExtraReverse = diffForwards(cfg.Reverse, reverses)
+	return drift, nil
+}
+
+func diffForwards(want []ForwardSpec, have []DeviceForward) (missing, changed, extra []ForwardSpec) {
+	actual := make(map[Port]Port, len(have))
+	for _, fw := range have {
+		actual[fw.Local] = fw.Remote
+	}
+	declared := make(map[Port]bool, len(want))
+	for _, spec := range want {
+		declared[spec.Local] = true
+		remote, ok := actual[spec.Local]
+		switch {
+		case !ok:
+			missing = append(missing, spec)
+		case remote != spec.Remote:
+			changed = append(changed, spec)
+		}
+	}
+	for _, fw := range have {
+		if !declared[fw.Local] {
+			extra = append(extra, ForwardSpec{Local: fw.Local, Remote: fw.Remote})
+		}
+	}
+	return
+}
+
+// ApplyForwardConfig brings the device's forwards and reverses in line with cfg, creating
+// missing ones and rebinding changed ones. With prune, undeclared forwards and reverses of
+// this device are removed too. It returns the drift found before applying.
+func (d Device) ApplyForwardConfig(cfg ForwardConfig, prune bool) (drift ForwardDrift, err error) {
+	if err = cfg.Validate(); err != nil {
+		return ForwardDrift{}, err
+	}
+	if drift, err = d.ForwardDrift(cfg); err != nil {
+		return ForwardDrift{}, err
+	}
+
+	for _, spec := range append(drift.Missing, drift.Changed...) {
+		if err = d.Forward(spec.Local, spec.Remote); err != nil {
+			return drift, fmt.Errorf("adb forward %s;%s: %w", spec.Local, spec.Remote, err)
+		}
+	}
+	for _, spec := range append(drift.MissingReverse, drift.ChangedReverse...) {
+		if err = d.Reverse(spec.Local, spec.Remote); err != nil {
+			return drift, fmt.Errorf("adb reverse %s;%s: %w", spec.Local, spec.Remote, err)
+		}
+	}
+	if !prune {
+		return drift, nil
+	}
+	for _, spec := range drift.Extra {
+		if err = d.ForwardKill(spec.Local); err != nil {
+			return drift, fmt.Errorf("adb forward --remove %s: %w", spec.Local, err)
+		}
+	}
+	for _, spec := range drift.ExtraReverse {
+		if err = d.ReverseKill(spec.Local); err != nil {
+			return drift, fmt.Errorf("adb reverse --remove %s: %w", spec.Local, err)
+		}
+	}
+	return drift, nil
+}
+
+// ApplyForwardConfig applies cfg to every device of the group and returns the drift found on
+// each, by serial. Host ports can only be forwarded to one device, so a group of more than one
+// device accepts reverses only; use ForwardAll to fan forwards out over distinct ports.
+func (g DeviceGroup) ApplyForwardConfig(cfg ForwardConfig, prune bool) (map[string]ForwardDrift, error) {
+	if len(g) > 1 && len(cfg.Forward) != 0 {
+		return nil, errors.New("forward config: forwards cannot be applied to several devices; use ForwardAll")
+	}
+
+	drifts := make(map[string]ForwardDrift, len(g))
+	var errs []error
+	for _, d := range g {
+		drift, err := d.ApplyForwardConfig(cfg, prune)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.serial, err))
+			continue
+		}
+		drifts[d.serial] = drift
+	}
+	return drifts, errors.Join(errs...)
+}
@@ -0,0 +1,48 @@
+package gadb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadForwardConfig(t *testing.T) {
+	cfg, err := LoadForwardConfig(strings.NewReader(`{
+		"forward": [{"local": "tcp:8080", "remote": "localabstract:agent"}],
+		"reverse": [{"local": "tcp:9000", "remote": "tcp:9000"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Forward) != 1 || cfg.Reverse[0].Remote != "tcp:9000" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	for _, raw := range []string{
+		`{"forward": [{"local": "udp:53", "remote": "tcp:53"}]}`,
+		`{"forward": [{"local": "tcp:1", "remote": "tcp:2"}, {"local": "tcp:1", "remote": "tcp:3"}]}`,
+		`{"forwards": []}`,
+	} {
+		if _, err = LoadForwardConfig(strings.NewReader(raw)); err == nil {
+			t.Errorf("%s: expected an error", raw)
+		}
+	}
+}
+
+func Test_diffForwards(t *testing.T) {
+	want := []ForwardSpec{
+		{Local: "tcp:8080", Remote: "tcp:80"},
+		{Local: "tcp:8081", Remote: "tcp:81"},
+		{Local: "tcp:8082", Remote: "tcp:82"},
+	}
+	have := []DeviceForward{
+		{Local: "tcp:8080", Remote: "tcp:80"},
+		{Local: "tcp:8081", Remote: "tcp:9999"},
+		{Local: "tcp:7000", Remote: "jdwp:1234"},
+	}
+	missing, changed, extra := diffForwards(want, have)
+	if !reflect.DeepEqual(missing, want[2:]) || !reflect.DeepEqual(changed, want[1:2]) ||
+		!reflect.DeepEqual(extra, []ForwardSpec{{Local: "tcp:7000", Remote: "jdwp:1234"}}) {
+		t.Fatalf("unexpected drift: %v %v %v", missing, changed, extra)
+	}
+}
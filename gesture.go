@@ -0,0 +1,446 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TouchPoint is the position of a finger, in screen pixels, at offset At from the start of
+// a gesture.
+type TouchPoint struct {
+	X, Y int
+	At   time.Duration
+}
+
+// TouchPath is the trajectory of one finger with increasing At. The finger touches down at
+// the first point, moves linearly between points and lifts at the last one.
+type TouchPath []TouchPoint
+
+// Gesture is a set of fingers moving at the same time, one TouchPath each.
+type Gesture []TouchPath
+
+// LinePath moves a finger from x1, y1 to x2, y2 over duration, starting at offset start.
+func LinePath(x1, y1, x2, y2 int, start, duration time.Duration) TouchPath {
+	return TouchPath{{X: x1, Y: y1, At: start}, {X: x2, Y: y2, At: start + duration}}
+}
+
+// Pinch moves two fingers placed horizontally around cx, cy from fromRadius to toRadius
+// pixels away from the centre: a zoom in if toRadius is larger, a zoom out otherwise.
+func Pinch(cx, cy, fromRadius, toRadius int, duration time.Duration) Gesture {
+	return Gesture{
+		LinePath(cx-fromRadius, cy, cx-toRadius, cy, 0, duration),
+		LinePath(cx+fromRadius, cy, cx+toRadius, cy, 0, duration),
+	}
+}
+
+// TwoFingerSwipe swipes two fingers, spacing pixels apart horizontally, from x1, y1 to x2, y2.
+func TwoFingerSwipe(x1, y1, x2, y2, spacing int, duration time.Duration) Gesture {
+	half := spacing / 2
+	return Gesture{
+		LinePath(x1-half, y1, x2-half, y2, 0, duration),
+		LinePath(x1+half, y1, x2+half, y2, 0, duration),
+	}
+}
+
+// position interpolates the finger's position at t; active is false before it touches down
+// and after it lifts.
+func (p TouchPath) position(t time.Duration) (x, y int, active bool) {
+	if len(p) == 0 || t < p[0].At || t > p[len(p)-1].At {
+		return 0, 0, false
+	}
+	for i := 1; i < len(p); i++ {
+		a, b := p[i-1], p[i]
+		if t > b.At {
+			continue
+		}
+		if b.At == a.At {
+			return b.X, b.Y, true
+		}
+		f := float64(t-a.At) / float64(b.At-a.At)
+		return a.X + int(f*float64(b.X-a.X)), a.Y + int(f*float64(b.Y-a.Y)), true
+	}
+	return p[0].X, p[0].Y, true
+}
+
+func (g Gesture) validate() error {
+	if len(g) == 0 {
+		return errors.New("adb gesture: no touch paths")
+	}
+	for i, p := range g {
+		if len(p) == 0 {
+			return fmt.Errorf("adb gesture: path %d is empty", i)
+		}
+		for j := 1; j < len(p); j++ {
+			if p[j].At < p[j-1].At {
+				return fmt.Errorf("adb gesture: path %d goes back in time at point %d", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func (g Gesture) duration() (end time.Duration) {
+	for _, p := range g {
+		end = max(end, p[len(p)-1].At)
+	}
+	return end
+}
+
+// gestureFrame is the interval at which finger positions are sampled.
+const gestureFrame = 10 * time.Millisecond
+
+// Input event types and codes for multi-touch protocol B, from linux/input-event-codes.h.
+const (
+	evSyn           = 0x00
+	evKey           = 0x01
+	evAbs           = 0x03
+	synReport       = 0x00
+	btnTouch        = 0x14a
+	absMTSlot       = 0x2f
+	absMTPositionX  = 0x35
+	absMTPositionY  = 0x36
+	absMTTrackingID = 0x39
+)
+
+// touchscreen is an input device reporting multi-touch protocol B events.
+type touchscreen struct {
+	path       string
+	minX, maxX int
+	minY, maxY int
+	slots      int
+}
+
+// inputEvent is a struct input_event without its timestamp, which the kernel sets when the
+// event is written to an evdev node.
+type inputEvent struct {
+	typ, code uint16
+	value     int32
+}
+
+// touchFrame holds the events to report at offset at from the start of a gesture.
+type touchFrame struct {
+	at     time.Duration
+	events []inputEvent
+}
+
+// Perform injects gesture. Gestures are written as raw events to the touchscreen, which can
+// express several fingers; each sampled frame is sent once its offset has elapsed on the
+// host, so frames drift by the adb round trip and are never sent early. Devices without an
+// accessible multi-touch screen fall back to `input motionevent` (Android 11 and later),
+// which only supports one finger and is much coarser in time.
+//
+// Coordinates are in pixels of the display in its natural orientation.
+func (in Input) Perform(gesture Gesture) error {
+	if err := gesture.validate(); err != nil {
+		return err
+	}
+
+	ts, err := in.touchscreen(len(gesture))
+	if err != nil {
+		script, fallbackErr := in.motioneventScript(gesture)
+		if fallbackErr != nil {
+			return errors.Join(err, fallbackErr)
+		}
+		return in.runScript(script)
+	}
+	width, height, err := in.d.screenSize()
+	if err != nil {
+		return err
+	}
+	return in.d.writeInputEvents(ts, ts.frames(gesture, width, height))
+}
+
+// touchscreen finds the multi-touch screen and checks that it tracks enough fingers.
+func (in Input) touchscreen(fingers int) (touchscreen, error) {
+	events, err := in.d.RunShellCommand("getevent -lp")
+	if err != nil {
+		return touchscreen{}, err
+	}
+	ts, ok := parseTouchscreen(events)
+	if !ok {
+		return touchscreen{}, errors.New("adb gesture: no multi-touch screen found")
+	}
+	if fingers > ts.slots {
+		return touchscreen{}, fmt.Errorf("adb gesture: %d fingers but the touchscreen tracks %d", fingers, ts.slots)
+	}
+	return ts, nil
+}
+
+// runScript runs a gesture script, which is far too long for a shell command line.
+func (in Input) runScript(script string) error {
+	scriptPath := path.Join(DefaultExecDir, fmt.Sprintf("gadb-gesture-%d.sh", time.Now().UnixNano()))
+	if err := in.d.PushString(script, scriptPath); err != nil {
+		return err
+	}
+	defer func() { _ = in.d.Remove(scriptPath) }()
+
+	output, err := in.d.RunShellCommand("sh", shellQuote(scriptPath))
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb gesture: %s", output)
+	}
+	return nil
+}
+
+// writeInputEvents plays frames on ts through a single dd. Its block size makes every write
+// one input_event, as evdev requires, and its count makes it exit once all are written.
+// The record size follows the device's ABI, assuming dd is built for it.
+func (d Device) writeInputEvents(ts touchscreen, frames []touchFrame) error {
+	is64, err := d.Is64Bit()
+	if err != nil {
+		return err
+	}
+	eventSize := 16
+	if is64 {
+		eventSize = 24
+	}
+	count := 0
+	for _, f := range frames {
+		count += len(f.events)
+	}
+
+	cmd := fmt.Sprintf("dd of=%s bs=%d count=%d 2>&1; echo $?", shellQuote(ts.path), eventSize, count)
+	conn, err := d.openExec(context.Background(), cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	writeErr := playFrames(conn, frames, eventSize)
+	output, err := io.ReadAll(conn)
+	if err != nil {
+		return errors.Join(writeErr, err)
+	}
+	if err = eventWriterError(string(output)); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// playFrames writes each frame to w as input_event records of eventSize bytes once its
+// offset has elapsed since the first, however long the writes before it took.
+func playFrames(w io.Writer, frames []touchFrame, eventSize int) error {
+	start := time.Now()
+	var b []byte
+	for _, f := range frames {
+		time.Sleep(time.Until(start.Add(f.at)))
+		b = b[:0]
+		for _, ev := range f.events {
+			b = append(b, make([]byte, eventSize-8)...)
+			b = binary.LittleEndian.AppendUint16(b, ev.typ)
+			b = binary.LittleEndian.AppendUint16(b, ev.code)
+			b = binary.LittleEndian.AppendUint32(b, uint32(ev.value))
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventWriterError returns the error reported by writeInputEvents' output: what dd printed,
+// followed by its exit status.
+func eventWriterError(output string) error {
+	output = strings.TrimSpace(output)
+	var message, status string
+	if i := strings.LastIndexByte(output, '\n'); i >= 0 {
+		message, status = strings.TrimSpace(output[:i]), output[i+1:]
+	} else {
+		status = output
+	}
+	switch {
+	case status == "0":
+		return nil
+	case message == "":
+		return fmt.Errorf("adb gesture: event writer exited with %q", status)
+	default:
+		return fmt.Errorf("adb gesture: %s", message)
+	}
+}
+
+// frames renders gesture as touchscreen events, scaling screen pixels to the touchscreen's
+// axis ranges. Each frame only reports what changed and ends with a SYN_REPORT.
+func (ts touchscreen) frames(gesture Gesture, width, height int) []touchFrame {
+	var frames []touchFrame
+	var events []inputEvent
+	send := func(typ, code, value int) {
+		events = append(events, inputEvent{typ: uint16(typ), code: uint16(code), value: int32(value)})
+	}
+	scale := func(v, size, lo, hi int) int {
+		return lo + v*(hi-lo+1)/max(size, 1)
+	}
+
+	type finger struct {
+		down bool
+		x, y int
+	}
+	fingers := make([]finger, len(gesture))
+	touching := 0
+	end := gesture.duration()
+	for t := time.Duration(0); ; t += gestureFrame {
+		events = nil
+		for i, p := range gesture {
+			x, y, active := p.position(min(t, end))
+			if t > end {
+				active = false
+			}
+			f := &fingers[i]
+			switch {
+			case active:
+				x, y = scale(x, width, ts.minX, ts.maxX), scale(y, height, ts.minY, ts.maxY)
+				if f.down && x == f.x && y == f.y {
+					continue
+				}
+				send(evAbs, absMTSlot, i)
+				if !f.down {
+					send(evAbs, absMTTrackingID, i)
+					if touching++; touching == 1 {
+						send(evKey, btnTouch, 1)
+					}
+				}
+				if !f.down || x != f.x {
+					send(evAbs, absMTPositionX, x)
+				}
+				if !f.down || y != f.y {
+					send(evAbs, absMTPositionY, y)
+				}
+				*f = finger{down: true, x: x, y: y}
+			case f.down:
+				send(evAbs, absMTSlot, i)
+				send(evAbs, absMTTrackingID, -1)
+				if touching--; touching == 0 {
+					send(evKey, btnTouch, 0)
+				}
+				f.down = false
+			}
+		}
+		if len(events) > 0 {
+			send(evSyn, synReport, 0)
+			frames = append(frames, touchFrame{at: t, events: events})
+		}
+		if t > end {
+			return frames
+		}
+	}
+}
+
+// motioneventScript renders a single-finger gesture with `input motionevent`.
+func (in Input) motioneventScript(gesture Gesture) (string, error) {
+	if len(gesture) != 1 {
+		return "", errors.New("adb gesture: input motionevent supports a single finger only")
+	}
+	sdk, err := in.d.SdkVersion()
+	if err != nil {
+		return "", err
+	}
+	if sdk < 30 {
+		return "", fmt.Errorf("adb gesture: input motionevent requires Android 11, device has API level %d", sdk)
+	}
+
+	var b strings.Builder
+	p := gesture[0]
+	action := "DOWN"
+	for t := p[0].At; ; t += gestureFrame {
+		at := min(t, p[len(p)-1].At)
+		x, y, _ := p.position(at)
+		if at == p[len(p)-1].At {
+			fmt.Fprintf(&b, "input motionevent UP %d %d\n", x, y)
+			break
+		}
+		fmt.Fprintf(&b, "input motionevent %s %d %d\n", action, x, y)
+		action = "MOVE"
+	}
+	return b.String(), nil
+}
+
+// parseTouchscreen finds the first device in `getevent -lp` output that reports multi-touch
+// slots and positions. Axes are listed as
+// "ABS_MT_POSITION_X     : value 0, min 0, max 1079, fuzz 0, flat 0, resolution 0".
+func parseTouchscreen(output string) (touchscreen, bool) {
+	var ts touchscreen
+	var hasX, hasY bool
+	done := func() bool { return ts.path != "" && hasX && hasY && ts.slots > 0 }
+
+	sc := bufio.NewScanner(strings.NewReader(output))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if devicePath, ok := strings.CutPrefix(line, "add device "); ok {
+			if done() {
+				return ts, true
+			}
+			_, devicePath, _ = strings.Cut(devicePath, ": ")
+			ts, hasX, hasY = touchscreen{path: strings.TrimSpace(devicePath)}, false, false
+			continue
+		}
+		line = strings.TrimPrefix(line, "ABS (0003): ")
+		axis, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lo, hi := absRange(rest)
+		switch strings.TrimSpace(axis) {
+		case "ABS_MT_SLOT":
+			ts.slots = hi - lo + 1
+		case "ABS_MT_POSITION_X":
+			ts.minX, ts.maxX, hasX = lo, hi, true
+		case "ABS_MT_POSITION_Y":
+			ts.minY, ts.maxY, hasY = lo, hi, true
+		}
+	}
+	return ts, done()
+}
+
+func absRange(s string) (lo, hi int) {
+	for _, field := range strings.Split(s, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), " ")
+		switch key {
+		case "min":
+			lo, _ = strconv.Atoi(value)
+		case "max":
+			hi, _ = strconv.Atoi(value)
+		}
+	}
+	return lo, hi
+}
+
+// screenSize returns the display size reported by `wm size`, preferring an override size.
+func (d Device) screenSize() (width, height int, err error) {
+	output, err := d.RunShellCommand("wm size")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseWMSize(output)
+}
+
+func parseWMSize(output string) (width, height int, err error) {
+	for _, line := range strings.Split(output, "\n") {
+		_, size, ok := strings.Cut(line, " size: ")
+		if !ok {
+			continue
+		}
+		w, h, _ := strings.Cut(strings.TrimSpace(size), "x")
+		wv, wErr := strconv.Atoi(w)
+		hv, hErr := strconv.Atoi(h)
+		if wErr != nil || hErr != nil {
+			continue
+		}
+		width, height = wv, hv
+		if strings.HasPrefix(line, "Override") {
+			break
+		}
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, fmt.Errorf("adb wm size: unexpected output %q", strings.TrimSpace(output))
+	}
+	return width, height, nil
+}
@@ -0,0 +1,154 @@
+package gadb
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const geteventOutput = `add device 1: /dev/input/event1
+  name:     "gpio-keys"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_VOLUMEUP          KEY_POWER
+add device 2: /dev/input/event2
+  name:     "fts_ts"
+  events:
+    KEY (0001): BTN_TOUCH
+    ABS (0003): ABS_MT_SLOT           : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+                ABS_MT_TOUCH_MAJOR    : value 0, min 0, max 255, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_X     : value 0, min 0, max 2159, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_Y     : value 0, min 0, max 4799, fuzz 0, flat 0, resolution 0
+                ABS_MT_TRACKING_ID    : value 0, min 0, max 65535, fuzz 0, flat 0, resolution 0
+  input props:
+    INPUT_PROP_DIRECT
+`
+
+func Test_parseTouchscreen(t *testing.T) {
+	ts, ok := parseTouchscreen(geteventOutput)
+	if !ok {
+		t.Fatal("expected a touchscreen")
+	}
+	if want := (touchscreen{path: "/dev/input/event2", maxX: 2159, maxY: 4799, slots: 10}); ts != want {
+		t.Fatalf("got %+v, want %+v", ts, want)
+	}
+	if _, ok = parseTouchscreen(geteventOutput[:strings.Index(geteventOutput, "add device 2")]); ok {
+		t.Fatal("expected no touchscreen")
+	}
+}
+
+func Test_parseWMSize(t *testing.T) {
+	w, h, err := parseWMSize("Physical size: 1080x2400\nOverride size: 720x1600\n")
+	if err != nil || w != 720 || h != 1600 {
+		t.Fatalf("unexpected size %dx%d, %v", w, h, err)
+	}
+	if _, _, err = parseWMSize("Physical size: unknown\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestTouchPath_position(t *testing.T) {
+	p := LinePath(0, 0, 100, 200, 10*time.Millisecond, 100*time.Millisecond)
+	if _, _, active := p.position(0); active {
+		t.Fatal("finger should not be down before the path starts")
+	}
+	if x, y, active := p.position(60 * time.Millisecond); !active || x != 50 || y != 100 {
+		t.Fatalf("unexpected position %d,%d (%v)", x, y, active)
+	}
+	if _, _, active := p.position(111 * time.Millisecond); active {
+		t.Fatal("finger should be lifted after the path ends")
+	}
+}
+
+func Test_touchscreen_frames(t *testing.T) {
+	ts := touchscreen{path: "/dev/input/event2", maxX: 2159, maxY: 4799, slots: 10}
+	frames := ts.frames(Pinch(540, 1200, 100, 100, 10*time.Millisecond), 1080, 2400)
+	want := []touchFrame{
+		{at: 0, events: []inputEvent{
+			{evAbs, absMTSlot, 0}, {evAbs, absMTTrackingID, 0}, {evKey, btnTouch, 1},
+			{evAbs, absMTPositionX, 880}, {evAbs, absMTPositionY, 2400},
+			{evAbs, absMTSlot, 1}, {evAbs, absMTTrackingID, 1},
+			{evAbs, absMTPositionX, 1280}, {evAbs, absMTPositionY, 2400},
+			{evSyn, synReport, 0},
+		}},
+		// Nothing moves at 10ms, so the fingers lift in the frame after.
+		{at: 20 * time.Millisecond, events: []inputEvent{
+			{evAbs, absMTSlot, 0}, {evAbs, absMTTrackingID, -1},
+			{evAbs, absMTSlot, 1}, {evAbs, absMTTrackingID, -1}, {evKey, btnTouch, 0},
+			{evSyn, synReport, 0},
+		}},
+	}
+	if !reflect.DeepEqual(frames, want) {
+		t.Fatalf("unexpected frames:\n%+v", frames)
+	}
+}
+
+// timedWriter records when each write happens.
+type timedWriter struct {
+	writes [][]byte
+	at     []time.Time
+}
+
+func (w *timedWriter) Write(p []byte) (int, error) {
+	w.writes, w.at = append(w.writes, bytes.Clone(p)), append(w.at, time.Now())
+	return len(p), nil
+}
+
+func Test_playFrames(t *testing.T) {
+	frames := []touchFrame{
+		{at: 0, events: []inputEvent{{evAbs, absMTPositionX, 880}, {evSyn, synReport, 0}}},
+		{at: 30 * time.Millisecond, events: []inputEvent{{evAbs, absMTTrackingID, -1}}},
+	}
+	var w timedWriter
+	start := time.Now()
+	if err := playFrames(&w, frames, 24); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each frame is one write, sent no earlier than its offset.
+	if len(w.writes) != 2 || len(w.writes[0]) != 48 || len(w.writes[1]) != 24 {
+		t.Fatalf("unexpected writes %x", w.writes)
+	}
+	if elapsed := w.at[1].Sub(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("second frame written after %v", elapsed)
+	}
+	want := append(make([]byte, 16), 0x03, 0, 0x35, 0, 0x70, 0x03, 0, 0)
+	if !bytes.Equal(w.writes[0][:24], want) {
+		t.Fatalf("got record %x, want %x", w.writes[0][:24], want)
+	}
+	if !bytes.Equal(w.writes[1][16:], []byte{0x03, 0, 0x39, 0, 0xff, 0xff, 0xff, 0xff}) {
+		t.Fatalf("unexpected record %x", w.writes[1])
+	}
+
+	// 32-bit processes use a 16-byte record, with an 8-byte timestamp.
+	w = timedWriter{}
+	if err := playFrames(&w, frames[:1], 16); err != nil || len(w.writes[0]) != 32 || !bytes.Equal(w.writes[0][8:16], want[16:]) {
+		t.Fatalf("unexpected writes %x, %v", w.writes, err)
+	}
+}
+
+func Test_eventWriterError(t *testing.T) {
+	if err := eventWriterError("12+0 records in\n12+0 records out\n288 bytes transferred in 0.031 secs\n0\n"); err != nil {
+		t.Fatal(err)
+	}
+	for output, want := range map[string]string{
+		"dd: /dev/input/event2: Permission denied\n1\n": "adb gesture: dd: /dev/input/event2: Permission denied",
+		"1\n": `adb gesture: event writer exited with "1"`,
+		"":    `adb gesture: event writer exited with ""`,
+	} {
+		if err := eventWriterError(output); err == nil || err.Error() != want {
+			t.Errorf("%q: got %v, want %s", output, err, want)
+		}
+	}
+}
+
+func TestGesture_validate(t *testing.T) {
+	if err := (Gesture{}).validate(); err == nil {
+		t.Fatal("expected an error for an empty gesture")
+	}
+	back := Gesture{{{X: 1, Y: 1, At: time.Second}, {X: 2, Y: 2}}}
+	if err := back.validate(); err == nil {
+		t.Fatal("expected an error for a path going back in time")
+	}
+}
@@ -0,0 +1,131 @@
+package gadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyCode is an Android key code, as in android.view.KeyEvent.
+type KeyCode int
+
+const (
+	KeyHome       KeyCode = 3
+	KeyBack       KeyCode = 4
+	KeyDpadUp     KeyCode = 19
+	KeyDpadDown   KeyCode = 20
+	KeyDpadLeft   KeyCode = 21
+	KeyDpadRight  KeyCode = 22
+	KeyVolumeUp   KeyCode = 24
+	KeyVolumeDown KeyCode = 25
+	KeyPower      KeyCode = 26
+	KeyTab        KeyCode = 61
+	KeyEnter      KeyCode = 66
+	KeyDel        KeyCode = 67
+	KeyMenu       KeyCode = 82
+	KeyEscape     KeyCode = 111
+	KeyMoveEnd    KeyCode = 123
+	KeyAppSwitch  KeyCode = 187
+	KeySleep      KeyCode = 223
+	KeyWakeup     KeyCode = 224
+)
+
+// longPressDuration comfortably exceeds the default long press timeout of 400-500ms.
+const longPressDuration = time.Second
+
+// Input injects input events with the device's `input` command. Obtain one with Device.Input.
+type Input struct {
+	d Device
+}
+
+// Input returns the input injection facade of the device.
+func (d Device) Input() Input {
+	return Input{d: d}
+}
+
+// Tap taps the screen at x, y (in pixels).
+func (in Input) Tap(x, y int) error {
+	return in.run("tap", strconv.Itoa(x), strconv.Itoa(y))
+}
+
+// Swipe drags from x1, y1 to x2, y2 over duration; zero uses the input command's default.
+func (in Input) Swipe(x1, y1, x2, y2 int, duration time.Duration) error {
+	args := []string{"swipe", strconv.Itoa(x1), strconv.Itoa(y1), strconv.Itoa(x2), strconv.Itoa(y2)}
+	if duration > 0 {
+		args = append(args, strconv.FormatInt(duration.Milliseconds(), 10))
+	}
+	return in.run(args...)
+}
+
+// LongPress holds a touch at x, y long enough to trigger a long click.
+func (in Input) LongPress(x, y int) error {
+	return in.Swipe(x, y, x, y, longPressDuration)
+}
+
+// KeyEvent presses and releases the key code.
+func (in Input) KeyEvent(code KeyCode) error {
+	return in.run("keyevent", strconv.Itoa(int(code)))
+}
+
+// Text types s into the focused view. Spaces and shell specials are escaped; newlines are
+// sent as KeyEnter. The input command can only type ASCII characters.
+func (in Input) Text(s string) error {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			if err := in.KeyEvent(KeyEnter); err != nil {
+				return err
+			}
+		}
+		if line == "" {
+			continue
+		}
+		texts, err := inputText(line)
+		if err != nil {
+			return err
+		}
+		for _, text := range texts {
+			if err = in.run("text", text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inputText escapes s for `input text`, which turns "%s" into a space and would otherwise
+// split the text at spaces. input has no escape for a literal "%s", so s is split into
+// pieces to type one after another wherever it has one, between the "%" and the "s".
+func inputText(s string) ([]string, error) {
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			return nil, fmt.Errorf("adb input text: cannot type %q", r)
+		}
+	}
+	var texts []string
+	for {
+		i := strings.Index(s, "%s")
+		if i < 0 {
+			return append(texts, strings.ReplaceAll(s, " ", "%s")), nil
+		}
+		texts = append(texts, strings.ReplaceAll(s[:i+1], " ", "%s"))
+		s = s[i+1:]
+	}
+}
+
+// run runs `input` with args, each quoted for the device shell. input prints nothing on
+// success, so any output is its error message.
+func (in Input) run(args ...string) error {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	output, err := in.d.RunShellCommand("input", quoted...)
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb input %s: %s", args[0], output)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_inputText(t *testing.T) {
+	got, err := inputText(`it's a "test" & more; $HOME`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`it's%sa%s"test"%s&%smore;%s$HOME`}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	// A literal "%s" would be typed as a space.
+	got, err = inputText("100%sure %s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"100%", "sure%s%", "s"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, err = inputText("héllo"); err == nil {
+		t.Fatal("expected an error for non-ASCII text")
+	}
+}
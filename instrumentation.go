@@ -0,0 +1,183 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// InstrumentationStatus is the status code of an instrumentation status report, as sent by
+// AndroidJUnitRunner and InstrumentationTestRunner.
+type InstrumentationStatus int
+
+const (
+	TestStarted          InstrumentationStatus = 1
+	TestPassed           InstrumentationStatus = 0
+	TestError            InstrumentationStatus = -1
+	TestFailed           InstrumentationStatus = -2
+	TestIgnored          InstrumentationStatus = -3
+	TestAssumptionFailed InstrumentationStatus = -4
+)
+
+func (s InstrumentationStatus) String() string {
+	switch s {
+	case TestStarted:
+		return "started"
+	case TestPassed:
+		return "passed"
+	case TestError:
+		return "error"
+	case TestFailed:
+		return "failed"
+	case TestIgnored:
+		return "ignored"
+	case TestAssumptionFailed:
+		return "assumption failed"
+	}
+	return "status " + strconv.Itoa(int(s))
+}
+
+// InstrumentationEvent is one status report of a running instrumentation.
+type InstrumentationEvent struct {
+	Status InstrumentationStatus
+	// Class and Test name the test case the report is about.
+	Class string
+	Test  string
+	// Current is the 1-based index of the test among NumTests.
+	Current  int
+	NumTests int
+	// Stack is the failure's stack trace for TestFailed and TestError.
+	Stack string
+	// Bundle holds every key of the report, including the ones above.
+	Bundle map[string]string
+}
+
+// InstrumentationResult is the final result of an instrumentation.
+type InstrumentationResult struct {
+	// Code is the instrumentation's result code; -1 (Activity.RESULT_OK) for a completed run.
+	Code int
+	// Bundle holds the result keys, e.g. "stream" with the runner's summary.
+	Bundle map[string]string
+}
+
+// InstrumentationError reports an instrumentation that failed to start or was aborted,
+// for instance because the test process crashed. Failing tests are not an InstrumentationError.
+type InstrumentationError struct {
+	Message string
+}
+
+func (e *InstrumentationError) Error() string {
+	return "adb am instrument: " + e.Message
+}
+
+// RunInstrumentation runs the instrumentation runner (e.g. com.example.test/androidx.test.runner.AndroidJUnitRunner)
+// with `am instrument -r -w`, passing args with -e, and calls handler with every status report
+// as it arrives: a TestStarted event before each test and one with the outcome after it.
+// The instrumentation is stopped when ctx is done.
+func (d Device) RunInstrumentation(ctx context.Context, runner ComponentName, args map[string]string, handler func(InstrumentationEvent)) (InstrumentationResult, error) {
+	cmd := []string{"am", "instrument", "-r", "-w"}
+	for _, key := range sortedKeys(args) {
+		cmd = append(cmd, "-e", shellQuote(key), shellQuote(args[key]))
+	}
+	cmd = append(cmd, shellQuote(runner.String()))
+
+	conn, err := d.openExec(ctx, strings.Join(cmd, " "))
+	if err != nil {
+		return InstrumentationResult{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	result, err := parseInstrumentation(conn, handler)
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	return result, err
+}
+
+// parseInstrumentation reads the raw output of `am instrument -r`. Reports are key=value lines
+// prefixed with INSTRUMENTATION_STATUS (or INSTRUMENTATION_RESULT for the final bundle) and
+// terminated with INSTRUMENTATION_STATUS_CODE (INSTRUMENTATION_CODE); lines without a prefix
+// continue the previous value, as stack traces span many lines.
+func parseInstrumentation(r io.Reader, handler func(InstrumentationEvent)) (result InstrumentationResult, err error) {
+	status := map[string]string{}
+	result.Bundle = map[string]string{}
+	var bundle map[string]string
+	var key string
+	var aborted string
+	var done bool
+
+	br := bufio.NewReader(r)
+	for {
+		line, readErr := br.ReadString('\n')
+		if line == "" && readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				return result, readErr
+			}
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		prefix, rest, _ := strings.Cut(line, ": ")
+		switch prefix {
+		case "INSTRUMENTATION_STATUS", "INSTRUMENTATION_RESULT":
+			bundle = status
+			if prefix == "INSTRUMENTATION_RESULT" {
+				bundle = result.Bundle
+			}
+			var value string
+			key, value, _ = strings.Cut(rest, "=")
+			bundle[key] = value
+		case "INSTRUMENTATION_STATUS_CODE":
+			code, _ := strconv.Atoi(strings.TrimSpace(rest))
+			if handler != nil {
+				handler(newInstrumentationEvent(InstrumentationStatus(code), status))
+			}
+			status, bundle, key = map[string]string{}, nil, ""
+		case "INSTRUMENTATION_CODE":
+			result.Code, _ = strconv.Atoi(strings.TrimSpace(rest))
+			bundle, key, done = nil, "", true
+		case "INSTRUMENTATION_FAILED", "INSTRUMENTATION_ABORTED":
+			aborted = rest
+			bundle, key = nil, ""
+		default:
+			if bundle != nil {
+				bundle[key] += "\n" + line
+			} else if msg, ok := strings.CutPrefix(line, "Error: "); ok && aborted == "" {
+				aborted = msg
+			}
+		}
+	}
+
+	// A crash of the test process is only reported in the result bundle, as
+	// "INSTRUMENTATION_RESULT: shortMsg=Process crashed." with code 0.
+	switch shortMsg := result.Bundle["shortMsg"]; {
+	case aborted != "":
+		return result, &InstrumentationError{Message: aborted}
+	case !done:
+		return result, &InstrumentationError{Message: "instrumentation ended without a result; did the process crash?"}
+	case shortMsg != "":
+		if longMsg := result.Bundle["longMsg"]; longMsg != "" {
+			shortMsg += " " + longMsg
+		}
+		return result, &InstrumentationError{Message: shortMsg}
+	case result.Code != -1 && result.Bundle["stream"] == "":
+		return result, &InstrumentationError{Message: "instrumentation finished with code " + strconv.Itoa(result.Code) + " and no results"}
+	}
+	return result, nil
+}
+
+func newInstrumentationEvent(status InstrumentationStatus, bundle map[string]string) InstrumentationEvent {
+	event := InstrumentationEvent{
+		Status: status,
+		Class:  bundle["class"],
+		Test:   bundle["test"],
+		Stack:  bundle["stack"],
+		Bundle: bundle,
+	}
+	event.Current, _ = strconv.Atoi(bundle["current"])
+	event.NumTests, _ = strconv.Atoi(bundle["numtests"])
+	return event
+}
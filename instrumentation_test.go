@@ -0,0 +1,108 @@
+package gadb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const instrumentationOutput = `INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: id=AndroidJUnitRunner
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: stream=
+com.example.FooTest:
+INSTRUMENTATION_STATUS: test=testPass
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: stream=.
+INSTRUMENTATION_STATUS: test=testPass
+INSTRUMENTATION_STATUS_CODE: 0
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=2
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: test=testFail
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=2
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: stack=java.lang.AssertionError: expected:<1> but was:<2>
+	at org.junit.Assert.fail(Assert.java:89)
+	at com.example.FooTest.testFail(FooTest.java:20)
+
+INSTRUMENTATION_STATUS: test=testFail
+INSTRUMENTATION_STATUS_CODE: -2
+INSTRUMENTATION_RESULT: stream=
+
+Time: 0.042
+There was 1 failure:
+
+FAILURES!!!
+Tests run: 2,  Failures: 1
+
+INSTRUMENTATION_CODE: -1
+`
+
+func Test_parseInstrumentation(t *testing.T) {
+	var events []InstrumentationEvent
+	result, err := parseInstrumentation(strings.NewReader(strings.ReplaceAll(instrumentationOutput, "\n", "\r\n")),
+		func(e InstrumentationEvent) { events = append(events, e) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	for i, want := range []InstrumentationStatus{TestStarted, TestPassed, TestStarted, TestFailed} {
+		if events[i].Status != want {
+			t.Errorf("event %d: got %s, want %s", i, events[i].Status, want)
+		}
+	}
+	fail := events[3]
+	if fail.Class != "com.example.FooTest" || fail.Test != "testFail" || fail.Current != 2 || fail.NumTests != 2 {
+		t.Fatalf("unexpected event: %+v", fail)
+	}
+	if !strings.HasPrefix(fail.Stack, "java.lang.AssertionError") || !strings.Contains(fail.Stack, "\n\tat com.example.FooTest.testFail") {
+		t.Fatalf("unexpected stack: %q", fail.Stack)
+	}
+	if events[0].Bundle["stream"] != "\ncom.example.FooTest:" {
+		t.Fatalf("unexpected stream: %q", events[0].Bundle["stream"])
+	}
+
+	if result.Code != -1 || !strings.Contains(result.Bundle["stream"], "Tests run: 2,  Failures: 1") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func Test_parseInstrumentation_aborted(t *testing.T) {
+	var instrErr *InstrumentationError
+	_, err := parseInstrumentation(strings.NewReader("INSTRUMENTATION_STATUS: class=com.example.FooTest\n"+
+		"INSTRUMENTATION_STATUS_CODE: 1\nINSTRUMENTATION_RESULT: shortMsg=Process crashed.\nINSTRUMENTATION_CODE: 0\n"), nil)
+	if !errors.As(err, &instrErr) || instrErr.Message != "Process crashed." {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = parseInstrumentation(strings.NewReader("INSTRUMENTATION_STATUS: class=com.example.FooTest\n"+
+		"INSTRUMENTATION_STATUS_CODE: 1\nINSTRUMENTATION_ABORTED: System has crashed.\n"), nil)
+	if !errors.As(err, &instrErr) || instrErr.Message != "System has crashed." {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = parseInstrumentation(strings.NewReader("INSTRUMENTATION_CODE: 0\n"), nil)
+	if !errors.As(err, &instrErr) {
+		t.Fatalf("expected an error for a run without results, got %v", err)
+	}
+
+	_, err = parseInstrumentation(strings.NewReader("INSTRUMENTATION_STATUS: class=com.example.FooTest\n"), nil)
+	if !errors.As(err, &instrErr) {
+		t.Fatalf("expected an error for a truncated run, got %v", err)
+	}
+
+	_, err = parseInstrumentation(strings.NewReader("Error: Unable to find instrumentation info for: ComponentInfo{a/b}\n"), nil)
+	if !errors.As(err, &instrErr) || !strings.HasPrefix(instrErr.Message, "Unable to find instrumentation") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Intent flags commonly passed to am; see android.content.Intent for the full list.
+const (
+	FlagIncludeStoppedPackages = 0x00000020
+	FlagActivityClearTask      = 0x00008000
+	FlagActivityClearTop       = 0x04000000
+	FlagActivityNoHistory      = 0x40000000
+	FlagActivityNewTask        = 0x10000000
+	FlagActivitySingleTop      = 0x20000000
+	FlagReceiverForeground     = 0x10000000
+)
+
+// Intent describes an intent for am. Zero fields are left out of the command line.
+type Intent struct {
+	Action string
+	// Data is the data URI (-d), e.g. "https://example.com/path?q=1".
+	Data string
+	// MimeType is the explicit data type (-t).
+	MimeType string
+	// Component makes the intent explicit (-n); Package limits resolution to one app (-p).
+	Component ComponentName
+	Package   string
+	// Categories are added with -c.
+	Categories []string
+	// Extras by type: --es, --ei, --ez and --el.
+	StringExtras map[string]string
+	IntExtras    map[string]int
+	BoolExtras   map[string]bool
+	LongExtras   map[string]int64
+	// Flags is a combination of the Flag constants (-f).
+	Flags int
+}
+
+// Args returns the intent as am arguments, each quoted for the device shell. Extras are
+// emitted in key order so that the command line is stable.
+func (in Intent) Args() []string {
+	var args []string
+	add := func(flag string, values ...string) {
+		args = append(args, flag)
+		for _, v := range values {
+			args = append(args, shellQuote(v))
+		}
+	}
+
+	if in.Action != "" {
+		add("-a", in.Action)
+	}
+	if in.Data != "" {
+		add("-d", in.Data)
+	}
+	if in.MimeType != "" {
+		add("-t", in.MimeType)
+	}
+	for _, category := range in.Categories {
+		add("-c", category)
+	}
+	if in.Component.Package != "" {
+		add("-n", in.Component.String())
+	}
+	if in.Package != "" {
+		add("-p", in.Package)
+	}
+	for _, key := range sortedKeys(in.StringExtras) {
+		add("--es", key, in.StringExtras[key])
+	}
+	for _, key := range sortedKeys(in.IntExtras) {
+		add("--ei", key, strconv.Itoa(in.IntExtras[key]))
+	}
+	for _, key := range sortedKeys(in.BoolExtras) {
+		add("--ez", key, strconv.FormatBool(in.BoolExtras[key]))
+	}
+	for _, key := range sortedKeys(in.LongExtras) {
+		add("--el", key, strconv.FormatInt(in.LongExtras[key], 10))
+	}
+	if in.Flags != 0 {
+		add("-f", fmt.Sprintf("0x%08x", in.Flags))
+	}
+	return args
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ErrIntentNotResolved is matched by an *IntentError when no component handles the intent
+// or the named component doesn't exist.
+var ErrIntentNotResolved = errors.New("intent not resolved")
+
+// IntentError is an error reported by am, such as an unresolvable intent or a permission denial.
+type IntentError struct {
+	Op      string
+	Message string
+}
+
+func (e *IntentError) Error() string {
+	return fmt.Sprintf("adb am %s: %s", e.Op, e.Message)
+}
+
+// Is makes errors.Is(err, ErrIntentNotResolved) work on resolution failures.
+func (e *IntentError) Is(target error) bool {
+	if target != ErrIntentNotResolved {
+		return false
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "unable to resolve") || strings.Contains(message, "does not exist") ||
+		strings.Contains(message, "not found")
+}
+
+// amError returns the *IntentError for the first error am printed in output, or nil. am
+// writes "Error: ..." (or "Error type N" followed by the message) for resolution failures and
+// "Exception occurred while executing ..." followed by the exception for everything else.
+func amError(op, output string) error {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		next := ""
+		if i+1 < len(lines) {
+			next = strings.TrimSpace(lines[i+1])
+		}
+		switch {
+		case strings.HasPrefix(line, "Error type "):
+			if next != "" {
+				return &IntentError{Op: op, Message: strings.TrimPrefix(next, "Error: ")}
+			}
+			return &IntentError{Op: op, Message: line}
+		case strings.HasPrefix(line, "Error: "):
+			return &IntentError{Op: op, Message: strings.TrimPrefix(line, "Error: ")}
+		case strings.HasPrefix(line, "Security exception: "):
+			return &IntentError{Op: op, Message: strings.TrimPrefix(line, "Security exception: ")}
+		case strings.HasPrefix(line, "Exception occurred while executing"):
+			if next != "" {
+				return &IntentError{Op: op, Message: next}
+			}
+			return &IntentError{Op: op, Message: line}
+		}
+	}
+	return nil
+}
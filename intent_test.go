@@ -0,0 +1,29 @@
+package gadb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntent_Args(t *testing.T) {
+	in := Intent{
+		Action:       "android.intent.action.VIEW",
+		Data:         "https://example.com/a?b=1&c='d'",
+		Component:    ComponentName{Package: "com.example", Class: "com.example.Main"},
+		Categories:   []string{"android.intent.category.BROWSABLE"},
+		StringExtras: map[string]string{"z": "last", "a": "first one"},
+		IntExtras:    map[string]int{"n": -3},
+		BoolExtras:   map[string]bool{"on": true},
+		LongExtras:   map[string]int64{"ts": 1 << 40},
+		Flags:        FlagActivityNewTask | FlagActivityClearTask,
+	}
+	want := `-a 'android.intent.action.VIEW' -d 'https://example.com/a?b=1&c='\''d'\''' ` +
+		`-c 'android.intent.category.BROWSABLE' -n 'com.example/com.example.Main' ` +
+		`--es 'a' 'first one' --es 'z' 'last' --ei 'n' '-3' --ez 'on' 'true' --el 'ts' '1099511627776' -f '0x10008000'`
+	if got := strings.Join(in.Args(), " "); got != want {
+		t.Fatalf("unexpected args:\n got %s\nwant %s", got, want)
+	}
+	if len(Intent{}.Args()) != 0 {
+		t.Fatal("expected no args for an empty intent")
+	}
+}
@@ -0,0 +1,81 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrKeyguardShowing is returned by Unlock when the keyguard is still showing afterwards,
+// e.g. because the PIN was wrong or the device needs a password it wasn't given.
+var ErrKeyguardShowing = errors.New("keyguard still showing")
+
+// unlockTimeout bounds how long Unlock waits for the keyguard to go away.
+const unlockTimeout = 5 * time.Second
+
+// Unlock wakes the screen and dismisses the keyguard with `wm dismiss-keyguard`. For a
+// secure lock screen pass the PIN or password, which is typed into the bouncer followed by
+// enter. Unlock then checks `dumpsys window` until the keyguard is gone.
+func (d Device) Unlock(pin ...string) error {
+	input := d.Input()
+	if err := input.KeyEvent(KeyWakeup); err != nil {
+		return err
+	}
+	output, err := d.RunShellCommand("wm dismiss-keyguard")
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb wm dismiss-keyguard: %s", output)
+	}
+
+	if len(pin) != 0 && pin[0] != "" {
+		// Give the bouncer a moment to take focus before typing.
+		time.Sleep(500 * time.Millisecond)
+		if err = input.Text(pin[0]); err != nil {
+			return err
+		}
+		if err = input.KeyEvent(KeyEnter); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(unlockTimeout)
+	for {
+		showing, err := d.KeyguardShowing()
+		if err != nil || !showing {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("adb unlock: %w", ErrKeyguardShowing)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// KeyguardShowing reports whether the lock screen is showing, according to the window manager.
+func (d Device) KeyguardShowing() (bool, error) {
+	output, err := d.Dumpsys("window")
+	if err != nil {
+		return false, err
+	}
+	showing, ok := parseKeyguardShowing(output)
+	if !ok {
+		return false, errors.New("adb dumpsys window: keyguard state not found")
+	}
+	return showing, nil
+}
+
+// parseKeyguardShowing finds the keyguard state in dumpsys window output. The field has been
+// renamed over the years, so the known names are tried from newest to oldest.
+func parseKeyguardShowing(output string) (showing, ok bool) {
+	for _, key := range []string{"mKeyguardShowing=", "isKeyguardShowingAndNotOccluded=", "mShowingLockscreen="} {
+		for _, field := range strings.Fields(output) {
+			if value, found := strings.CutPrefix(field, key); found {
+				return value == "true", true
+			}
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,19 @@
+package gadb
+
+import "testing"
+
+func Test_parseKeyguardShowing(t *testing.T) {
+	for output, want := range map[string]bool{
+		"KeyguardController:\n  mKeyguardShowing=true mAodShowing=false mKeyguardGoingAway=false\n": true,
+		"  KeyguardServiceDelegate\n    isKeyguardShowingAndNotOccluded=false\n":                    false,
+		"    mShowingLockscreen=true mShowingDream=false mDreamingLockscreen=true\n":                true,
+	} {
+		showing, ok := parseKeyguardShowing(output)
+		if !ok || showing != want {
+			t.Errorf("%q: got %v (found %v), want %v", output, showing, ok, want)
+		}
+	}
+	if _, ok := parseKeyguardShowing("WINDOW MANAGER POLICY STATE\n"); ok {
+		t.Fatal("expected no keyguard state")
+	}
+}
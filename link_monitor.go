@@ -0,0 +1,149 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LinkSample is one measurement of the connection to a device.
+type LinkSample struct {
+	Time time.Time
+	// Latency is the round trip of a sync STAT, including opening the transport.
+	Latency time.Duration
+	// Throughput is the device-to-host rate in bytes per second while streaming a sample.
+	Throughput float64
+	// Err is set if a probe failed; the other fields are then unreliable.
+	Err error
+}
+
+// LinkEvent reports that the link crossed the monitor's thresholds.
+type LinkEvent struct {
+	Sample LinkSample
+	// Degraded is true when the link dropped below the thresholds and false when it recovered.
+	Degraded bool
+	// Reason describes which threshold was crossed, e.g. "latency 480ms > 250ms".
+	Reason string
+}
+
+// LinkMonitorOptions configures MonitorLink. Zero fields use the defaults noted below.
+type LinkMonitorOptions struct {
+	// Interval between samples; 10s by default.
+	Interval time.Duration
+	// SampleSize is the number of bytes streamed to measure throughput; 1 MiB by default.
+	SampleSize int64
+	// MaxLatency and MinThroughput (bytes per second) mark the link as degraded when exceeded;
+	// a zero value disables that check.
+	MaxLatency    time.Duration
+	MinThroughput float64
+	// Failures is the number of consecutive degraded samples before an event is emitted,
+	// damping a single slow probe; 1 by default.
+	Failures int
+	// OnSample, if set, is called with every sample, e.g. to export metrics.
+	OnSample func(LinkSample)
+}
+
+// MonitorLink periodically measures latency and throughput to the device and sends an event
+// whenever the link degrades past the thresholds or recovers. It is mainly useful for devices
+// connected over TCP, so that orchestration can move work back to USB before jobs fail.
+// The channel is closed when ctx is done.
+func (d Device) MonitorLink(ctx context.Context, opts LinkMonitorOptions) <-chan LinkEvent {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = 1024 * 1024
+	}
+	if opts.Failures <= 0 {
+		opts.Failures = 1
+	}
+
+	events := make(chan LinkEvent, 1)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		var degraded bool
+		var bad int
+		for {
+			sample := d.probeLink(ctx, opts.SampleSize)
+			if ctx.Err() != nil {
+				return
+			}
+			if opts.OnSample != nil {
+				opts.OnSample(sample)
+			}
+
+			reason := opts.degradedReason(sample)
+			if reason != "" {
+				bad++
+			} else {
+				bad = 0
+			}
+
+			var event *LinkEvent
+			switch {
+			case !degraded && bad >= opts.Failures:
+				degraded = true
+				event = &LinkEvent{Sample: sample, Degraded: true, Reason: reason}
+			case degraded && bad == 0:
+				degraded = false
+				event = &LinkEvent{Sample: sample, Reason: "recovered"}
+			}
+			if event != nil {
+				select {
+				case events <- *event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+func (opts LinkMonitorOptions) degradedReason(sample LinkSample) string {
+	switch {
+	case sample.Err != nil:
+		return fmt.Sprintf("probe failed: %v", sample.Err)
+	case opts.MaxLatency > 0 && sample.Latency > opts.MaxLatency:
+		return fmt.Sprintf("latency %s > %s", sample.Latency.Round(time.Millisecond), opts.MaxLatency)
+	case opts.MinThroughput > 0 && sample.Throughput < opts.MinThroughput:
+		return fmt.Sprintf("throughput %.0f B/s < %.0f B/s", sample.Throughput, opts.MinThroughput)
+	}
+	return ""
+}
+
+// probeLink takes one latency and throughput sample.
+func (d Device) probeLink(ctx context.Context, size int64) (sample LinkSample) {
+	sample.Time = time.Now()
+	if _, sample.Err = d.Stat("/"); sample.Err != nil {
+		return sample
+	}
+	sample.Latency = time.Since(sample.Time)
+
+	start := time.Now()
+	var conn *execConn
+	if conn, sample.Err = d.openExec(ctx, fmt.Sprintf("head -c %d /dev/zero", size)); sample.Err != nil {
+		return sample
+	}
+	defer func() { _ = conn.Close() }()
+
+	var n int64
+	n, sample.Err = io.Copy(io.Discard, conn)
+	if sample.Err == nil && n < size {
+		sample.Err = fmt.Errorf("short sample: %d of %d bytes", n, size)
+	}
+	if elapsed := time.Since(start); elapsed > 0 {
+		sample.Throughput = float64(n) / elapsed.Seconds()
+	}
+	return sample
+}
@@ -0,0 +1,25 @@
+package gadb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLinkMonitorOptions_degradedReason(t *testing.T) {
+	opts := LinkMonitorOptions{MaxLatency: 250 * time.Millisecond, MinThroughput: 1 << 20}
+	tests := []struct {
+		sample LinkSample
+		want   string
+	}{
+		{LinkSample{Latency: 10 * time.Millisecond, Throughput: 4 << 20}, ""},
+		{LinkSample{Latency: 480 * time.Millisecond, Throughput: 4 << 20}, "latency 480ms > 250ms"},
+		{LinkSample{Latency: 10 * time.Millisecond, Throughput: 1000}, "throughput 1000 B/s < 1048576 B/s"},
+		{LinkSample{Err: errors.New("closed")}, "probe failed: closed"},
+	}
+	for _, tt := range tests {
+		if got := opts.degradedReason(tt.sample); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sdkLocaleProp is the first API level (Android 5.0) that keeps the locale in
+// persist.sys.locale as a language tag; earlier ones split it into persist.sys.language and
+// persist.sys.country.
+const sdkLocaleProp = 21
+
+// localeTagRe matches BCP 47 language tags such as "en", "fr-CA" or "zh-Hans-CN".
+var localeTagRe = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// Locale returns the system locale as a BCP 47 language tag such as "en-US": the one set
+// with SetLocale or the settings app, or else the build's default.
+func (d Device) Locale() (string, error) {
+	for _, key := range []string{"persist.sys.locale", "ro.product.locale"} {
+		if locale, err := d.GetProp(key); err != nil || locale != "" {
+			return locale, err
+		}
+	}
+	language, err := d.GetProp("ro.product.locale.language")
+	if err != nil || language == "" {
+		return "", err
+	}
+	region, err := d.GetProp("ro.product.locale.region")
+	if err != nil || region == "" {
+		return language, err
+	}
+	return language + "-" + region, nil
+}
+
+// SetLocale sets the system locale to the BCP 47 language tag tag, such as "fr-FR", then
+// reboots the device for the framework to pick it up, waiting until it has booted again,
+// DefaultBootTimeout passes or ctx is done. The shell user may not write the locale
+// properties, and Android has no shell command for the system locale, so SetLocale needs
+// adbd to run as root and returns ErrRootRequired, before changing anything, otherwise.
+func (d Device) SetLocale(ctx context.Context, tag string) error {
+	if !localeTagRe.MatchString(tag) {
+		return fmt.Errorf("adb locale: invalid language tag %q", tag)
+	}
+	root, err := d.IsRoot()
+	if err != nil {
+		return err
+	}
+	if !root {
+		return fmt.Errorf("adb locale: %w", ErrRootRequired)
+	}
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return err
+	}
+	for _, prop := range localeProps(tag, sdk) {
+		if err = d.SetProp(prop[0], prop[1]); err != nil {
+			return fmt.Errorf("adb locale: %w", err)
+		}
+	}
+
+	return d.Reboot(ctx, RebootSystem, RebootOptions{Wait: true})
+}
+
+// localeProps returns the key, value pairs of the properties that hold tag on a device of API
+// level sdk.
+func localeProps(tag string, sdk int) [][2]string {
+	if sdk >= sdkLocaleProp {
+		return [][2]string{{"persist.sys.locale", tag}}
+	}
+	parts := strings.Split(tag, "-")
+	region := ""
+	for _, part := range parts[1:] {
+		// The region is the two-letter or three-digit subtag; scripts have four letters.
+		if len(part) == 2 || len(part) == 3 && strings.Trim(part, "0123456789") == "" {
+			region = strings.ToUpper(part)
+			break
+		}
+	}
+	return [][2]string{{"persist.sys.language", strings.ToLower(parts[0])}, {"persist.sys.country", region}}
+}
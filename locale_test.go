@@ -0,0 +1,32 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_localeProps(t *testing.T) {
+	for _, tt := range []struct {
+		tag  string
+		sdk  int
+		want [][2]string
+	}{
+		{"fr-FR", 34, [][2]string{{"persist.sys.locale", "fr-FR"}}},
+		{"fr-FR", 19, [][2]string{{"persist.sys.language", "fr"}, {"persist.sys.country", "FR"}}},
+		{"zh-Hans-cn", 19, [][2]string{{"persist.sys.language", "zh"}, {"persist.sys.country", "CN"}}},
+		{"es-419", 19, [][2]string{{"persist.sys.language", "es"}, {"persist.sys.country", "419"}}},
+		{"de", 19, [][2]string{{"persist.sys.language", "de"}, {"persist.sys.country", ""}}},
+	} {
+		if got := localeProps(tt.tag, tt.sdk); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s on %d: got %v, want %v", tt.tag, tt.sdk, got, tt.want)
+		}
+	}
+}
+
+func Test_localeTagRe(t *testing.T) {
+	for tag, want := range map[string]bool{"en": true, "fr-CA": true, "zh-Hans-CN": true, "": false, "en_US": false, "fr-FR; reboot": false} {
+		if got := localeTagRe.MatchString(tag); got != want {
+			t.Errorf("%q: got %t, want %t", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package logcat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logIDBuffers maps the log ids of logger_entry to buffer names.
+var logIDBuffers = []Buffer{BufferMain, BufferRadio, BufferEvents, BufferSystem, BufferCrash, "stats", "security", "kernel"}
+
+// Event log value types, from liblog's log/log_event_list.h.
+const (
+	eventTypeInt    = 0
+	eventTypeLong   = 1
+	eventTypeString = 2
+	eventTypeList   = 3
+	eventTypeFloat  = 4
+)
+
+// loggerEntryV1Size is the header size of logger_entry v1, which left hdr_size as padding.
+const loggerEntryV1Size = 20
+
+// BinaryEntry is a log entry read from `logcat -B`. For the text buffers the embedded
+// LogEntry is filled in as for threadtime output; for the binary buffers (events, stats and
+// security) Event holds the decoded payload instead of a Message.
+type BinaryEntry struct {
+	LogEntry
+	Buffer Buffer
+	// UID is the uid of the logging process; -1 when the device's log format predates it.
+	UID   int
+	Event *Event
+}
+
+// Event is the payload of an event log entry.
+type Event struct {
+	TagID int32
+	// Values are the typed fields: int32, int64, float32, string, or []any for a list.
+	// Most events log a single list.
+	Values []any
+}
+
+// BinaryReader reads the binary log format printed by `logcat -B`: a sequence of
+// logger_entry headers, each followed by its payload. It is cheaper to produce and parse
+// than the text formats and preserves the typed fields of event log entries.
+type BinaryReader struct {
+	br *bufio.Reader
+	// EventTags names event tag ids, as parsed by ParseEventTags; events with an unknown
+	// tag get the id as their Tag.
+	EventTags map[int32]string
+}
+
+// NewBinaryReader returns a BinaryReader reading from r.
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{br: bufio.NewReader(r)}
+}
+
+// Next returns the next entry. It returns io.EOF at the end of the stream.
+func (r *BinaryReader) Next() (entry BinaryEntry, err error) {
+	var prefix [4]byte
+	if _, err = io.ReadFull(r.br, prefix[:]); err != nil {
+		return BinaryEntry{}, err
+	}
+	payloadLen := int(binary.LittleEndian.Uint16(prefix[0:]))
+	hdrSize := int(binary.LittleEndian.Uint16(prefix[2:]))
+	if hdrSize == 0 {
+		hdrSize = loggerEntryV1Size
+	}
+	if hdrSize < loggerEntryV1Size || hdrSize > 128 {
+		return BinaryEntry{}, fmt.Errorf("logcat: invalid binary entry header size %d", hdrSize)
+	}
+
+	buf := make([]byte, hdrSize-4+payloadLen)
+	if _, err = io.ReadFull(r.br, buf); err != nil {
+		return BinaryEntry{}, noEOF(err)
+	}
+	header, payload := buf[:hdrSize-4], buf[hdrSize-4:]
+
+	entry.PID = int(int32(binary.LittleEndian.Uint32(header[0:])))
+	entry.TID = int(binary.LittleEndian.Uint32(header[4:]))
+	entry.Time = time.Unix(int64(binary.LittleEndian.Uint32(header[8:])), int64(binary.LittleEndian.Uint32(header[12:])))
+	entry.Buffer = BufferMain
+	entry.UID = -1
+	if len(header) >= 20 {
+		if lid := int(binary.LittleEndian.Uint32(header[16:])); lid < len(logIDBuffers) {
+			entry.Buffer = logIDBuffers[lid]
+		} else {
+			entry.Buffer = Buffer(strconv.Itoa(lid))
+		}
+	}
+	if len(header) >= 24 {
+		entry.UID = int(binary.LittleEndian.Uint32(header[20:]))
+	}
+
+	switch entry.Buffer {
+	case BufferEvents, "stats", "security":
+		err = r.decodeEvent(&entry, payload)
+	default:
+		err = decodeText(&entry, payload)
+	}
+	return entry, err
+}
+
+// decodeText decodes a text payload: the priority byte, then the NUL-terminated tag and message.
+func decodeText(entry *BinaryEntry, payload []byte) error {
+	if len(payload) < 2 {
+		return errors.New("logcat: truncated binary text entry")
+	}
+	entry.Priority = Priority(payload[0])
+	tag, message, _ := bytes.Cut(payload[1:], []byte{0})
+	entry.Tag = string(tag)
+	entry.Message = strings.TrimRight(string(bytes.TrimRight(message, "\x00")), "\n")
+	return nil
+}
+
+// decodeEvent decodes an event payload: the int32 tag id followed by typed values.
+func (r *BinaryReader) decodeEvent(entry *BinaryEntry, payload []byte) error {
+	if len(payload) < 4 {
+		return errors.New("logcat: truncated binary event entry")
+	}
+	event := &Event{TagID: int32(binary.LittleEndian.Uint32(payload))}
+	entry.Event = event
+	entry.Priority = Info
+	entry.Tag = strconv.Itoa(int(event.TagID))
+	if name, ok := r.EventTags[event.TagID]; ok {
+		entry.Tag = name
+	}
+
+	rest := payload[4:]
+	for len(rest) > 0 {
+		value, n, err := decodeEventValue(rest)
+		if err != nil {
+			return err
+		}
+		event.Values = append(event.Values, value)
+		rest = rest[n:]
+	}
+	return nil
+}
+
+// decodeEventValue decodes one typed value and returns the number of bytes it used.
+func decodeEventValue(b []byte) (value any, n int, err error) {
+	truncated := errors.New("logcat: truncated event value")
+	if len(b) < 1 {
+		return nil, 0, truncated
+	}
+	switch b[0] {
+	case eventTypeInt:
+		if len(b) < 5 {
+			return nil, 0, truncated
+		}
+		return int32(binary.LittleEndian.Uint32(b[1:])), 5, nil
+	case eventTypeLong:
+		if len(b) < 9 {
+			return nil, 0, truncated
+		}
+		return int64(binary.LittleEndian.Uint64(b[1:])), 9, nil
+	case eventTypeFloat:
+		if len(b) < 5 {
+			return nil, 0, truncated
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b[1:])), 5, nil
+	case eventTypeString:
+		if len(b) < 5 {
+			return nil, 0, truncated
+		}
+		size := int(binary.LittleEndian.Uint32(b[1:]))
+		if size < 0 || len(b) < 5+size {
+			return nil, 0, truncated
+		}
+		return string(b[5 : 5+size]), 5 + size, nil
+	case eventTypeList:
+		if len(b) < 2 {
+			return nil, 0, truncated
+		}
+		count := int(b[1])
+		list := make([]any, 0, count)
+		n = 2
+		for range count {
+			item, size, err := decodeEventValue(b[n:])
+			if err != nil {
+				return nil, 0, err
+			}
+			list = append(list, item)
+			n += size
+		}
+		return list, n, nil
+	}
+	return nil, 0, fmt.Errorf("logcat: unknown event value type %d", b[0])
+}
+
+// ParseEventTags parses an event tag map such as /system/etc/event-log-tags, whose lines
+// read "<id> <name> [(<field>|<type>...)...]", into tag names by id.
+func ParseEventTags(r io.Reader) (map[int32]string, error) {
+	tags := map[int32]string{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		id, err := strconv.ParseInt(fields[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		tags[int32(id)] = fields[1]
+	}
+	return tags, sc.Err()
+}
+
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package logcat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loggerEntry encodes a v4 logger_entry with the given log id and payload.
+func loggerEntry(lid uint32, payload []byte) []byte {
+	var b bytes.Buffer
+	for _, v := range []any{uint16(len(payload)), uint16(28), int32(1234), uint32(5678), uint32(1760445296), uint32(789_000_000), lid, uint32(10123)} {
+		_ = binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.Write(payload)
+	return b.Bytes()
+}
+
+func TestBinaryReader_Next(t *testing.T) {
+	var event bytes.Buffer
+	_ = binary.Write(&event, binary.LittleEndian, int32(30001))
+	event.Write([]byte{eventTypeList, 4, eventTypeInt})
+	_ = binary.Write(&event, binary.LittleEndian, int32(-1))
+	event.WriteByte(eventTypeLong)
+	_ = binary.Write(&event, binary.LittleEndian, int64(1)<<40)
+	event.WriteByte(eventTypeString)
+	_ = binary.Write(&event, binary.LittleEndian, uint32(5))
+	event.WriteString("hello")
+	event.WriteByte(eventTypeFloat)
+	_ = binary.Write(&event, binary.LittleEndian, math.Float32bits(1.5))
+
+	stream := append(loggerEntry(0, []byte("\x04ActivityManager\x00Start proc\n\x00")), loggerEntry(2, event.Bytes())...)
+	r := NewBinaryReader(bytes.NewReader(stream))
+	r.EventTags = map[int32]string{30001: "am_finish_activity"}
+
+	entry, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LogEntry{
+		Time:     time.Date(2025, 10, 14, 12, 34, 56, 789_000_000, time.UTC),
+		PID:      1234,
+		TID:      5678,
+		Priority: Info,
+		Tag:      "ActivityManager",
+		Message:  "Start proc",
+	}
+	if !entry.Time.Equal(want.Time) {
+		t.Fatalf("got time %v, want %v", entry.Time, want.Time)
+	}
+	entry.Time = want.Time
+	if entry.LogEntry != want || entry.Buffer != BufferMain || entry.UID != 10123 || entry.Event != nil {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	entry, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Buffer != BufferEvents || entry.Tag != "am_finish_activity" || entry.Event == nil || entry.Event.TagID != 30001 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	values := []any{[]any{int32(-1), int64(1) << 40, "hello", float32(1.5)}}
+	if !reflect.DeepEqual(entry.Event.Values, values) {
+		t.Fatalf("got values %#v, want %#v", entry.Event.Values, values)
+	}
+
+	if _, err = r.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestBinaryReader_Next_v1(t *testing.T) {
+	// v1 entries have no hdr_size, log id or uid.
+	var b bytes.Buffer
+	payload := []byte("\x06Tag\x00boom\x00")
+	for _, v := range []any{uint16(len(payload)), uint16(0), int32(1), uint32(2), uint32(3), uint32(4)} {
+		_ = binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.Write(payload)
+
+	entry, err := NewBinaryReader(&b).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Priority != Error || entry.Tag != "Tag" || entry.Message != "boom" || entry.Buffer != BufferMain || entry.UID != -1 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+}
+
+func TestBinaryReader_Next_truncated(t *testing.T) {
+	stream := loggerEntry(0, []byte("\x04Tag\x00message\x00"))
+	_, err := NewBinaryReader(bytes.NewReader(stream[:len(stream)-3])).Next()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestParseEventTags(t *testing.T) {
+	tags, err := ParseEventTags(strings.NewReader("# comment\n42 answer (to life|1|5)\n30001 am_finish_activity (User|1|5),(Token|1|5)\n\nbogus line\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int32]string{42: "answer", 30001: "am_finish_activity"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatalf("got %v, want %v", tags, want)
+	}
+}
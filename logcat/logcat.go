@@ -0,0 +1,147 @@
+// Package logcat parses Android log output as printed by `logcat -v threadtime`, the
+// default format of modern logcat:
+//
+//	10-14 12:34:56.789  1234  5678 I ActivityManager: Start proc 4321:com.example/u0a123
+//
+// BinaryReader reads the binary format of `logcat -B` instead. The package has no
+// dependencies; Device.LogcatEntries and Device.LogcatBinaryEntries in gadb stream entries
+// parsed with it.
+package logcat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Priority is the priority of a log entry, ordered from Verbose to Silent.
+type Priority int
+
+const (
+	Verbose Priority = 2 + iota
+	Debug
+	Info
+	Warn
+	Error
+	Fatal
+	Silent
+)
+
+var priorityLetters = "VDIWEFS"
+
+// String returns the letter logcat uses for the priority, such as "I".
+func (p Priority) String() string {
+	if p < Verbose || p > Silent {
+		return "?"
+	}
+	return priorityLetters[p-Verbose : p-Verbose+1]
+}
+
+// ParsePriority parses a priority letter such as "W".
+func ParsePriority(s string) (Priority, error) {
+	if len(s) == 1 {
+		if i := strings.Index(priorityLetters, strings.ToUpper(s)); i >= 0 {
+			return Verbose + Priority(i), nil
+		}
+	}
+	return 0, fmt.Errorf("logcat: invalid priority %q", s)
+}
+
+// LogEntry is one line of the log.
+type LogEntry struct {
+	Time     time.Time
+	PID      int
+	TID      int
+	Priority Priority
+	Tag      string
+	Message  string
+}
+
+// ErrNotLogEntry is returned by ParseLine for lines that aren't log entries, such as the
+// "--------- beginning of main" buffer headers.
+var ErrNotLogEntry = errors.New("logcat: not a log entry")
+
+const (
+	threadtimeLayout     = "01-02 15:04:05.000"
+	threadtimeYearLayout = "2006-01-02 15:04:05.000"
+)
+
+// ParseLine parses a threadtime line. threadtime omits the year, so the time gets the given
+// year, in loc; with `-v year` the year in the line is used instead.
+func ParseLine(line string, year int, loc *time.Location) (entry LogEntry, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return LogEntry{}, ErrNotLogEntry
+	}
+
+	layout := threadtimeLayout
+	if len(fields[0]) == len("2006-01-02") {
+		layout = threadtimeYearLayout
+	}
+	if entry.Time, err = time.ParseInLocation(layout, fields[0]+" "+fields[1], loc); err != nil {
+		return LogEntry{}, ErrNotLogEntry
+	}
+	if layout == threadtimeLayout {
+		entry.Time = entry.Time.AddDate(year, 0, 0)
+	}
+	if entry.PID, err = strconv.Atoi(fields[2]); err != nil {
+		return LogEntry{}, ErrNotLogEntry
+	}
+	if entry.TID, err = strconv.Atoi(fields[3]); err != nil {
+		return LogEntry{}, ErrNotLogEntry
+	}
+	if entry.Priority, err = ParsePriority(fields[4]); err != nil {
+		return LogEntry{}, ErrNotLogEntry
+	}
+
+	// The tag is padded to eight columns and may itself contain spaces, so cut the rest of
+	// the line after the priority at the first ": ".
+	rest := line
+	for range 5 {
+		rest = strings.TrimLeft(rest, " ")
+		rest = rest[strings.IndexByte(rest, ' ')+1:]
+	}
+	if tag, message, ok := strings.Cut(rest, ": "); ok {
+		entry.Tag, entry.Message = strings.TrimRight(tag, " "), message
+	} else {
+		entry.Tag = strings.TrimRight(strings.TrimSuffix(strings.TrimRight(rest, " "), ":"), " ")
+	}
+	return entry, nil
+}
+
+// Parser reads log entries from a stream of threadtime output.
+type Parser struct {
+	br *bufio.Reader
+	// Year is given to entries without one; the current year by default.
+	Year int
+	// Location is the time zone of the timestamps; UTC by default. Set it to the device's
+	// zone (persist.sys.timezone) for correct absolute times.
+	Location *time.Location
+}
+
+// NewParser returns a Parser reading from r.
+func NewParser(r io.Reader) *Parser {
+	return &Parser{br: bufio.NewReader(r), Year: time.Now().Year(), Location: time.UTC}
+}
+
+// Next returns the next log entry, skipping lines that aren't entries. It returns io.EOF at
+// the end of the stream.
+func (p *Parser) Next() (LogEntry, error) {
+	for {
+		line, err := p.br.ReadString('\n')
+		if line == "" && err != nil {
+			return LogEntry{}, err
+		}
+		entry, parseErr := ParseLine(strings.TrimRight(line, "\r\n"), p.Year, p.Location)
+		if parseErr == nil {
+			return entry, nil
+		}
+		if err != nil {
+			return LogEntry{}, err
+		}
+	}
+}
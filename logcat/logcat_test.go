@@ -0,0 +1,81 @@
+package logcat
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	entry, err := ParseLine("10-14 12:34:56.789  1234  5678 I ActivityManager: Start proc 4321:com.example/u0a123", 2026, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LogEntry{
+		Time:     time.Date(2026, 10, 14, 12, 34, 56, 789_000_000, time.UTC),
+		PID:      1234,
+		TID:      5678,
+		Priority: Info,
+		Tag:      "ActivityManager",
+		Message:  "Start proc 4321:com.example/u0a123",
+	}
+	if entry != want {
+		t.Fatalf("got %+v, want %+v", entry, want)
+	}
+
+	entry, err = ParseLine("2025-01-02 03:04:05.006   100   101 W My Tag  : message: with colons", 2026, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Time.Year() != 2025 || entry.Priority != Warn || entry.Tag != "My Tag" || entry.Message != "message: with colons" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	entry, err = ParseLine("10-14 12:34:56.789  1234  5678 D chatty  :", 2026, time.UTC)
+	if err != nil || entry.Tag != "chatty" || entry.Message != "" {
+		t.Fatalf("unexpected entry %+v, %v", entry, err)
+	}
+
+	for _, line := range []string{"--------- beginning of main", "", "10-14 12:34:56.789  x  5678 I Tag: msg"} {
+		if _, err = ParseLine(line, 2026, time.UTC); !errors.Is(err, ErrNotLogEntry) {
+			t.Errorf("%q: expected ErrNotLogEntry, got %v", line, err)
+		}
+	}
+}
+
+func TestParser_Next(t *testing.T) {
+	p := NewParser(strings.NewReader("--------- beginning of main\r\n" +
+		"10-14 12:00:00.000     1     1 E Tag1    : first\r\n" +
+		"10-14 12:00:01.000     2     2 F Tag2    : second"))
+	var messages []string
+	for {
+		entry, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, entry.Priority.String()+" "+entry.Message)
+	}
+	if strings.Join(messages, ",") != "E first,F second" {
+		t.Fatalf("unexpected messages %q", messages)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, s := range []string{"V", "d", "I", "W", "E", "F", "S"} {
+		p, err := ParsePriority(s)
+		if err != nil || p.String() != strings.ToUpper(s) {
+			t.Errorf("%q: got %v, %v", s, p, err)
+		}
+	}
+	if _, err := ParsePriority("X"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if Verbose >= Error || Priority(0).String() != "?" {
+		t.Fatal("unexpected priority ordering")
+	}
+}
@@ -0,0 +1,104 @@
+package logcat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter shows entries of Tag at Priority or above. Tag "*" applies to every tag not named
+// by another filter; Filter{"*", Silent} therefore limits the output to the named tags.
+type Filter struct {
+	Tag      string
+	Priority Priority
+}
+
+// String returns the logcat filter spec, such as "ActivityManager:I".
+func (f Filter) String() string {
+	return f.Tag + ":" + f.Priority.String()
+}
+
+// Buffer is a log buffer of the device.
+type Buffer string
+
+const (
+	BufferMain   Buffer = "main"
+	BufferSystem Buffer = "system"
+	BufferCrash  Buffer = "crash"
+	BufferEvents Buffer = "events"
+	BufferRadio  Buffer = "radio"
+	// BufferAll reads every buffer.
+	BufferAll Buffer = "all"
+)
+
+// Options selects the entries logcat prints.
+type Options struct {
+	// Buffers are the buffers to read (-b); logcat reads main, system and crash by default.
+	Buffers []Buffer
+	Filters []Filter
+	// PID only shows entries of the process (--pid).
+	PID int
+	// UIDs only shows entries logged by these uids (--uid, Android 9 and later).
+	UIDs []int
+	// Regex only shows entries whose message matches the expression (-e). logcat uses
+	// ECMAScript regular expressions, not Go's syntax.
+	Regex string
+
+	// Since starts at the first entry logged at or after the time (-T), e.g. the start of a
+	// test; Tail starts with the last Tail entries instead. At most one of them may be set.
+	Since time.Time
+	Tail  int
+	// Dump prints the selected entries and exits instead of following the log (-d), giving
+	// a bounded snapshot.
+	Dump bool
+}
+
+// Args returns the logcat arguments for the options, unquoted.
+func (o Options) Args() ([]string, error) {
+	var args []string
+	for _, b := range o.Buffers {
+		if b == "" || strings.ContainsAny(string(b), ", \t\n") {
+			return nil, fmt.Errorf("logcat: invalid buffer %q", b)
+		}
+		args = append(args, "-b", string(b))
+	}
+	if o.PID > 0 {
+		args = append(args, "--pid="+strconv.Itoa(o.PID))
+	}
+	if len(o.UIDs) != 0 {
+		uids := make([]string, len(o.UIDs))
+		for i, uid := range o.UIDs {
+			uids[i] = strconv.Itoa(uid)
+		}
+		args = append(args, "--uid="+strings.Join(uids, ","))
+	}
+	if o.Regex != "" {
+		args = append(args, "-e", o.Regex)
+	}
+	switch {
+	case !o.Since.IsZero() && o.Tail > 0:
+		return nil, errors.New("logcat: Since and Tail are mutually exclusive")
+	case !o.Since.IsZero():
+		// Seconds since the epoch avoid any doubt about the device's time zone.
+		ms := o.Since.UnixMilli()
+		args = append(args, "-T", fmt.Sprintf("%d.%03d", ms/1000, ms%1000))
+	case o.Tail > 0:
+		args = append(args, "-T", strconv.Itoa(o.Tail))
+	}
+	if o.Dump {
+		args = append(args, "-d")
+	}
+	// Filter specs are positional and must come after the options.
+	for _, f := range o.Filters {
+		if f.Tag == "" || strings.ContainsAny(f.Tag, ": \t\n") {
+			return nil, fmt.Errorf("logcat: invalid filter tag %q", f.Tag)
+		}
+		if f.Priority < Verbose || f.Priority > Silent {
+			return nil, fmt.Errorf("logcat: invalid priority %d for tag %q", f.Priority, f.Tag)
+		}
+		args = append(args, f.String())
+	}
+	return args, nil
+}
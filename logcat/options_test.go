@@ -0,0 +1,41 @@
+package logcat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Args(t *testing.T) {
+	opts := Options{
+		Buffers: []Buffer{BufferCrash, BufferSystem},
+		Filters: []Filter{{"ActivityManager", Info}, {"*", Silent}},
+		PID:     1234,
+		UIDs:    []int{10123, 1000},
+		Regex:   "Start proc .*",
+		Since:   time.UnixMilli(1760445296789),
+		Dump:    true,
+	}
+	args, err := opts.Args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(args, " "), "-b crash -b system --pid=1234 --uid=10123,1000 -e Start proc .* -T 1760445296.789 -d ActivityManager:I *:S"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	for _, f := range []Filter{{"", Info}, {"a:b", Info}, {"Tag", 0}} {
+		if _, err = (Options{Filters: []Filter{f}}).Args(); err == nil {
+			t.Errorf("%+v: expected an error", f)
+		}
+	}
+	if args, _ = (Options{Tail: 100}).Args(); strings.Join(args, " ") != "-T 100" {
+		t.Fatalf("unexpected args %q", args)
+	}
+	if _, err = (Options{Tail: 1, Since: time.Now()}).Args(); err == nil {
+		t.Fatal("expected an error for Since with Tail")
+	}
+	if _, err = (Options{Buffers: []Buffer{"main,crash"}}).Args(); err == nil {
+		t.Fatal("expected an error for a combined buffer")
+	}
+}
@@ -0,0 +1,160 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrProcessNotRunning is returned when the process asked about, or no process of the
+// package, is not running.
+var ErrProcessNotRunning = errors.New("process not running")
+
+// MemInfo is the system memory usage from /proc/meminfo. Sizes are in bytes.
+type MemInfo struct {
+	Total     int64
+	Free      int64
+	Available int64
+	Buffers   int64
+	Cached    int64
+	SwapTotal int64
+	SwapFree  int64
+	// Fields has every /proc/meminfo line, such as "Shmem" or "Active(anon)", in bytes
+	// when the line has a unit and as a plain count otherwise.
+	Fields map[string]int64
+}
+
+// MemInfo returns the system memory usage.
+func (d Device) MemInfo() (MemInfo, error) {
+	output, err := d.RunShellCommand("cat /proc/meminfo")
+	if err != nil {
+		return MemInfo{}, err
+	}
+	return parseMemInfo(output)
+}
+
+// parseMemInfo parses /proc/meminfo lines such as "MemTotal:        3809036 kB".
+func parseMemInfo(output string) (MemInfo, error) {
+	fields := map[string]int64{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		number, unit, _ := strings.Cut(strings.TrimSpace(value), " ")
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			continue
+		}
+		if unit == "kB" {
+			n *= 1024
+		}
+		fields[key] = n
+	}
+	if _, ok := fields["MemTotal"]; !ok {
+		return MemInfo{}, errors.New("adb meminfo: MemTotal not found")
+	}
+	return MemInfo{
+		Total:     fields["MemTotal"],
+		Free:      fields["MemFree"],
+		Available: fields["MemAvailable"],
+		Buffers:   fields["Buffers"],
+		Cached:    fields["Cached"],
+		SwapTotal: fields["SwapTotal"],
+		SwapFree:  fields["SwapFree"],
+		Fields:    fields,
+	}, nil
+}
+
+// AppMemInfo is the memory usage of an app process, from the App Summary of
+// `dumpsys meminfo`. Sizes are proportional set sizes in bytes, except TotalRSS.
+type AppMemInfo struct {
+	PID          int
+	Process      string
+	TotalPSS     int64
+	JavaHeap     int64
+	NativeHeap   int64
+	Code         int64
+	Stack        int64
+	Graphics     int64
+	PrivateOther int64
+	System       int64
+	// TotalRSS is the resident set size, reported since Android 10; zero before.
+	TotalRSS int64
+}
+
+// AppMemInfo returns the memory usage of the running process of pkg. If the package runs
+// several processes, the first one reported is returned. It returns ErrProcessNotRunning if
+// none is running.
+func (d Device) AppMemInfo(pkg string) (AppMemInfo, error) {
+	output, err := d.Dumpsys("meminfo", pkg)
+	if err != nil {
+		return AppMemInfo{}, err
+	}
+	return parseAppMemInfo(output)
+}
+
+var (
+	meminfoHeaderRe = regexp.MustCompile(`\*\* MEMINFO in pid (\d+) \[(.*)\] \*\*`)
+	// meminfoValueRe matches the "Label: value" pairs of App Summary lines, several of which
+	// share the TOTAL line.
+	meminfoValueRe = regexp.MustCompile(`([A-Za-z][A-Za-z ()]*?):\s+(\d+)`)
+)
+
+// parseAppMemInfo parses the first process of dumpsys meminfo output:
+//
+//	** MEMINFO in pid 1234 [com.example] **
+//	...
+//	 App Summary
+//	                       Pss(KB)                        Rss(KB)
+//	                        ------                         ------
+//	           Java Heap:     5316                          12000
+//	         Native Heap:    10408                          12000
+//	...
+//	           TOTAL PSS:    34000            TOTAL RSS:    50000       TOTAL SWAP PSS:        0
+//
+// Releases before Android 10 have only the Pss column and a "TOTAL:" line.
+func parseAppMemInfo(output string) (info AppMemInfo, err error) {
+	header := meminfoHeaderRe.FindStringSubmatchIndex(output)
+	if header == nil {
+		if line, _, _ := strings.Cut(strings.TrimSpace(output), "\n"); strings.HasPrefix(line, "No process found") {
+			return AppMemInfo{}, ErrProcessNotRunning
+		}
+		return AppMemInfo{}, errors.New("adb dumpsys meminfo: no process memory information")
+	}
+	info.PID, _ = strconv.Atoi(output[header[2]:header[3]])
+	info.Process = output[header[4]:header[5]]
+
+	rest := output[header[1]:]
+	if next := meminfoHeaderRe.FindStringIndex(rest); next != nil {
+		rest = rest[:next[0]]
+	}
+	_, summary, ok := strings.Cut(rest, "App Summary")
+	if !ok {
+		return AppMemInfo{}, fmt.Errorf("adb dumpsys meminfo: no app summary for pid %d", info.PID)
+	}
+
+	fields := map[string]*int64{
+		"Java Heap":     &info.JavaHeap,
+		"Native Heap":   &info.NativeHeap,
+		"Code":          &info.Code,
+		"Stack":         &info.Stack,
+		"Graphics":      &info.Graphics,
+		"Private Other": &info.PrivateOther,
+		"System":        &info.System,
+		"TOTAL PSS":     &info.TotalPSS,
+		"TOTAL":         &info.TotalPSS,
+		"TOTAL RSS":     &info.TotalRSS,
+	}
+	for _, line := range strings.Split(summary, "\n") {
+		for _, m := range meminfoValueRe.FindAllStringSubmatch(line, -1) {
+			if field, ok := fields[strings.TrimSpace(m[1])]; ok {
+				kb, _ := strconv.ParseInt(m[2], 10, 64)
+				*field = kb * 1024
+			}
+		}
+	}
+	return info, nil
+}
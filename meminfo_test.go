@@ -0,0 +1,83 @@
+package gadb
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_parseMemInfo(t *testing.T) {
+	info, err := parseMemInfo("MemTotal:        3809036 kB\nMemFree:          123456 kB\nMemAvailable:    2000000 kB\nCached:           500000 kB\nSwapTotal:             0 kB\nHugePages_Total:       0\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Total != 3809036*1024 || info.Free != 123456*1024 || info.Available != 2000000*1024 || info.Cached != 500000*1024 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if n, ok := info.Fields["HugePages_Total"]; !ok || n != 0 {
+		t.Fatalf("unexpected fields %v", info.Fields)
+	}
+	if _, err = parseMemInfo("cat: /proc/meminfo: Permission denied\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func Test_parseAppMemInfo(t *testing.T) {
+	output := `Applications Memory Usage (in Kilobytes):
+Uptime: 123456 Realtime: 123456
+
+** MEMINFO in pid 4321 [com.example] **
+                   Pss  Private  Private  SwapPss      Rss     Heap     Heap     Heap
+                 Total    Dirty    Clean    Dirty    Total     Size    Alloc     Free
+                ------   ------   ------   ------   ------   ------   ------   ------
+  Native Heap    10468    10408        0        0    12000    20480    14875     5604
+  Dalvik Heap     3008     2928        0        0     4000     6144     3072     3072
+        TOTAL    34000    30000     1000        0    50000    26624    17947     8676
+
+ App Summary
+                       Pss(KB)                        Rss(KB)
+                        ------                         ------
+           Java Heap:     5316                          12000
+         Native Heap:    10408                          12000
+                Code:     8000                          20000
+               Stack:       56                             56
+            Graphics:     4000                           4000
+       Private Other:     1220
+              System:     5000
+             Unknown:                                     100
+
+           TOTAL PSS:    34000            TOTAL RSS:    50000       TOTAL SWAP PSS:        0
+
+** MEMINFO in pid 4322 [com.example:remote] **
+ App Summary
+           TOTAL PSS:    99999
+`
+	info, err := parseAppMemInfo(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AppMemInfo{
+		PID:          4321,
+		Process:      "com.example",
+		TotalPSS:     34000 * 1024,
+		JavaHeap:     5316 * 1024,
+		NativeHeap:   10408 * 1024,
+		Code:         8000 * 1024,
+		Stack:        56 * 1024,
+		Graphics:     4000 * 1024,
+		PrivateOther: 1220 * 1024,
+		System:       5000 * 1024,
+		TotalRSS:     50000 * 1024,
+	}
+	if info != want {
+		t.Fatalf("got %+v, want %+v", info, want)
+	}
+
+	info, err = parseAppMemInfo("** MEMINFO in pid 1 [old] **\n App Summary\n           TOTAL:    34000      TOTAL SWAP PSS:        0\n")
+	if err != nil || info.TotalPSS != 34000*1024 || info.TotalRSS != 0 {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+
+	if _, err = parseAppMemInfo("No process found for: com.example\n"); !errors.Is(err, ErrProcessNotRunning) {
+		t.Fatalf("expected ErrProcessNotRunning, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonkeyOptions configures Monkey.
+type MonkeyOptions struct {
+	// Packages restricts the monkey to these packages (-p); all packages if empty.
+	Packages []string
+	// Categories restricts launched activities to these intent categories (-c).
+	Categories []string
+	// Events is the number of events to inject; it must be positive.
+	Events int
+	// Seed makes the pseudo-random event sequence reproducible (-s); 0 lets monkey pick one.
+	Seed int64
+	// Throttle is the delay between events (--throttle).
+	Throttle time.Duration
+	// IgnoreCrashes and IgnoreTimeouts keep the monkey running after a crash or an ANR.
+	IgnoreCrashes  bool
+	IgnoreTimeouts bool
+	// OnEvent, if set, is called with every injected event as monkey reports it,
+	// e.g. "Sending Touch (ACTION_DOWN): 0:(540.0,1200.0)".
+	OnEvent func(event string)
+}
+
+func (opts MonkeyOptions) command() (string, error) {
+	if opts.Events <= 0 {
+		return "", fmt.Errorf("adb monkey: event count must be positive, got %d", opts.Events)
+	}
+	args := []string{"monkey"}
+	for _, pkg := range opts.Packages {
+		args = append(args, "-p", shellQuote(pkg))
+	}
+	for _, category := range opts.Categories {
+		args = append(args, "-c", shellQuote(category))
+	}
+	if opts.Seed != 0 {
+		args = append(args, "-s", strconv.FormatInt(opts.Seed, 10))
+	}
+	if opts.Throttle > 0 {
+		args = append(args, "--throttle", strconv.FormatInt(opts.Throttle.Milliseconds(), 10))
+	}
+	if opts.IgnoreCrashes {
+		args = append(args, "--ignore-crashes")
+	}
+	if opts.IgnoreTimeouts {
+		args = append(args, "--ignore-timeouts")
+	}
+	// Crash and ANR reports are written to stderr, which the exec service doesn't forward.
+	args = append(args, "-v", strconv.Itoa(opts.Events), "2>&1")
+	return strings.Join(args, " "), nil
+}
+
+// MonkeyCrash is an application crash reported by monkey.
+type MonkeyCrash struct {
+	Package  string
+	PID      int
+	ShortMsg string
+	LongMsg  string
+	// Stack is the Java stack trace of the crash.
+	Stack string
+}
+
+// MonkeyANR is an "application not responding" reported by monkey.
+type MonkeyANR struct {
+	Package string
+	PID     int
+	// Activity is the component that stopped responding, if reported.
+	Activity string
+	Reason   string
+	// Details holds the rest of the report, such as the load and CPU usage.
+	Details string
+}
+
+// MonkeyResult summarises a monkey run.
+type MonkeyResult struct {
+	Seed           int64
+	EventsInjected int
+	Crashes        []MonkeyCrash
+	ANRs           []MonkeyANR
+	// Aborted is set if monkey stopped early; AbortReason then says why.
+	Aborted     bool
+	AbortReason string
+}
+
+// Monkey runs the monkey stress tester with opts and returns the crashes and ANRs it found.
+// Those are reported in the result, not as an error, even when they abort the run.
+func (d Device) Monkey(opts MonkeyOptions) (MonkeyResult, error) {
+	cmd, err := opts.command()
+	if err != nil {
+		return MonkeyResult{}, err
+	}
+	conn, err := d.openExec(context.Background(), cmd)
+	if err != nil {
+		return MonkeyResult{}, err
+	}
+	defer func() { _ = conn.Close() }()
+	return parseMonkey(conn, opts.OnEvent)
+}
+
+// parseMonkey reads the verbose output of monkey. Event lines start with ":", comments with
+// "//" and errors with "**"; crash reports are "//" comments after "// CRASH:", ANR reports
+// follow "// NOT RESPONDING:" up to the "// meminfo status" line.
+func parseMonkey(r io.Reader, onEvent func(string)) (result MonkeyResult, err error) {
+	var crash *MonkeyCrash
+	var anr *MonkeyANR
+	var details []string
+
+	br := bufio.NewReader(r)
+	for {
+		line, readErr := br.ReadString('\n')
+		if line == "" && readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				return result, readErr
+			}
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if anr != nil {
+			if !strings.HasPrefix(line, "// meminfo status") && !strings.HasPrefix(line, ":") && !strings.HasPrefix(line, "**") {
+				switch {
+				case strings.HasPrefix(line, "ANR in "):
+					if _, activity, ok := strings.Cut(line, "("); ok {
+						anr.Activity = strings.TrimSuffix(activity, ")")
+					}
+				case strings.HasPrefix(line, "Reason: "):
+					anr.Reason = strings.TrimPrefix(line, "Reason: ")
+				case strings.HasPrefix(line, "PID: "), strings.HasPrefix(line, "// "):
+				default:
+					details = append(details, line)
+				}
+				continue
+			}
+			anr.Details = strings.TrimSpace(strings.Join(details, "\n"))
+			result.ANRs = append(result.ANRs, *anr)
+			anr, details = nil, nil
+		}
+
+		if crash != nil {
+			if comment, ok := strings.CutPrefix(line, "// "); ok && comment != "" {
+				switch {
+				case strings.HasPrefix(comment, "Short Msg: "):
+					crash.ShortMsg = strings.TrimPrefix(comment, "Short Msg: ")
+				case strings.HasPrefix(comment, "Long Msg: "):
+					crash.LongMsg = strings.TrimPrefix(comment, "Long Msg: ")
+				case strings.HasPrefix(comment, "Build "):
+				default:
+					details = append(details, comment)
+				}
+				continue
+			}
+			crash.Stack = strings.Join(details, "\n")
+			result.Crashes = append(result.Crashes, *crash)
+			crash, details = nil, nil
+		}
+
+		switch {
+		case strings.HasPrefix(line, "// CRASH: "):
+			crash = &MonkeyCrash{}
+			crash.Package, crash.PID = parseMonkeyProcess(strings.TrimPrefix(line, "// CRASH: "))
+		case strings.HasPrefix(line, "// NOT RESPONDING: "):
+			anr = &MonkeyANR{}
+			anr.Package, anr.PID = parseMonkeyProcess(strings.TrimPrefix(line, "// NOT RESPONDING: "))
+		case strings.HasPrefix(line, ":Monkey: seed="):
+			seed, _, _ := strings.Cut(strings.TrimPrefix(line, ":Monkey: seed="), " ")
+			result.Seed, _ = strconv.ParseInt(seed, 10, 64)
+		case strings.HasPrefix(line, ":Sending "), strings.HasPrefix(line, ":Switch: "):
+			if onEvent != nil {
+				onEvent(strings.TrimPrefix(line, ":"))
+			}
+		case strings.HasPrefix(line, "Events injected: "):
+			result.EventsInjected, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Events injected: ")))
+		case strings.HasPrefix(line, "** "):
+			result.Aborted = true
+			if result.AbortReason == "" {
+				result.AbortReason = strings.TrimPrefix(line, "** ")
+			}
+		}
+	}
+
+	// A report may be cut short by the end of the output.
+	if crash != nil {
+		crash.Stack = strings.Join(details, "\n")
+		result.Crashes = append(result.Crashes, *crash)
+	}
+	if anr != nil {
+		anr.Details = strings.TrimSpace(strings.Join(details, "\n"))
+		result.ANRs = append(result.ANRs, *anr)
+	}
+	return result, nil
+}
+
+// parseMonkeyProcess parses "com.example (pid 1234)".
+func parseMonkeyProcess(s string) (pkg string, pid int) {
+	pkg, rest, _ := strings.Cut(s, " (pid ")
+	pid, _ = strconv.Atoi(strings.TrimSuffix(rest, ")"))
+	return pkg, pid
+}
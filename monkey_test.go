@@ -0,0 +1,87 @@
+package gadb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonkeyOptions_command(t *testing.T) {
+	opts := MonkeyOptions{Packages: []string{"com.example"}, Events: 500, Seed: 42, Throttle: 100 * time.Millisecond, IgnoreTimeouts: true}
+	cmd, err := opts.command()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "monkey -p 'com.example' -s 42 --throttle 100 --ignore-timeouts -v 500 2>&1"; cmd != want {
+		t.Fatalf("unexpected command %q", cmd)
+	}
+	if _, err = (MonkeyOptions{}).command(); err == nil {
+		t.Fatal("expected an error without an event count")
+	}
+}
+
+const monkeyOutput = `:Monkey: seed=42 count=500
+:AllowPackage: com.example
+:IncludeCategory: android.intent.category.LAUNCHER
+// Event percentages:
+//   0: 15.0%
+:Switch: #Intent;action=android.intent.action.MAIN;category=android.intent.category.LAUNCHER;component=com.example/.Main;end
+    // Allowing start of Intent { act=android.intent.action.MAIN cmp=com.example/.Main } in package com.example
+:Sending Touch (ACTION_DOWN): 0:(540.0,1200.0)
+:Sending Touch (ACTION_UP): 0:(541.0,1203.0)
+// NOT RESPONDING: com.example (pid 4321)
+ANR in com.example (com.example/.Main)
+PID: 4321
+Reason: Input dispatching timed out
+Load: 1.2 / 0.9 / 0.7
+CPU usage from 0ms to 5000ms later:
+// meminfo status was 0
+// CRASH: com.example (pid 4321)
+// Short Msg: java.lang.NullPointerException
+// Long Msg: java.lang.NullPointerException: Attempt to invoke virtual method on a null object reference
+// Build Label: google/sdk/generic:14/UE1A/1:userdebug/dev-keys
+// Build Changelist: 1
+// Build Time: 1700000000000
+// java.lang.NullPointerException: Attempt to invoke virtual method on a null object reference
+// 	at com.example.Main.onClick(Main.java:42)
+// 
+** Monkey aborted due to error.
+Events injected: 37
+:Sending rotation degree=0, persist=false
+:Dropped: keys=0 pointers=0 trackballs=0 flips=0 rotations=0
+## Network stats: elapsed time=1234ms (0ms mobile, 0ms wifi, 1234ms not connected)
+** System appears to have crashed at event 37 of 500 using seed 42
+`
+
+func Test_parseMonkey(t *testing.T) {
+	var events []string
+	result, err := parseMonkey(strings.NewReader(monkeyOutput), func(e string) { events = append(events, e) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Seed != 42 || result.EventsInjected != 37 || !result.Aborted || result.AbortReason != "Monkey aborted due to error." {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(events) != 4 || events[1] != "Sending Touch (ACTION_DOWN): 0:(540.0,1200.0)" {
+		t.Fatalf("unexpected events: %q", events)
+	}
+
+	if len(result.ANRs) != 1 {
+		t.Fatalf("expected 1 ANR, got %+v", result.ANRs)
+	}
+	anr := result.ANRs[0]
+	if anr.Package != "com.example" || anr.PID != 4321 || anr.Activity != "com.example/.Main" ||
+		anr.Reason != "Input dispatching timed out" || !strings.HasPrefix(anr.Details, "Load: 1.2") {
+		t.Fatalf("unexpected ANR: %+v", anr)
+	}
+
+	if len(result.Crashes) != 1 {
+		t.Fatalf("expected 1 crash, got %+v", result.Crashes)
+	}
+	crash := result.Crashes[0]
+	if crash.Package != "com.example" || crash.PID != 4321 || crash.ShortMsg != "java.lang.NullPointerException" ||
+		!strings.HasPrefix(crash.LongMsg, "java.lang.NullPointerException: Attempt") ||
+		crash.Stack != "java.lang.NullPointerException: Attempt to invoke virtual method on a null object reference\n\tat com.example.Main.onClick(Main.java:42)" {
+		t.Fatalf("unexpected crash: %+v", crash)
+	}
+}
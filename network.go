@@ -0,0 +1,196 @@
+package gadb
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NetworkInterface is a network interface of the device, as listed by `ip addr`.
+type NetworkInterface struct {
+	Index int
+	Name  string
+	// Flags are the interface flags, such as "UP" and "LOWER_UP".
+	Flags []string
+	MTU   int
+	// State is the operational state, such as "UP", "DOWN" or "UNKNOWN".
+	State string
+	// MAC is the link-layer address, empty for interfaces without one.
+	MAC       string
+	Addresses []netip.Prefix
+}
+
+// Up reports whether the interface is administratively up.
+func (iface NetworkInterface) Up() bool {
+	return slices.Contains(iface.Flags, "UP")
+}
+
+// NetworkInterfaces lists the device's network interfaces with their addresses.
+func (d Device) NetworkInterfaces() ([]NetworkInterface, error) {
+	output, err := d.RunShellCommand("ip addr")
+	if err != nil {
+		return nil, err
+	}
+	interfaces := parseIPAddr(output)
+	if len(interfaces) == 0 {
+		return nil, errors.New("adb ip addr: unexpected output " + strings.TrimSpace(output))
+	}
+	return interfaces, nil
+}
+
+// parseIPAddr parses `ip addr` output:
+//
+//	3: wlan0@if4: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP group default qlen 3000
+//	    link/ether 02:00:00:44:55:66 brd ff:ff:ff:ff:ff:ff
+//	    inet 192.168.1.23/24 brd 192.168.1.255 scope global wlan0
+//	       valid_lft forever preferred_lft forever
+//	    inet6 fe80::ff:fe44:5566/64 scope link
+func parseIPAddr(output string) (interfaces []NetworkInterface) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if index, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":")); err == nil && line[0] != ' ' {
+			name, _, _ := strings.Cut(strings.TrimSuffix(fields[1], ":"), "@")
+			iface := NetworkInterface{Index: index, Name: name}
+			for i := 2; i < len(fields); i++ {
+				switch {
+				case strings.HasPrefix(fields[i], "<"):
+					if flags := strings.Trim(fields[i], "<>"); flags != "" {
+						iface.Flags = strings.Split(flags, ",")
+					}
+				case fields[i] == "mtu" && i+1 < len(fields):
+					iface.MTU, _ = strconv.Atoi(fields[i+1])
+				case fields[i] == "state" && i+1 < len(fields):
+					iface.State = fields[i+1]
+				}
+			}
+			interfaces = append(interfaces, iface)
+			continue
+		}
+		if len(interfaces) == 0 {
+			continue
+		}
+		iface := &interfaces[len(interfaces)-1]
+		switch {
+		case fields[0] == "link/ether":
+			iface.MAC = fields[1]
+		case fields[0] == "inet" || fields[0] == "inet6":
+			if prefix, err := netip.ParsePrefix(fields[1]); err == nil {
+				iface.Addresses = append(iface.Addresses, prefix)
+			}
+		}
+	}
+	return interfaces
+}
+
+// TCPState is the state of a TCP connection, numbered as in the kernel's tcp_states.h.
+type TCPState int
+
+const (
+	TCPEstablished TCPState = 1 + iota
+	TCPSynSent
+	TCPSynRecv
+	TCPFinWait1
+	TCPFinWait2
+	TCPTimeWait
+	TCPClose
+	TCPCloseWait
+	TCPLastAck
+	TCPListen
+	TCPClosing
+)
+
+var tcpStateNames = []string{"ESTABLISHED", "SYN_SENT", "SYN_RECV", "FIN_WAIT1", "FIN_WAIT2", "TIME_WAIT", "CLOSE", "CLOSE_WAIT", "LAST_ACK", "LISTEN", "CLOSING"}
+
+// String returns the name netstat uses for the state, such as "LISTEN".
+func (s TCPState) String() string {
+	if s < TCPEstablished || s > TCPClosing {
+		return strconv.Itoa(int(s))
+	}
+	return tcpStateNames[s-TCPEstablished]
+}
+
+// Connection is a TCP socket of the device.
+type Connection struct {
+	// Protocol is "tcp" or "tcp6".
+	Protocol string
+	Local    netip.AddrPort
+	Remote   netip.AddrPort
+	State    TCPState
+	// UID is the uid owning the socket, for telling which app opened it.
+	UID   int
+	Inode uint64
+}
+
+// Connections lists the device's TCP sockets, including listening ones, from /proc/net/tcp
+// and /proc/net/tcp6, which every release has, unlike ss.
+func (d Device) Connections() ([]Connection, error) {
+	output, err := d.RunShellCommand("cat /proc/net/tcp /proc/net/tcp6")
+	if err != nil {
+		return nil, err
+	}
+	connections, err := parseProcNetTCP(output)
+	if err != nil {
+		return nil, err
+	}
+	if connections == nil && !strings.Contains(output, "local_address") {
+		return nil, errors.New("adb connections: " + strings.TrimSpace(output))
+	}
+	return connections, nil
+}
+
+// parseProcNetTCP parses the lines of /proc/net/tcp and tcp6:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:13AD 00000000:0000 0A 00000000:00000000 00:00000000 00000000  2000        0 12345 1 ...
+//
+// Addresses are in hex, as 32-bit words in host (little-endian) order, and ports in hex.
+func parseProcNetTCP(output string) (connections []Connection, err error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		var c Connection
+		if c.Local, err = parseProcNetAddr(fields[1]); err != nil {
+			return nil, err
+		}
+		if c.Remote, err = parseProcNetAddr(fields[2]); err != nil {
+			return nil, err
+		}
+		c.Protocol = "tcp"
+		if c.Local.Addr().Is6() {
+			c.Protocol = "tcp6"
+		}
+		state, _ := strconv.ParseInt(fields[3], 16, 32)
+		c.State = TCPState(state)
+		c.UID, _ = strconv.Atoi(fields[7])
+		c.Inode, _ = strconv.ParseUint(fields[9], 10, 64)
+		connections = append(connections, c)
+	}
+	return connections, nil
+}
+
+func parseProcNetAddr(s string) (netip.AddrPort, error) {
+	addrHex, portHex, _ := strings.Cut(s, ":")
+	raw, err := hex.DecodeString(addrHex)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.AddrPort{}, errors.New("adb connections: invalid address " + s)
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, errors.New("adb connections: invalid port " + s)
+	}
+	// Each 32-bit word is printed in host order; swap it into network order.
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(raw[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	addr, _ := netip.AddrFromSlice(raw)
+	return netip.AddrPortFrom(addr, uint16(port)), nil
+}
@@ -0,0 +1,62 @@
+package gadb
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func Test_parseIPAddr(t *testing.T) {
+	output := `1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN group default qlen 1000
+    link/loopback 00:00:00:00:00:00 brd 00:00:00:00:00:00
+    inet 127.0.0.1/8 scope host lo
+       valid_lft forever preferred_lft forever
+    inet6 ::1/128 scope host
+       valid_lft forever preferred_lft forever
+3: wlan0@if4: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP group default qlen 3000
+    link/ether 02:00:00:44:55:66 brd ff:ff:ff:ff:ff:ff
+    inet 192.168.1.23/24 brd 192.168.1.255 scope global wlan0
+       valid_lft forever preferred_lft forever
+    inet6 fe80::ff:fe44:5566/64 scope link
+       valid_lft forever preferred_lft forever
+4: dummy0: <BROADCAST,NOARP> mtu 1500 qdisc noop state DOWN group default qlen 1000
+`
+	want := []NetworkInterface{
+		{Index: 1, Name: "lo", Flags: []string{"LOOPBACK", "UP", "LOWER_UP"}, MTU: 65536, State: "UNKNOWN",
+			Addresses: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/8"), netip.MustParsePrefix("::1/128")}},
+		{Index: 3, Name: "wlan0", Flags: []string{"BROADCAST", "MULTICAST", "UP", "LOWER_UP"}, MTU: 1500, State: "UP", MAC: "02:00:00:44:55:66",
+			Addresses: []netip.Prefix{netip.MustParsePrefix("192.168.1.23/24"), netip.MustParsePrefix("fe80::ff:fe44:5566/64")}},
+		{Index: 4, Name: "dummy0", Flags: []string{"BROADCAST", "NOARP"}, MTU: 1500, State: "DOWN"},
+	}
+	interfaces := parseIPAddr(output)
+	if !reflect.DeepEqual(interfaces, want) {
+		t.Fatalf("got %+v, want %+v", interfaces, want)
+	}
+	if !interfaces[1].Up() || interfaces[2].Up() {
+		t.Fatal("unexpected Up")
+	}
+}
+
+func Test_parseProcNetTCP(t *testing.T) {
+	output := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:13AD 00000000:0000 0A 00000000:00000000 00:00000000 00000000  2000        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 1701A8C0:C350 8EFB1AD8:01BB 01 00000000:00000000 02:000A7D1A 00000000 10123        0 67890 2 0000000000000000 20 4 30 10 -1
+  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 555 1 0000000000000000 100 0 0 10 0
+`
+	connections, err := parseProcNetTCP(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Connection{
+		{Protocol: "tcp", Local: netip.MustParseAddrPort("127.0.0.1:5037"), Remote: netip.MustParseAddrPort("0.0.0.0:0"), State: TCPListen, UID: 2000, Inode: 12345},
+		{Protocol: "tcp", Local: netip.MustParseAddrPort("192.168.1.23:50000"), Remote: netip.MustParseAddrPort("216.26.251.142:443"), State: TCPEstablished, UID: 10123, Inode: 67890},
+		{Protocol: "tcp6", Local: netip.MustParseAddrPort("[::1]:8080"), Remote: netip.MustParseAddrPort("[::]:0"), State: TCPListen, UID: 1000, Inode: 555},
+	}
+	if !reflect.DeepEqual(connections, want) {
+		t.Fatalf("got %+v, want %+v", connections, want)
+	}
+	if TCPListen.String() != "LISTEN" || TCPState(0).String() != "0" {
+		t.Fatal("unexpected state names")
+	}
+}
@@ -0,0 +1,68 @@
+package gadb
+
+// WithNormalizedOutput returns a copy of the device whose shell helpers (RunShellCommand,
+// RunShellCommandWithBytes, RunShellBounded) pass their output through NormalizeOutput.
+// Leave it off when reading binary output such as screencap.
+func (d Device) WithNormalizedOutput() Device {
+	d.normalizeOutput = true
+	return d
+}
+
+// NormalizeOutput strips ANSI escape sequences (colors, cursor movement, window titles) and
+// collapses the CRLF line endings produced by the legacy pty shell into plain LF.
+func NormalizeOutput(raw []byte) []byte {
+	out := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == 0x1b && i+1 < len(raw):
+			i = skipEscape(raw, i+1)
+		case c == 0x1b:
+			// Dangling ESC at the end of the output.
+		case c == '\r':
+			j := i
+			for j < len(raw) && raw[j] == '\r' {
+				j++
+			}
+			if j < len(raw) && raw[j] == '\n' {
+				i = j - 1
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// skipEscape returns the index of the last byte of the escape sequence whose introducer follows ESC at i.
+func skipEscape(raw []byte, i int) int {
+	switch raw[i] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte in 0x40-0x7e.
+		for i++; i < len(raw); i++ {
+			if raw[i] >= 0x40 && raw[i] <= 0x7e {
+				return i
+			}
+		}
+		return len(raw) - 1
+	case ']', 'P', '_', '^':
+		// OSC/DCS/APC/PM: terminated by BEL or ST (ESC \).
+		for i++; i < len(raw); i++ {
+			if raw[i] == 0x07 {
+				return i
+			}
+			if raw[i] == 0x1b && i+1 < len(raw) && raw[i+1] == '\\' {
+				return i + 1
+			}
+		}
+		return len(raw) - 1
+	default:
+		// Two-byte sequence such as ESC ( B or ESC =.
+		if (raw[i] == '(' || raw[i] == ')') && i+1 < len(raw) {
+			return i + 1
+		}
+		return i
+	}
+}
@@ -0,0 +1,22 @@
+package gadb
+
+import (
+	"testing"
+)
+
+func TestNormalizeOutput(t *testing.T) {
+	cases := map[string]string{
+		"\x1b[1;31mred\x1b[0m\r\n":          "red\n",
+		"a\r\r\nb\r\n":                      "a\nb\n",
+		"\x1b]0;title\x07prompt$ ":          "prompt$ ",
+		"\x1b]0;title\x1b\\x":               "x",
+		"progress 10%\rprogress 20%\n":      "progress 10%\rprogress 20%\n",
+		"\x1b(Bplain\x1b=":                  "plain",
+		"\x1b[38;5;208morange\x1b[K\x1b[m ": "orange ",
+	}
+	for in, want := range cases {
+		if got := string(NormalizeOutput([]byte(in))); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
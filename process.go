@@ -0,0 +1,203 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Process is one process listed by Device.Processes.
+type Process struct {
+	PID  int
+	PPID int
+	// UID is the process's uid, or -1 if it could not be determined.
+	UID  int
+	Name string
+	// State is the one-letter process state, such as "R" (running), "S" (sleeping) or "Z"
+	// (zombie).
+	State string
+	// RSS is the resident set size in bytes.
+	RSS int64
+}
+
+// sdkToyboxPS is the first API level (Android 8.0) whose ps is toybox's; earlier releases
+// have toolbox's, which lists everything but accepts no output format.
+const sdkToyboxPS = 26
+
+// Processes lists the processes running on the device.
+func (d Device) Processes() ([]Process, error) {
+	sdk, err := d.SdkVersion()
+	if err != nil {
+		return nil, err
+	}
+	cmd := "ps"
+	if sdk >= sdkToyboxPS {
+		cmd = "ps -A -o PID,PPID,UID,S,RSS,NAME"
+	}
+	output, err := d.RunShellCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parsePS(output)
+}
+
+// parsePS parses ps output by its header, which is either toybox's for the columns Processes
+// asks for,
+//
+//	PID  PPID   UID S   RSS NAME
+//	  1     0     0 S  9876 init
+//
+// or toolbox's,
+//
+//	USER      PID   PPID  VSIZE  RSS   WCHAN              PC  NAME
+//	u0_a57    2215  1757  1558640 61272 SyS_epoll_ 0000000000 S com.android.systemui
+//
+// whose user names are mapped back to uids, and whose rows have the state in a column
+// without a header before the name. The name, in the last column, may contain spaces.
+func parsePS(output string) ([]Process, error) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	header := strings.Fields(lines[0])
+	if len(header) == 0 || !strings.Contains(lines[0], "PID") {
+		return nil, errors.New("adb ps: unexpected output " + strings.TrimSpace(lines[0]))
+	}
+	if !slices.Contains(header, "S") && header[len(header)-1] == "NAME" {
+		header = slices.Insert(header, len(header)-1, "S")
+	}
+
+	var processes []Process
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < len(header) {
+			continue
+		}
+		fields = append(fields[:len(header)-1], strings.Join(fields[len(header)-1:], " "))
+		p := Process{UID: -1}
+		for i, column := range header {
+			value := fields[i]
+			switch column {
+			case "PID":
+				p.PID, _ = strconv.Atoi(value)
+			case "PPID":
+				p.PPID, _ = strconv.Atoi(value)
+			case "UID":
+				if uid, err := strconv.Atoi(value); err == nil {
+					p.UID = uid
+				}
+			case "USER":
+				p.UID = androidUID(value)
+			case "S":
+				p.State = value
+			case "RSS":
+				kb, _ := strconv.ParseInt(value, 10, 64)
+				p.RSS = kb * 1024
+			case "NAME":
+				p.Name = value
+			}
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+// androidUIDs are the uids of the system user names, from android_filesystem_config.h.
+var androidUIDs = map[string]int{
+	"root": 0, "system": 1000, "radio": 1001, "bluetooth": 1002, "graphics": 1003, "input": 1004,
+	"audio": 1005, "camera": 1006, "log": 1007, "compass": 1008, "mount": 1009, "wifi": 1010,
+	"adb": 1011, "install": 1012, "media": 1013, "dhcp": 1014, "sdcard_rw": 1015, "vpn": 1016,
+	"keystore": 1017, "usb": 1018, "drm": 1019, "mdnsr": 1020, "gps": 1021, "media_rw": 1023,
+	"mtp": 1024, "nfc": 1027, "shell": 2000, "cache": 2001, "diag": 2002, "nobody": 9999,
+}
+
+// androidUID maps a user name as printed by ps to its uid: a system name such as "system",
+// or an app or isolated uid of a user, such as "u0_a123" (10123) or "u10_i5" (1099005).
+// It returns -1 for names it doesn't know.
+func androidUID(name string) int {
+	if uid, ok := androidUIDs[name]; ok {
+		return uid
+	}
+	user, app, ok := strings.Cut(strings.TrimPrefix(name, "u"), "_")
+	if !ok || !strings.HasPrefix(name, "u") || len(app) < 2 {
+		return -1
+	}
+	userID, err := strconv.Atoi(user)
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.Atoi(app[1:])
+	if err != nil {
+		return -1
+	}
+	switch app[0] {
+	case 'a':
+		return userID*100000 + 10000 + n
+	case 'i':
+		return userID*100000 + 99000 + n
+	}
+	return -1
+}
+
+// KillProcess sends sig to the process pid. The number is sent as is, so use the Linux
+// numbering; the common signals such as syscall.SIGTERM and syscall.SIGKILL have it on every
+// host. It returns ErrProcessNotRunning if there is no such process, and an error wrapping
+// os.ErrPermission if the process belongs to another uid: the shell uid can only signal its
+// own processes (and debuggable apps' through run-as), root any.
+func (d Device) KillProcess(pid int, sig syscall.Signal) error {
+	output, err := d.RunShellCommand(fmt.Sprintf("kill -%d %d", int(sig), pid))
+	if err != nil {
+		return err
+	}
+	return d.killError(pid, output)
+}
+
+// KillAll sends sig, SIGTERM by default, to every process named name, such as a helper
+// binary left behind by a test. It returns ErrProcessNotRunning if there is none; failures
+// for individual processes are mapped as by KillProcess and joined.
+func (d Device) KillAll(name string, sig ...syscall.Signal) error {
+	signal := syscall.SIGTERM
+	if len(sig) != 0 {
+		signal = sig[0]
+	}
+	output, err := d.RunShellCommand("pidof", shellQuote(name))
+	if err != nil {
+		return err
+	}
+	if len(strings.Fields(output)) == 0 {
+		return fmt.Errorf("adb killall %s: %w", name, ErrProcessNotRunning)
+	}
+
+	var errs []error
+	for _, field := range strings.Fields(output) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("adb pidof %s: %s", name, strings.TrimSpace(output))
+		}
+		// A process that exited in the meantime needs no killing.
+		if err = d.KillProcess(pid, signal); err != nil && !errors.Is(err, ErrProcessNotRunning) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// killError maps the output of kill, such as "kill: pid 123: Operation not permitted", to an
+// error; it is nil for no output.
+func (d Device) killError(pid int, output string) error {
+	output = strings.TrimSpace(output)
+	switch {
+	case output == "":
+		return nil
+	case strings.Contains(output, "No such process"):
+		return fmt.Errorf("adb kill %d: %w", pid, ErrProcessNotRunning)
+	case strings.Contains(output, "Operation not permitted"):
+		as := "the shell uid; signalling other uids' processes needs root"
+		if uid, err := d.RunShellCommand("id -u"); err == nil && strings.TrimSpace(uid) == "0" {
+			as = "root"
+		}
+		return fmt.Errorf("adb kill %d: %w as %s", pid, os.ErrPermission, as)
+	}
+	return fmt.Errorf("adb kill %d: %s", pid, output)
+}
@@ -0,0 +1,66 @@
+package gadb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_parsePS(t *testing.T) {
+	processes, err := parsePS("  PID  PPID   UID S   RSS NAME\n    1     0     0 S  9876 init\n 4321   123 10123 R 56789 com.example\n 77     2     0 S     0 [kworker/0:1]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Process{
+		{PID: 1, PPID: 0, UID: 0, Name: "init", State: "S", RSS: 9876 * 1024},
+		{PID: 4321, PPID: 123, UID: 10123, Name: "com.example", State: "R", RSS: 56789 * 1024},
+		{PID: 77, PPID: 2, UID: 0, Name: "[kworker/0:1]", State: "S"},
+	}
+	if !reflect.DeepEqual(processes, want) {
+		t.Fatalf("got %+v, want %+v", processes, want)
+	}
+
+	// Android 7.1 toolbox ps, whose state column has no header.
+	processes, err = parsePS("USER      PID   PPID  VSIZE  RSS   WCHAN              PC  NAME\r\n" +
+		"root      1     0     10632  1396  SyS_epoll_ 0000000000 S /init\r\n" +
+		"root      2     0     0      0       kthreadd 0000000000 S kthreadd\r\n" +
+		"u0_a57    2215  1757  1558640 61272 SyS_epoll_ 0000000000 S com.android.systemui\r\n" +
+		"u0_a12    3120  1757  1012492 38620 SyS_epoll_ 0000000000 R com.android.phone:remote service\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Process{
+		{PID: 1, PPID: 0, UID: 0, Name: "/init", State: "S", RSS: 1396 * 1024},
+		{PID: 2, PPID: 0, UID: 0, Name: "kthreadd", State: "S"},
+		{PID: 2215, PPID: 1757, UID: 10057, Name: "com.android.systemui", State: "S", RSS: 61272 * 1024},
+		{PID: 3120, PPID: 1757, UID: 10012, Name: "com.android.phone:remote service", State: "R", RSS: 38620 * 1024},
+	}
+	if !reflect.DeepEqual(processes, want) {
+		t.Fatalf("got %+v, want %+v", processes, want)
+	}
+
+	if _, err = parsePS("/system/bin/sh: ps: not found\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func Test_androidUID(t *testing.T) {
+	for name, want := range map[string]int{"shell": 2000, "u0_a123": 10123, "u10_a5": 1010005, "u0_i7": 99007, "user": -1, "u0_x1": -1} {
+		if uid := androidUID(name); uid != want {
+			t.Errorf("%s: got %d, want %d", name, uid, want)
+		}
+	}
+}
+
+func Test_killError(t *testing.T) {
+	var d Device
+	if err := d.killError(123, "\n"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := d.killError(123, "kill: pid 123: No such process\n"); !errors.Is(err, ErrProcessNotRunning) {
+		t.Fatalf("expected ErrProcessNotRunning, got %v", err)
+	}
+	if err := d.killError(123, "kill: unknown signal 99\n"); err == nil || err.Error() != "adb kill 123: kill: unknown signal 99" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package gadb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProvisionPlan declares the desired state of a device. Steps run in field order:
+// installs, pushes, settings, permission grants, an optional reboot, then verification.
+type ProvisionPlan struct {
+	Install     []string          `json:"install,omitempty"`
+	Push        []ProvisionPush   `json:"push,omitempty"`
+	Settings    []ProvisionSet    `json:"settings,omitempty"`
+	Permissions []ProvisionGrant  `json:"permissions,omitempty"`
+	Reboot      bool              `json:"reboot,omitempty"`
+	Verify      []ProvisionVerify `json:"verify,omitempty"`
+}
+
+// ProvisionPush copies a local file to the device.
+type ProvisionPush struct {
+	Local  string      `json:"local"`
+	Remote string      `json:"remote"`
+	Mode   os.FileMode `json:"mode,omitempty"`
+}
+
+// ProvisionSet writes a setting in the "system", "secure" or "global" namespace.
+type ProvisionSet struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+}
+
+// ProvisionGrant grants a runtime permission to a package.
+type ProvisionGrant struct {
+	Package    string `json:"package"`
+	Permission string `json:"permission"`
+}
+
+// ProvisionVerify runs a shell command and checks that its output contains Expect.
+type ProvisionVerify struct {
+	Command string `json:"command"`
+	Expect  string `json:"expect"`
+}
+
+// Provisioner applies a ProvisionPlan to devices, retrying failed steps.
+type Provisioner struct {
+	Plan ProvisionPlan
+	// Retries is the number of extra attempts for a failing step.
+	Retries int
+	// RetryDelay is the pause between attempts.
+	RetryDelay time.Duration
+	// BootTimeout bounds how long a reboot step waits for the device to come back.
+	BootTimeout time.Duration
+	// ContinueOnError keeps going after a step exhausts its retries instead of stopping.
+	ContinueOnError bool
+}
+
+// ProvisionStepResult records the outcome of a single step.
+type ProvisionStepResult struct {
+	Name     string        `json:"name"`
+	Attempts int           `json:"attempts"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ProvisionReport is the machine-readable outcome of Provisioner.Run.
+type ProvisionReport struct {
+	Serial   string                `json:"serial"`
+	Started  time.Time             `json:"started"`
+	Finished time.Time             `json:"finished"`
+	Success  bool                  `json:"success"`
+	Steps    []ProvisionStepResult `json:"steps"`
+}
+
+type provisionStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Run executes the plan against d. The report is returned even when a step fails;
+// the error is that of the first failed step.
+func (p Provisioner) Run(ctx context.Context, d Device) (report ProvisionReport, err error) {
+	return p.run(ctx, d.Serial(), p.steps(d))
+}
+
+// run runs steps with the retries of p and reports on them.
+func (p Provisioner) run(ctx context.Context, serial string, steps []provisionStep) (report ProvisionReport, err error) {
+	report = ProvisionReport{Serial: serial, Started: time.Now(), Steps: []ProvisionStepResult{}}
+	defer func() {
+		report.Finished = time.Now()
+		report.Success = err == nil
+	}()
+
+	for _, step := range steps {
+		result := ProvisionStepResult{Name: step.name}
+		started := time.Now()
+		var stepErr error
+		for {
+			// A done context runs no further attempt, and keeps the error of the last one.
+			if ctx.Err() != nil {
+				if stepErr == nil {
+					stepErr = ctx.Err()
+				}
+				break
+			}
+			result.Attempts++
+			if stepErr = step.run(ctx); stepErr == nil || result.Attempts > p.Retries {
+				break
+			}
+			debugLog(fmt.Sprintf("provision %s: %s failed (attempt %d): %v", serial, step.name, result.Attempts, stepErr))
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.RetryDelay):
+			}
+		}
+		result.Duration = time.Since(started)
+		if stepErr != nil {
+			result.Error = stepErr.Error()
+			if err == nil {
+				err = fmt.Errorf("provision %s: %w", step.name, stepErr)
+			}
+		}
+		report.Steps = append(report.Steps, result)
+		if stepErr != nil && (!p.ContinueOnError || ctx.Err() != nil) {
+			return
+		}
+	}
+	return
+}
+
+func (p Provisioner) steps(d Device) (steps []provisionStep) {
+	for _, apk := range p.Plan.Install {
+		steps = append(steps, provisionStep{"install " + filepath.Base(apk), func(context.Context) error {
+			return d.provisionInstall(apk)
+		}})
+	}
+	for _, push := range p.Plan.Push {
+		steps = append(steps, provisionStep{"push " + push.Remote, func(context.Context) error {
+			f, err := os.Open(push.Local)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			mode := push.Mode
+			if mode == 0 {
+				mode = DefaultFileMode
+			}
+			stat, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			return d.Push(f, push.Remote, stat.ModTime(), mode)
+		}})
+	}
+	for _, set := range p.Plan.Settings {
+		steps = append(steps, provisionStep{fmt.Sprintf("settings %s/%s", set.Namespace, set.Key), func(context.Context) error {
+			return d.Settings(SettingsNamespace(set.Namespace)).Put(set.Key, set.Value)
+		}})
+	}
+	for _, grant := range p.Plan.Permissions {
+		steps = append(steps, provisionStep{fmt.Sprintf("grant %s %s", grant.Package, grant.Permission), func(context.Context) error {
+			return d.expectSilentShell(fmt.Sprintf("pm grant %s %s", shellQuote(grant.Package), shellQuote(grant.Permission)))
+		}})
+	}
+	if p.Plan.Reboot {
+		steps = append(steps, provisionStep{"reboot", func(ctx context.Context) error {
+			return d.Reboot(ctx, RebootSystem, RebootOptions{Wait: true, Timeout: p.BootTimeout})
+		}})
+	}
+	for _, verify := range p.Plan.Verify {
+		steps = append(steps, provisionStep{"verify " + verify.Command, func(context.Context) error {
+			output, err := d.RunShellCommand(verify.Command)
+			if err != nil {
+				return err
+			}
+			if !strings.Contains(output, verify.Expect) {
+				return fmt.Errorf("output %q does not contain %q", strings.TrimSpace(output), verify.Expect)
+			}
+			return nil
+		}})
+	}
+	return
+}
+
+// provisionInstall installs a local APK, replacing any existing version.
+func (d Device) provisionInstall(apk string) (err error) {
+	var f *os.File
+	if f, err = os.Open(apk); err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var info os.FileInfo
+	if info, err = f.Stat(); err != nil {
+		return err
+	}
+	return d.InstallAPK(f, info.Size(), InstallOptions{Replace: true})
+}
+
+// expectSilentShell runs cmd and treats any output as an error message.
+func (d Device) expectSilentShell(cmd string) error {
+	output, err := d.RunShellCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return errors.New(output)
+	}
+	return nil
+}
+
+// waitForDisconnect polls until the device is no longer online, e.g. after a reboot request.
+func (d Device) waitForDisconnect(ctx context.Context) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if state, err := d.State(); err != nil || state != StateOnline {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package gadb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flakyStep returns a step that fails its first failures runs.
+func flakyStep(name string, failures int, runs *int) provisionStep {
+	return provisionStep{name, func(context.Context) error {
+		*runs++
+		if *runs <= failures {
+			return errFlaky
+		}
+		return nil
+	}}
+}
+
+func TestProvisioner_run(t *testing.T) {
+	var first, second int
+	report, err := Provisioner{Retries: 2}.run(context.Background(), "serial", []provisionStep{
+		flakyStep("first", 2, &first),
+		flakyStep("second", 0, &second),
+	})
+	if err != nil || !report.Success || report.Serial != "serial" || len(report.Steps) != 2 {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+	if report.Steps[0].Attempts != 3 || report.Steps[0].Error != "" || report.Steps[1].Attempts != 1 {
+		t.Fatalf("unexpected steps %+v", report.Steps)
+	}
+
+	// A step exhausting its retries stops the run.
+	first, second = 0, 0
+	report, err = Provisioner{Retries: 1}.run(context.Background(), "serial", []provisionStep{
+		flakyStep("first", 5, &first),
+		flakyStep("second", 0, &second),
+	})
+	if err == nil || err.Error() != "provision first: flaky" || report.Success || len(report.Steps) != 1 || first != 2 || second != 0 {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+	if report.Steps[0].Attempts != 2 || report.Steps[0].Error != "flaky" {
+		t.Fatalf("unexpected steps %+v", report.Steps)
+	}
+
+	// ContinueOnError runs the remaining steps but still reports the first failure.
+	first, second = 0, 0
+	var third int
+	report, err = Provisioner{ContinueOnError: true}.run(context.Background(), "serial", []provisionStep{
+		flakyStep("first", 5, &first),
+		flakyStep("second", 5, &second),
+		flakyStep("third", 0, &third),
+	})
+	if err == nil || err.Error() != "provision first: flaky" || report.Success || len(report.Steps) != 3 || third != 1 {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+
+	// A done context runs nothing, and stops continuing.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first, second = 0, 0
+	report, err = Provisioner{Retries: 5, ContinueOnError: true}.run(ctx, "serial", []provisionStep{
+		flakyStep("first", 5, &first),
+		flakyStep("second", 0, &second),
+	})
+	if !errors.Is(err, context.Canceled) || len(report.Steps) != 1 || first != 0 || second != 0 {
+		t.Fatalf("unexpected report %+v, %v after %d runs", report, err, first)
+	}
+	if report.Steps[0].Attempts != 0 || report.Steps[0].Error != "context canceled" {
+		t.Fatalf("unexpected steps %+v", report.Steps)
+	}
+
+	// A context done while waiting to retry stops retrying with the step's own error.
+	ctx, cancel = context.WithCancel(context.Background())
+	first = 0
+	cancelling := provisionStep{"first", func(ctx context.Context) error {
+		first++
+		cancel()
+		return errFlaky
+	}}
+	report, err = Provisioner{Retries: 5, RetryDelay: time.Hour}.run(ctx, "serial", []provisionStep{cancelling})
+	if !errors.Is(err, errFlaky) || first != 1 || report.Steps[0].Attempts != 1 {
+		t.Fatalf("unexpected report %+v, %v after %d runs", report, err, first)
+	}
+}
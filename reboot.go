@@ -0,0 +1,100 @@
+package gadb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBootTimeout bounds how long Reboot waits for the device to come back.
+var DefaultBootTimeout = 5 * time.Minute
+
+// RebootMode is what a device reboots into, as named by `adb reboot`.
+type RebootMode string
+
+const (
+	// RebootSystem boots Android normally.
+	RebootSystem RebootMode = ""
+	// RebootRecovery boots the recovery image.
+	RebootRecovery RebootMode = "recovery"
+	// RebootBootloader boots the bootloader's fastboot mode.
+	RebootBootloader RebootMode = "bootloader"
+	// RebootSideload boots recovery ready to receive an OTA package with adb sideload.
+	RebootSideload RebootMode = "sideload"
+	// RebootFastboot boots fastbootd, the userspace fastboot of devices with dynamic partitions.
+	RebootFastboot RebootMode = "fastboot"
+)
+
+// RebootOptions controls Reboot.
+type RebootOptions struct {
+	// Wait waits for the device to go away and come back in the target mode: booted for
+	// RebootSystem, in the recovery or sideload state for RebootRecovery and RebootSideload.
+	// Fastboot modes aren't visible to adb, so for them, and modes Reboot doesn't know, it
+	// only waits for the device to go away.
+	Wait bool
+	// Timeout bounds waiting; DefaultBootTimeout if zero.
+	Timeout time.Duration
+}
+
+// Reboot reboots the device into mode with the reboot: service. Other modes a bootloader
+// supports, such as "edl", can be given as well. Cached device information is invalidated.
+func (d Device) Reboot(ctx context.Context, mode RebootMode, opts ...RebootOptions) error {
+	service, err := rebootService(mode)
+	if err != nil {
+		return err
+	}
+	var o RebootOptions
+	if len(opts) != 0 {
+		o = opts[0]
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultBootTimeout
+	}
+
+	if _, err = d.executeCommand(service, true); err != nil {
+		return fmt.Errorf("adb reboot %s: %w", mode, err)
+	}
+	d.InvalidateCache()
+	if !o.Wait {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+	if err = d.waitForDisconnect(ctx); err != nil {
+		return fmt.Errorf("adb reboot %s: %w", mode, err)
+	}
+	switch booted, state := rebootTarget(mode); {
+	case booted:
+		err = d.WaitBootComplete(ctx)
+	case state != "":
+		err = d.waitForState(ctx, state)
+	}
+	if err != nil {
+		return fmt.Errorf("adb reboot %s: %w", mode, err)
+	}
+	return nil
+}
+
+// rebootService returns the adb service rebooting into mode.
+func rebootService(mode RebootMode) (string, error) {
+	if strings.ContainsAny(string(mode), ": \t\n") {
+		return "", fmt.Errorf("adb reboot: invalid mode %q", mode)
+	}
+	return "reboot:" + string(mode), nil
+}
+
+// rebootTarget returns what Reboot waits for once the device has gone away: a completed
+// boot, a device state, or neither for modes adb can't see.
+func rebootTarget(mode RebootMode) (booted bool, state DeviceState) {
+	switch mode {
+	case RebootSystem:
+		return true, ""
+	case RebootRecovery:
+		return false, StateRecovery
+	case RebootSideload:
+		return false, StateSideload
+	}
+	return false, ""
+}
@@ -0,0 +1,40 @@
+package gadb
+
+import "testing"
+
+func Test_rebootService(t *testing.T) {
+	for mode, want := range map[RebootMode]string{
+		RebootSystem:     "reboot:",
+		RebootRecovery:   "reboot:recovery",
+		RebootBootloader: "reboot:bootloader",
+		"edl":            "reboot:edl",
+	} {
+		if service, err := rebootService(mode); err != nil || service != want {
+			t.Errorf("%q: got %q, %v, want %q", mode, service, err, want)
+		}
+	}
+	for _, mode := range []RebootMode{"recovery:wipe", "boot loader", "sideload\n"} {
+		if _, err := rebootService(mode); err == nil {
+			t.Errorf("%q: expected an error", mode)
+		}
+	}
+}
+
+func Test_rebootTarget(t *testing.T) {
+	for _, tt := range []struct {
+		mode   RebootMode
+		booted bool
+		state  DeviceState
+	}{
+		{RebootSystem, true, ""},
+		{RebootRecovery, false, StateRecovery},
+		{RebootSideload, false, StateSideload},
+		{RebootBootloader, false, ""},
+		{RebootFastboot, false, ""},
+		{"edl", false, ""},
+	} {
+		if booted, state := rebootTarget(tt.mode); booted != tt.booted || state != tt.state {
+			t.Errorf("%q: got %t, %q, want %t, %q", tt.mode, booted, state, tt.booted, tt.state)
+		}
+	}
+}
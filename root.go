@@ -0,0 +1,110 @@
+package gadb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrRootNotAllowed is returned by Root on production builds, whose adbd can't run as root.
+var ErrRootNotAllowed = errors.New("adbd cannot run as root")
+
+// ErrRootRequired is returned by operations that only work while adbd runs as root; see Root.
+var ErrRootRequired = errors.New("adbd must run as root")
+
+// RootOptions controls Root and Unroot.
+type RootOptions struct {
+	// Wait waits for adbd to come back after restarting, running as the requested user.
+	Wait bool
+	// Timeout bounds waiting; DefaultResetTimeout if zero.
+	Timeout time.Duration
+}
+
+// Root restarts adbd as root with the root: service, so that shell commands run as root.
+// It returns ErrRootNotAllowed on production builds, and does nothing if adbd already runs
+// as root. Cached device information is invalidated.
+func (d Device) Root(ctx context.Context, opts ...RootOptions) error {
+	return d.restartAdbd(ctx, "root", opts)
+}
+
+// Unroot restarts adbd as the shell user with the unroot: service. It does nothing if adbd
+// doesn't run as root.
+func (d Device) Unroot(ctx context.Context, opts ...RootOptions) error {
+	return d.restartAdbd(ctx, "unroot", opts)
+}
+
+// IsRoot reports whether shell commands run as root.
+func (d Device) IsRoot() (bool, error) {
+	output, err := d.RunShellCommand("id -u")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) == "0", nil
+}
+
+func (d Device) restartAdbd(ctx context.Context, service string, opts []RootOptions) error {
+	var o RootOptions
+	if len(opts) != 0 {
+		o = opts[0]
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultResetTimeout
+	}
+
+	raw, err := d.executeCommand(service + ":")
+	if err != nil {
+		return fmt.Errorf("adb %s: %w", service, err)
+	}
+	restarting, err := parseRootResponse(service, string(raw))
+	if err != nil || !restarting {
+		return err
+	}
+	d.InvalidateCache()
+	if !o.Wait {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+	if err = d.waitForRoot(ctx, service == "root"); err != nil {
+		return fmt.Errorf("adb %s: %w", service, err)
+	}
+	return nil
+}
+
+// waitForRoot polls until the device is online with adbd running as root, or not.
+// Commands may still reach the old adbd before it exits, so the shell user is checked
+// rather than the device going away.
+func (d Device) waitForRoot(ctx context.Context, root bool) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if state, err := d.State(); err == nil && state == StateOnline {
+			if output, err := d.RunShellCommand("id -u"); err == nil && (strings.TrimSpace(output) == "0") == root {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseRootResponse parses the message adbd answers the root: or unroot: service with, and
+// reports whether adbd is restarting.
+func parseRootResponse(service, output string) (restarting bool, err error) {
+	output = strings.TrimSpace(output)
+	switch {
+	case strings.HasPrefix(output, "restarting adbd"):
+		return true, nil
+	case output == "adbd is already running as root", output == "adbd not running as root":
+		return false, nil
+	case strings.HasPrefix(output, "adbd cannot run as root"):
+		return false, fmt.Errorf("adb %s: %w", service, ErrRootNotAllowed)
+	}
+	return false, fmt.Errorf("adb %s: %s", service, output)
+}
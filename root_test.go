@@ -0,0 +1,28 @@
+package gadb
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_parseRootResponse(t *testing.T) {
+	for _, tt := range []struct {
+		service, output string
+		restarting      bool
+		err             error
+	}{
+		{"root", "restarting adbd as root\n", true, nil},
+		{"root", "adbd is already running as root\n", false, nil},
+		{"unroot", "restarting adbd as non root\n", true, nil},
+		{"unroot", "adbd not running as root\n", false, nil},
+		{"root", "adbd cannot run as root in production builds\n", false, ErrRootNotAllowed},
+	} {
+		restarting, err := parseRootResponse(tt.service, tt.output)
+		if restarting != tt.restarting || !errors.Is(err, tt.err) {
+			t.Errorf("%q: got %t, %v, want %t, %v", tt.output, restarting, err, tt.restarting, tt.err)
+		}
+	}
+	if _, err := parseRootResponse("root", "error: closed"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,170 @@
+package gadb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rotation is a display orientation in quarter turns, as stored in user_rotation.
+type Rotation int
+
+const (
+	Rotation0   Rotation = 0
+	Rotation90  Rotation = 1
+	Rotation180 Rotation = 2
+	Rotation270 Rotation = 3
+)
+
+// MaxBrightness is the highest value of the screen_brightness setting.
+const MaxBrightness = 255
+
+// SetRotation turns auto-rotation off and locks the display in rotation r, so that
+// screenshots come out the same whichever way the device lies.
+func (d Device) SetRotation(r Rotation) error {
+	if err := checkRotation(r); err != nil {
+		return err
+	}
+	return d.putSystemSettings("set rotation", "accelerometer_rotation", "0", "user_rotation", strconv.Itoa(int(r)))
+}
+
+func checkRotation(r Rotation) error {
+	if r < Rotation0 || r > Rotation270 {
+		return fmt.Errorf("adb set rotation: invalid rotation %d", r)
+	}
+	return nil
+}
+
+// SetAutoRotate turns rotation following the accelerometer on or off. Turning it off keeps
+// the rotation last set with SetRotation.
+func (d Device) SetAutoRotate(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return d.putSystemSettings("set auto-rotate", "accelerometer_rotation", value)
+}
+
+// Rotation returns the locked rotation and whether auto-rotation is on. While it is on, the
+// display follows the accelerometer instead; Displays reports the actual rotation.
+func (d Device) Rotation() (r Rotation, auto bool, err error) {
+	values, err := d.systemSettings("user_rotation", "accelerometer_rotation")
+	if err != nil {
+		return 0, false, err
+	}
+	return Rotation(values[0]), values[1] == 1, nil
+}
+
+// SetBrightness switches to manual brightness and sets the level, from 0 to MaxBrightness.
+func (d Device) SetBrightness(level int) error {
+	if err := checkBrightness(level); err != nil {
+		return err
+	}
+	return d.putSystemSettings("set brightness", "screen_brightness_mode", "0", "screen_brightness", strconv.Itoa(level))
+}
+
+func checkBrightness(level int) error {
+	if level < 0 || level > MaxBrightness {
+		return fmt.Errorf("adb set brightness: level %d out of range 0-%d", level, MaxBrightness)
+	}
+	return nil
+}
+
+// SetAutoBrightness turns adaptive brightness on or off.
+func (d Device) SetAutoBrightness(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	return d.putSystemSettings("set auto-brightness", "screen_brightness_mode", value)
+}
+
+// Brightness returns the manual brightness level and whether adaptive brightness is on,
+// in which case the level is only its starting point.
+func (d Device) Brightness() (level int, auto bool, err error) {
+	values, err := d.systemSettings("screen_brightness", "screen_brightness_mode")
+	if err != nil {
+		return 0, false, err
+	}
+	return values[0], values[1] == 1, nil
+}
+
+// animationScaleKeys are the global settings of the window, transition and animator
+// duration scales of the developer options.
+var animationScaleKeys = [3]string{"window_animation_scale", "transition_animation_scale", "animator_duration_scale"}
+
+// SetAnimationScales sets the window animation, transition animation and animator duration
+// scales; 1 is the normal speed and 0 turns the animations off. Negative, infinite and NaN
+// scales are rejected before any is written.
+func (d Device) SetAnimationScales(window, transition, animator float64) error {
+	scales := [3]float64{window, transition, animator}
+	if err := checkAnimationScales(scales); err != nil {
+		return err
+	}
+	settings := d.Settings(SettingsGlobal)
+	for i, scale := range scales {
+		if err := settings.PutFloat(animationScaleKeys[i], scale); err != nil {
+			return fmt.Errorf("adb set animation scales: %w", err)
+		}
+	}
+	return nil
+}
+
+func checkAnimationScales(scales [3]float64) error {
+	for i, scale := range scales {
+		if scale < 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+			return fmt.Errorf("adb set animation scales: invalid %s %v", animationScaleKeys[i], scale)
+		}
+	}
+	return nil
+}
+
+// DisableAnimations sets every animation scale to 0, so that UI tests don't race
+// animations. SetAnimationScales(1, 1, 1) turns them back on.
+func (d Device) DisableAnimations() error {
+	return d.SetAnimationScales(0, 0, 0)
+}
+
+// AnimationScales returns the window animation, transition animation and animator duration
+// scales; unset ones read as 1, the default.
+func (d Device) AnimationScales() (window, transition, animator float64, err error) {
+	settings := d.Settings(SettingsGlobal)
+	var scales [3]float64
+	for i, key := range animationScaleKeys {
+		if scales[i], err = settings.GetFloat(key, 1); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return scales[0], scales[1], scales[2], nil
+}
+
+// putSystemSettings writes key, value pairs of the system namespace.
+func (d Device) putSystemSettings(op string, pairs ...string) error {
+	settings := d.Settings(SettingsSystem)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := settings.Put(pairs[i], pairs[i+1]); err != nil {
+			return fmt.Errorf("adb %s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// systemSettings reads integer settings of the system namespace; missing ones read as 0.
+func (d Device) systemSettings(keys ...string) ([]int, error) {
+	settings := d.Settings(SettingsSystem)
+	values := make([]int, len(keys))
+	for i, key := range keys {
+		value, ok, err := settings.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if values[i], err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("adb settings get system %s: unexpected value %q", key, value)
+		}
+	}
+	return values, nil
+}
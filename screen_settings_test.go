@@ -0,0 +1,60 @@
+package gadb
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_checkRotation(t *testing.T) {
+	for _, tt := range []struct {
+		r  Rotation
+		ok bool
+	}{
+		{Rotation0, true},
+		{Rotation90, true},
+		{Rotation270, true},
+		{-1, false},
+		{4, false},
+	} {
+		if err := checkRotation(tt.r); (err == nil) != tt.ok {
+			t.Errorf("%d: got %v", tt.r, err)
+		}
+	}
+}
+
+func Test_checkBrightness(t *testing.T) {
+	for _, tt := range []struct {
+		level int
+		ok    bool
+	}{
+		{0, true},
+		{128, true},
+		{MaxBrightness, true},
+		{-1, false},
+		{MaxBrightness + 1, false},
+	} {
+		if err := checkBrightness(tt.level); (err == nil) != tt.ok {
+			t.Errorf("%d: got %v", tt.level, err)
+		}
+	}
+}
+
+func Test_checkAnimationScales(t *testing.T) {
+	for _, tt := range []struct {
+		scales [3]float64
+		ok     bool
+	}{
+		{[3]float64{0, 0, 0}, true},
+		{[3]float64{1, 0.5, 10}, true},
+		{[3]float64{1, -1, 1}, false},
+		{[3]float64{1, 1, math.NaN()}, false},
+		{[3]float64{math.Inf(1), 1, 1}, false},
+	} {
+		if err := checkAnimationScales(tt.scales); (err == nil) != tt.ok {
+			t.Errorf("%v: got %v", tt.scales, err)
+		}
+	}
+	if err := checkAnimationScales([3]float64{1, 1, math.NaN()}); err == nil || err.Error() != "adb set animation scales: invalid animator_duration_scale NaN" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
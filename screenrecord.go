@@ -0,0 +1,170 @@
+package gadb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxScreenRecordTime is the longest screenrecord will record in one go.
+const MaxScreenRecordTime = 3 * time.Minute
+
+// ScreenRecordOptions configures ScreenRecord.
+type ScreenRecordOptions struct {
+	// Width and Height set the video size (--size); the display size if zero.
+	Width, Height int
+	// BitRate is the video bit rate in bits per second (--bit-rate); 20 Mbps if zero.
+	BitRate int
+	// TimeLimit stops the recording after the given time (--time-limit); MaxScreenRecordTime
+	// if zero. Longer limits are capped by screenrecord.
+	TimeLimit time.Duration
+	// BugReport overlays the time stamp and frame number (--bugreport).
+	BugReport bool
+}
+
+func (opts ScreenRecordOptions) args() []string {
+	var args []string
+	if opts.Width > 0 && opts.Height > 0 {
+		args = append(args, "--size", fmt.Sprintf("%dx%d", opts.Width, opts.Height))
+	}
+	if opts.BitRate > 0 {
+		args = append(args, "--bit-rate", strconv.Itoa(opts.BitRate))
+	}
+	if opts.TimeLimit > 0 {
+		args = append(args, "--time-limit", strconv.Itoa(int(max(opts.TimeLimit.Seconds(), 1))))
+	}
+	if opts.BugReport {
+		args = append(args, "--bugreport")
+	}
+	return args
+}
+
+// ScreenRecord records the screen into dst as an MP4 until the time limit is reached or ctx
+// is done. An MP4 can only be written to a seekable file, so the video is recorded into a
+// temporary file on the device, which is pulled into dst and removed afterwards. When ctx is
+// done screenrecord is interrupted, which finalises the file, so the video recorded so far is
+// still written to dst; the returned error is only about failures, not the cancellation.
+func (d Device) ScreenRecord(ctx context.Context, dst io.Writer, opts ScreenRecordOptions) (err error) {
+	remotePath := path.Join(DefaultExecDir, fmt.Sprintf("gadb-screenrecord-%d.mp4", time.Now().UnixNano()))
+	defer func() { _ = d.Remove(remotePath) }()
+
+	// Run screenrecord in the background to learn its PID, so it can be sent SIGINT: closing
+	// the connection would kill it without writing the MP4 index.
+	cmd := fmt.Sprintf("screenrecord %s %s 2>&1 & echo $!; wait", strings.Join(opts.args(), " "), shellQuote(remotePath))
+	conn, err := d.openExec(context.Background(), cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	br := bufio.NewReader(conn)
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("adb screenrecord: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return fmt.Errorf("adb screenrecord: unexpected output %q", line)
+	}
+
+	done := make(chan error, 1)
+	var output strings.Builder
+	go func() {
+		_, err := io.Copy(&output, br)
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		if _, err = d.RunShellCommand(fmt.Sprintf("kill -INT %d", pid)); err != nil {
+			return err
+		}
+		err = <-done
+	}
+	if err != nil {
+		return fmt.Errorf("adb screenrecord: %w", err)
+	}
+	// screenrecord only prints on failure, but warnings there don't necessarily mean no video.
+	if err = d.Pull(remotePath, dst); err != nil {
+		if msg := strings.TrimSpace(output.String()); msg != "" {
+			return fmt.Errorf("adb screenrecord: %s: %w", msg, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// ScreenStream streams the screen as a raw H.264 elementary stream (Annex B), as produced by
+// `screenrecord --output-format=h264`, for feeding a decoder in real time. screenrecord stops
+// after MaxScreenRecordTime, so sessions are restarted transparently; each one begins with
+// fresh SPS and PPS units, which decoders handle as a stream reconfiguration. With
+// opts.TimeLimit the whole stream ends after that time; otherwise it runs until ctx is done
+// or the reader is closed.
+func (d Device) ScreenStream(ctx context.Context, opts ScreenRecordOptions) (io.ReadCloser, error) {
+	var deadline time.Time
+	if opts.TimeLimit > 0 {
+		deadline = time.Now().Add(opts.TimeLimit)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	// open starts the next session, or returns nil once the time limit is used up.
+	open := func() (*execConn, error) {
+		session := opts
+		var ok bool
+		if session.TimeLimit, ok = sessionTimeLimit(deadline, time.Now()); !ok {
+			return nil, nil
+		}
+		return d.openExec(ctx, fmt.Sprintf("screenrecord --output-format=h264 %s -", strings.Join(session.args(), " ")))
+	}
+
+	conn, err := open()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer cancel()
+		for conn != nil {
+			n, err := io.Copy(pw, conn)
+			_ = conn.Close()
+			switch {
+			case ctx.Err() != nil:
+				_ = pw.Close()
+				return
+			case err != nil:
+				_ = pw.CloseWithError(err)
+				return
+			case n == 0:
+				// A session that ends without a frame won't do better when restarted.
+				_ = pw.CloseWithError(errors.New("adb screenrecord: no video data; is H.264 output supported?"))
+				return
+			}
+			if conn, err = open(); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+	return &cancelReader{PipeReader: pr, cancel: cancel}, nil
+}
+
+// sessionTimeLimit returns the time limit of a ScreenStream session starting at now, for a
+// stream ending at deadline, or false once less than a second, screenrecord's granularity,
+// is left. A zero deadline never runs out.
+func sessionTimeLimit(deadline, now time.Time) (time.Duration, bool) {
+	if deadline.IsZero() {
+		return MaxScreenRecordTime, true
+	}
+	limit := min(deadline.Sub(now), MaxScreenRecordTime)
+	return limit, limit >= time.Second
+}
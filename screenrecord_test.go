@@ -0,0 +1,38 @@
+package gadb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScreenRecordOptions_args(t *testing.T) {
+	opts := ScreenRecordOptions{Width: 720, Height: 1280, BitRate: 4000000, TimeLimit: 30 * time.Second, BugReport: true}
+	if got, want := strings.Join(opts.args(), " "), "--size 720x1280 --bit-rate 4000000 --time-limit 30 --bugreport"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if args := (ScreenRecordOptions{Width: 720}).args(); len(args) != 0 {
+		t.Fatalf("unexpected args %q", args)
+	}
+}
+
+func Test_sessionTimeLimit(t *testing.T) {
+	now := time.Now()
+	for _, tt := range []struct {
+		deadline time.Time
+		want     time.Duration
+		ok       bool
+	}{
+		{time.Time{}, MaxScreenRecordTime, true},
+		{now.Add(10 * time.Minute), MaxScreenRecordTime, true},
+		{now.Add(70 * time.Second), 70 * time.Second, true},
+		{now.Add(time.Second), time.Second, true},
+		// screenrecord can't record for less than a second, so the stream ends.
+		{now.Add(999 * time.Millisecond), 999 * time.Millisecond, false},
+		{now.Add(-time.Second), -time.Second, false},
+	} {
+		if limit, ok := sessionTimeLimit(tt.deadline, now); limit != tt.want || ok != tt.ok {
+			t.Errorf("%v before the deadline: got %v, %v, want %v, %v", tt.deadline.Sub(now), limit, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Pixel formats of the raw screencap output, from android.graphics.PixelFormat.
+const (
+	pixelFormatRGBA8888 = 1
+	pixelFormatRGBX8888 = 2
+	pixelFormatRGB565   = 4
+)
+
+// ScreenshotPNGBytes captures the screen with `screencap -p` and returns the PNG file. It
+// uses the exec service, so the image isn't mangled by line ending translation.
+func (d Device) ScreenshotPNGBytes() ([]byte, error) {
+	raw, err := d.screencap("screencap -p")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(raw, pngSignature) {
+		return nil, fmt.Errorf("adb screencap: unexpected output %q", truncateOutput(raw))
+	}
+	return raw, nil
+}
+
+// Screenshot captures the screen and returns it as an image. It reads screencap's raw pixel
+// format, which saves the device from encoding a PNG.
+func (d Device) Screenshot() (image.Image, error) {
+	raw, err := d.screencap("screencap")
+	if err != nil {
+		return nil, err
+	}
+	return decodeScreencap(raw)
+}
+
+func (d Device) screencap(cmd string) ([]byte, error) {
+	conn, err := d.openExec(context.Background(), cmd)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	return io.ReadAll(conn)
+}
+
+// decodeScreencap decodes screencap output: a PNG, or the raw format of a header of little
+// endian uint32 width, height, pixel format and, since Android 9, color space, followed by
+// the pixels.
+func decodeScreencap(raw []byte) (image.Image, error) {
+	if bytes.HasPrefix(raw, pngSignature) {
+		return png.Decode(bytes.NewReader(raw))
+	}
+	if len(raw) < 12 {
+		return nil, fmt.Errorf("adb screencap: unexpected output %q", truncateOutput(raw))
+	}
+
+	width := int(binary.LittleEndian.Uint32(raw[0:]))
+	height := int(binary.LittleEndian.Uint32(raw[4:]))
+	format := binary.LittleEndian.Uint32(raw[8:])
+	bpp := 4
+	if format == pixelFormatRGB565 {
+		bpp = 2
+	} else if format != pixelFormatRGBA8888 && format != pixelFormatRGBX8888 {
+		return nil, fmt.Errorf("adb screencap: unsupported pixel format %d", format)
+	}
+
+	size := width * height * bpp
+	var pixels []byte
+	switch len(raw) - size {
+	case 12, 16:
+		pixels = raw[len(raw)-size:]
+	default:
+		return nil, errors.New("adb screencap: raw image size doesn't match its header")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	switch format {
+	case pixelFormatRGBA8888:
+		copy(img.Pix, pixels)
+	case pixelFormatRGBX8888:
+		copy(img.Pix, pixels)
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	case pixelFormatRGB565:
+		for i := 0; i < width*height; i++ {
+			v := binary.LittleEndian.Uint16(pixels[2*i:])
+			r, g, b := byte(v>>11), byte(v>>5&0x3f), byte(v&0x1f)
+			img.Pix[4*i+0] = r<<3 | r>>2
+			img.Pix[4*i+1] = g<<2 | g>>4
+			img.Pix[4*i+2] = b<<3 | b>>2
+			img.Pix[4*i+3] = 0xff
+		}
+	}
+	return img, nil
+}
+
+// truncateOutput shortens unexpected command output for an error message.
+func truncateOutput(raw []byte) []byte {
+	if len(raw) > 64 {
+		return raw[:64]
+	}
+	return raw
+}
@@ -0,0 +1,69 @@
+package gadb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func rawScreencap(width, height, format uint32, colorSpace bool, pixels []byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{width, height, format})
+	if colorSpace {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+	}
+	buf.Write(pixels)
+	return buf.Bytes()
+}
+
+func Test_decodeScreencap(t *testing.T) {
+	img, err := decodeScreencap(rawScreencap(2, 1, pixelFormatRGBA8888, true, []byte{255, 0, 0, 255, 0, 0, 255, 128}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("unexpected bounds %v", img.Bounds())
+	}
+	if c := color.NRGBAModel.Convert(img.At(1, 0)); c != (color.NRGBA{B: 255, A: 128}) {
+		t.Fatalf("unexpected pixel %v", c)
+	}
+
+	img, err = decodeScreencap(rawScreencap(1, 1, pixelFormatRGBX8888, false, []byte{1, 2, 3, 0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := color.NRGBAModel.Convert(img.At(0, 0)); c != (color.NRGBA{R: 1, G: 2, B: 3, A: 255}) {
+		t.Fatalf("unexpected pixel %v", c)
+	}
+
+	img, err = decodeScreencap(rawScreencap(1, 1, pixelFormatRGB565, true, []byte{0xe0, 0x07}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := color.NRGBAModel.Convert(img.At(0, 0)); c != (color.NRGBA{G: 255, A: 255}) {
+		t.Fatalf("unexpected pixel %v", c)
+	}
+
+	if _, err = decodeScreencap(rawScreencap(4, 4, pixelFormatRGBA8888, true, []byte{1, 2, 3})); err == nil {
+		t.Fatal("expected an error for a short image")
+	}
+}
+
+func Test_decodeScreencap_png(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(2, 1, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	img, err := decodeScreencap(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := color.NRGBAModel.Convert(img.At(2, 1)); c != (color.NRGBA{R: 10, G: 20, B: 30, A: 255}) {
+		t.Fatalf("unexpected pixel %v", c)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"syscall"
 )
 
 // A Session represents a connection to a remote command or shell.
@@ -53,6 +54,36 @@ func (e *ExitError) Error() string {
 	return fmt.Sprintf("unexpected error code %d", e.exitStatus)
 }
 
+// A SignalError reports that a remote command was terminated by a signal,
+// for example SIGKILL from the low-memory killer. The shell protocol only carries an exit
+// status, so a command that exits with 128+N itself is reported the same way; a
+// SignalError therefore also unwraps to the *ExitError of its status.
+type SignalError struct {
+	Waitmsg
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("killed by signal %d (%s)", int(e.Signal()), e.Signal())
+}
+
+func (e *SignalError) Unwrap() error {
+	return &ExitError{Waitmsg: e.Waitmsg}
+}
+
+// A TransportError reports that the connection to the device broke before the
+// remote command reported its exit status.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("transport failed: %v", e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
 // Waitmsg stores the information about an exited remote command as reported by Wait.
 type Waitmsg struct {
 	exitStatus int
@@ -63,6 +94,29 @@ func (w Waitmsg) ExitStatus() int {
 	return w.exitStatus
 }
 
+// Signal returns the signal that terminated the remote command, or 0 if it exited normally.
+// adbd and the device shell both report death by signal N as exit status 128+N.
+func (w Waitmsg) Signal() syscall.Signal {
+	if w.exitStatus > 128 && w.exitStatus < 128+65 {
+		return syscall.Signal(w.exitStatus - 128)
+	}
+	return 0
+}
+
+// exitStatusError returns the error describing a reported exit status: nil for 0,
+// *SignalError if the command was killed by a signal, *ExitError otherwise.
+func exitStatusError(exitStatus int) error {
+	msg := Waitmsg{exitStatus: exitStatus}
+	switch {
+	case exitStatus == 0:
+		return nil
+	case msg.Signal() != 0:
+		return &SignalError{Waitmsg: msg}
+	default:
+		return &ExitError{Waitmsg: msg}
+	}
+}
+
 // NewSession opens a new Session for this client. (A session is a remote execution of a program.)
 func (d Device) NewSession() (*Session, error) {
 	tp, err := d.createDeviceTransport()
@@ -163,7 +217,7 @@ func (s *Session) Start(cmd string) error {
 			for !s.abort {
 				n, err := s.Stdin.Read(buffer)
 				if err == io.EOF {
-					if err := shellTp.Send(shellCloseStdin, []byte{}); err != nil {
+					if err := shellTp.Send(ShellCloseStdin, []byte{}); err != nil {
 						s.errorChan <- fmt.Errorf("failed to close stdin: %w", err)
 					}
 					return
@@ -172,11 +226,11 @@ func (s *Session) Start(cmd string) error {
 					s.errorChan <- fmt.Errorf("failed to copy stdin: %w", err)
 					return
 				}
-				shellTp.Send(shellStdin, buffer[0:n])
+				shellTp.Send(ShellStdin, buffer[0:n])
 			}
 		}()
 	} else {
-		if err := shellTp.Send(shellCloseStdin, []byte{}); err != nil {
+		if err := shellTp.Send(ShellCloseStdin, []byte{}); err != nil {
 			return fmt.Errorf("failed to close stdin: %w", err)
 		}
 	}
@@ -187,41 +241,42 @@ func (s *Session) Start(cmd string) error {
 				break
 			}
 			if err != nil {
-				s.errorChan <- fmt.Errorf("failed to read shell msg: %w", err)
+				s.errorChan <- fmt.Errorf("failed to read shell msg: %w", &TransportError{Err: err})
 				return
 			}
 			switch msgType {
-			case shellStdout: // stdout
+			case ShellStdout: // stdout
 				if s.Stdout != nil {
 					if _, err := s.Stdout.Write(msg); err != nil {
 						s.errorChan <- fmt.Errorf("failed to write stdout: %w", err)
 						return
 					}
 				}
-			case shellStderr: // stderr
+			case ShellStderr: // stderr
 				if s.Stderr != nil {
 					if _, err := s.Stderr.Write(msg); err != nil {
 						s.errorChan <- fmt.Errorf("failed to write stderr: %w", err)
 						return
 					}
 				}
-			case shellExit: // exit
+			case ShellExit: // exit
 				exitCode := int(msg[0])
 				err := s.closeFiles()
 				if err != nil {
 					s.errorChan <- fmt.Errorf("failed to close files: %w", err)
 				}
-				if exitCode == 0 {
-					s.errorChan <- nil
-				} else {
-					s.errorChan <- &ExitError{
-						Waitmsg: Waitmsg{exitStatus: exitCode},
-					}
-				}
+				s.errorChan <- exitStatusError(exitCode)
 				return
 			default:
-				s.errorChan <- fmt.Errorf("unexpected shell message %d: %w", msgType, err)
-				return
+				ok, err := handleShellPacket(msgType, msg)
+				if !ok {
+					s.errorChan <- fmt.Errorf("unexpected shell message %d", msgType)
+					return
+				}
+				if err != nil {
+					s.errorChan <- fmt.Errorf("shell message %d: %w", msgType, err)
+					return
+				}
 			}
 		}
 		s.errorChan <- &ExitMissingError{}
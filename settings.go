@@ -0,0 +1,154 @@
+package gadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SettingsNamespace is a namespace of Android settings, as stored by the settings provider.
+type SettingsNamespace string
+
+const (
+	SettingsSystem SettingsNamespace = "system"
+	SettingsSecure SettingsNamespace = "secure"
+	SettingsGlobal SettingsNamespace = "global"
+)
+
+// Settings reads and writes the settings of one namespace with the device's `settings`
+// command. Obtain one with Device.Settings.
+type Settings struct {
+	d         Device
+	namespace SettingsNamespace
+}
+
+// Settings returns the settings of namespace, such as
+//
+//	d.Settings(gadb.SettingsGlobal).PutFloat("window_animation_scale", 0)
+func (d Device) Settings(namespace SettingsNamespace) Settings {
+	return Settings{d: d, namespace: namespace}
+}
+
+// Get returns the value of key, and whether it is set.
+func (s Settings) Get(key string) (value string, ok bool, err error) {
+	output, err := s.run("get", key)
+	if err != nil {
+		return "", false, err
+	}
+	// settings prints "null" for an unset key; an empty value is printed as is.
+	value = strings.TrimSuffix(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if value == "null" {
+		return "", false, nil
+	}
+	return value, true, nil
+}
+
+// Put sets key to value.
+func (s Settings) Put(key, value string) error {
+	output, err := s.run("put", key, value)
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb settings put %s %s: %s", s.namespace, key, output)
+	}
+	return nil
+}
+
+// Delete removes key, so that readers fall back to their default.
+func (s Settings) Delete(key string) error {
+	output, err := s.run("delete", key)
+	if err != nil {
+		return err
+	}
+	// settings reports the number of rows deleted.
+	if output = strings.TrimSpace(output); output != "" && !strings.HasPrefix(output, "Deleted ") {
+		return fmt.Errorf("adb settings delete %s %s: %s", s.namespace, key, output)
+	}
+	return nil
+}
+
+// List returns every setting of the namespace.
+func (s Settings) List() (map[string]string, error) {
+	output, err := s.run("list")
+	if err != nil {
+		return nil, err
+	}
+	return parseSettingsList(output), nil
+}
+
+// GetInt returns the integer setting key, or def if it is unset or not an integer.
+func (s Settings) GetInt(key string, def int) (int, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return n, nil
+	}
+	return def, nil
+}
+
+// GetFloat returns the floating-point setting key, such as an animation scale, or def if it
+// is unset or not a number.
+func (s Settings) GetFloat(key string, def float64) (float64, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return f, nil
+	}
+	return def, nil
+}
+
+// GetBool returns the boolean setting key, stored as "1" or "0" like airplane_mode_on, or def
+// if it is unset or not an integer. Other non-zero integers read as true, as they do for
+// Android.
+func (s Settings) GetBool(key string, def bool) (bool, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return n != 0, nil
+	}
+	return def, nil
+}
+
+// PutInt sets key to n.
+func (s Settings) PutInt(key string, n int) error {
+	return s.Put(key, strconv.Itoa(n))
+}
+
+// PutFloat sets key to f.
+func (s Settings) PutFloat(key string, f float64) error {
+	return s.Put(key, strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// PutBool sets key to "1" or "0".
+func (s Settings) PutBool(key string, b bool) error {
+	if b {
+		return s.Put(key, "1")
+	}
+	return s.Put(key, "0")
+}
+
+func (s Settings) run(cmd string, args ...string) (string, error) {
+	quoted := []string{"settings", cmd, shellQuote(string(s.namespace))}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return s.d.RunShellCommand(strings.Join(quoted, " "))
+}
+
+// parseSettingsList parses the "key=value" lines of `settings list`. Values may contain "=".
+func parseSettingsList(output string) map[string]string {
+	settings := map[string]string{}
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok && key != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
@@ -0,0 +1,20 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseSettingsList(t *testing.T) {
+	settings := parseSettingsList("airplane_mode_on=0\r\nadb_wifi_enabled=1\nwindow_animation_scale=0.5\nfoo=a=b\nempty=\n\n")
+	want := map[string]string{
+		"airplane_mode_on":       "0",
+		"adb_wifi_enabled":       "1",
+		"window_animation_scale": "0.5",
+		"foo":                    "a=b",
+		"empty":                  "",
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Fatalf("got %v, want %v", settings, want)
+	}
+}
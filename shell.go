@@ -2,6 +2,7 @@ package gadb
 
 import (
 	"io"
+	"strings"
 )
 
 // Shell represents a running adb shell session started with a specific command.
@@ -35,19 +36,29 @@ func newShellReader(st *shellTransport) io.Reader {
 				return
 			}
 			switch msgType {
-			case shellStdout, shellStderr:
+			case ShellStdout, ShellStderr:
 				if len(data) > 0 {
 					if _, werr := pw.Write(data); werr != nil {
 						return
 					}
 				}
-			case shellExit:
+			case ShellExit:
 				// exit code in data[0], we simply end the stream
 				return
-			case shellCloseStdin:
+			case ShellCloseStdin:
 				// ignore for read side
+			default:
+				if _, err := handleShellPacket(msgType, data); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
 			}
 		}
 	}()
 	return pr
 }
+
+// shellQuote quotes s so the device shell treats it as a single literal argument.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ShellPacketHandler handles shell v2 packets of a type gadb doesn't interpret itself.
+// Returning an error aborts the session that received the packet.
+type ShellPacketHandler func(command ShellPacketType, data []byte) error
+
+var (
+	shellHandlersMu sync.RWMutex
+	shellHandlers   = map[ShellPacketType]ShellPacketHandler{}
+)
+
+// RegisterShellPacketHandler installs handler for packets of type command received by
+// Session, RunShellCommandAsync and RunShellBounded. Without a handler, Session treats such
+// packets as a protocol error and the other runners ignore them. The built-in stream and exit
+// types cannot be overridden; a nil handler removes the registration.
+func RegisterShellPacketHandler(command ShellPacketType, handler ShellPacketHandler) error {
+	if command <= ShellCloseStdin || command == ShellInvalid {
+		return fmt.Errorf("shell: packet type %d is handled internally", command)
+	}
+
+	shellHandlersMu.Lock()
+	defer shellHandlersMu.Unlock()
+	if handler == nil {
+		delete(shellHandlers, command)
+	} else {
+		shellHandlers[command] = handler
+	}
+	return nil
+}
+
+// handleShellPacket dispatches an unrecognised packet to its registered handler.
+// ok is false if there is none.
+func handleShellPacket(command ShellPacketType, data []byte) (ok bool, err error) {
+	shellHandlersMu.RLock()
+	handler := shellHandlers[command]
+	shellHandlersMu.RUnlock()
+	if handler == nil {
+		return false, nil
+	}
+	return true, handler(command, data)
+}
+
+// ShellConn is a raw shell v2 connection. It exchanges typed packets directly, for callers
+// that need packet types gadb has no higher-level support for.
+type ShellConn struct {
+	st shellTransport
+}
+
+// OpenShellConn starts cmd with the shell v2 protocol and returns the connection without
+// interpreting any packets. If pty is true the command runs in a pseudo-terminal, which is
+// required for ShellWindowSizeChange to have any effect.
+func (d Device) OpenShellConn(cmd string, pty ...bool) (*ShellConn, error) {
+	if strings.TrimSpace(cmd) == "" {
+		return nil, errors.New("adb shell: command cannot be empty")
+	}
+
+	mode := "raw"
+	if len(pty) != 0 && pty[0] {
+		mode = "pty"
+	}
+
+	tp, err := d.createDeviceTransport()
+	if err != nil {
+		return nil, err
+	}
+	if err = tp.Send(fmt.Sprintf("shell,v2,%s:%s", mode, cmd)); err != nil {
+		_ = tp.Close()
+		return nil, err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		_ = tp.Close()
+		return nil, err
+	}
+
+	shTp, err := tp.CreateShellTransport()
+	if err != nil {
+		_ = tp.Close()
+		return nil, err
+	}
+	return &ShellConn{st: shTp}, nil
+}
+
+// ReadPacket reads the next packet. It returns io.EOF when the device closes the connection.
+func (c *ShellConn) ReadPacket() (ShellPacketType, []byte, error) {
+	return c.st.Read()
+}
+
+// WritePacket sends a packet of type command.
+func (c *ShellConn) WritePacket(command ShellPacketType, data []byte) error {
+	return c.st.Send(command, data)
+}
+
+// Close closes the connection, terminating the remote command.
+func (c *ShellConn) Close() error {
+	return c.st.Close()
+}
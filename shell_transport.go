@@ -18,23 +18,32 @@ type shellTransport struct {
 	readTimeout time.Duration
 }
 
-// Shell protocol message types.
-type shellMessageType byte
+// ShellPacketType identifies a shell v2 protocol packet. Each packet is framed as the type
+// byte, a little-endian uint32 payload length and the payload.
+type ShellPacketType byte
 
 const (
-	shellStdin      shellMessageType = 0
-	shellStdout     shellMessageType = 1
-	shellStderr     shellMessageType = 2
-	shellExit       shellMessageType = 3
-	shellCloseStdin shellMessageType = 4
+	ShellStdin      ShellPacketType = 0
+	ShellStdout     ShellPacketType = 1
+	ShellStderr     ShellPacketType = 2
+	ShellExit       ShellPacketType = 3
+	ShellCloseStdin ShellPacketType = 4
+	// ShellWindowSizeChange carries "<rows>x<cols>,<x_pixels>x<y_pixels>" for pty sessions.
+	ShellWindowSizeChange ShellPacketType = 5
+	// ShellInvalid is returned alongside read errors.
+	ShellInvalid ShellPacketType = 255
 )
 
+// shellMaxPacketSize bounds the payload accepted by ReadShellPacket; it matches adbd's
+// maximum payload, which no shell packet exceeds.
+const shellMaxPacketSize = 1024 * 1024
+
 func newShellTransport(sock net.Conn, readTimeout time.Duration) shellTransport {
 	return shellTransport{sock: sock, readTimeout: readTimeout}
 }
 
-// Send creates and sends a packet over the shell protocol.
-func (s *shellTransport) Send(command shellMessageType, data []byte) (err error) {
+// WriteShellPacket writes a single shell v2 packet to w.
+func WriteShellPacket(w io.Writer, command ShellPacketType, data []byte) (err error) {
 	msg := new(bytes.Buffer)
 	if err := msg.WriteByte(byte(command)); err != nil {
 		return fmt.Errorf("shell transport write: %w", err)
@@ -47,29 +56,44 @@ func (s *shellTransport) Send(command shellMessageType, data []byte) (err error)
 	}
 
 	debugLog(fmt.Sprintf("--> %v", msg.Bytes()))
-	return _send(s.sock, msg.Bytes())
+	return _send(w, msg.Bytes())
 }
 
-func (s *shellTransport) Read() (command shellMessageType, data []byte, err error) {
-	err = binary.Read(s.sock, binary.LittleEndian, &command)
+// ReadShellPacket reads a single shell v2 packet from r. It returns io.EOF unwrapped
+// when the stream ends cleanly between packets.
+func ReadShellPacket(r io.Reader) (command ShellPacketType, data []byte, err error) {
+	err = binary.Read(r, binary.LittleEndian, &command)
 	if err == io.EOF {
-		return 255, nil, err
+		return ShellInvalid, nil, err
 	}
 	if err != nil {
-		return 255, nil, fmt.Errorf("failed to read response msg type: %w", err)
+		return ShellInvalid, nil, fmt.Errorf("failed to read response msg type: %w", err)
 	}
 	var msgLen uint32
-	err = binary.Read(s.sock, binary.LittleEndian, &msgLen)
+	err = binary.Read(r, binary.LittleEndian, &msgLen)
 	if err != nil {
 		return command, nil, fmt.Errorf("failed to read response msg len: %w", err)
 	}
-	data, err = s.ReadBytesN(int(msgLen))
+	if msgLen > shellMaxPacketSize {
+		return command, nil, fmt.Errorf("shell packet too large: %d bytes", msgLen)
+	}
+	data, err = _readN(r, int(msgLen))
 	if err != nil {
 		return command, data, fmt.Errorf("failed to read response msg body: %w", err)
 	}
 	return command, data, nil
 }
 
+// Send creates and sends a packet over the shell protocol.
+func (s *shellTransport) Send(command ShellPacketType, data []byte) (err error) {
+	return WriteShellPacket(s.sock, command, data)
+}
+
+func (s *shellTransport) Read() (command ShellPacketType, data []byte, err error) {
+	_ = s.sock.SetReadDeadline(time.Time{})
+	return ReadShellPacket(s.sock)
+}
+
 func (s *shellTransport) ReadBytesN(size int) (raw []byte, err error) {
 	_ = s.sock.SetReadDeadline(time.Time{})
 	return _readN(s.sock, size)
@@ -0,0 +1,86 @@
+package gadb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestShellPacket_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteShellPacket(&buf, ShellWindowSizeChange, []byte("24x80,0x0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteShellPacket(&buf, ShellCloseStdin, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	command, data, err := ReadShellPacket(&buf)
+	if err != nil || command != ShellWindowSizeChange || string(data) != "24x80,0x0" {
+		t.Fatalf("unexpected packet: %d %q %v", command, data, err)
+	}
+	if command, data, err = ReadShellPacket(&buf); err != nil || command != ShellCloseStdin || len(data) != 0 {
+		t.Fatalf("unexpected packet: %d %q %v", command, data, err)
+	}
+	if _, _, err = ReadShellPacket(&buf); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadShellPacket_tooLarge(t *testing.T) {
+	r := bytes.NewReader([]byte{byte(ShellStdout), 0xff, 0xff, 0xff, 0xff})
+	if _, _, err := ReadShellPacket(r); err == nil {
+		t.Fatal("expected an error for an oversized packet")
+	}
+}
+
+func TestRegisterShellPacketHandler(t *testing.T) {
+	if err := RegisterShellPacketHandler(ShellExit, func(ShellPacketType, []byte) error { return nil }); err == nil {
+		t.Fatal("expected built-in packet types to be rejected")
+	}
+
+	const heartbeat ShellPacketType = 6
+	errStop := errors.New("stop")
+	if err := RegisterShellPacketHandler(heartbeat, func(ShellPacketType, []byte) error { return errStop }); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = RegisterShellPacketHandler(heartbeat, nil) }()
+
+	if ok, err := handleShellPacket(heartbeat, nil); !ok || err != errStop {
+		t.Fatalf("unexpected dispatch result: %v %v", ok, err)
+	}
+	if ok, _ := handleShellPacket(heartbeat+1, nil); ok {
+		t.Fatal("unexpected handler for unregistered type")
+	}
+}
+
+func Test_copyShellOutput(t *testing.T) {
+	client, peer := net.Pipe()
+	defer client.Close()
+	go func() {
+		_ = WriteShellPacket(peer, ShellStdout, []byte("I/ActivityManager: start\n"))
+		_ = WriteShellPacket(peer, ShellStderr, []byte("read: unexpected EOF!\n"))
+		_ = WriteShellPacket(peer, ShellExit, []byte{1})
+		_ = peer.Close()
+	}()
+
+	var out bytes.Buffer
+	st := newShellTransport(client, 0)
+	err := copyShellOutput(&st, &out)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 1 {
+		t.Fatalf("expected exit status 1, got %v", err)
+	}
+	if out.String() != "I/ActivityManager: start\nread: unexpected EOF!\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	closed, closedPeer := net.Pipe()
+	_ = closedPeer.Close()
+	st = newShellTransport(closed, 0)
+	if err = copyShellOutput(&st, &out); !errors.As(err, new(*ExitMissingError)) {
+		t.Fatalf("expected ExitMissingError on disconnect, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package gadb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ErrSideloadFailed is returned by Sideload when recovery reports that installing the
+// package failed; the recovery screen or log has the reason.
+var ErrSideloadFailed = errors.New("recovery failed to install the package")
+
+// sideloadBlockSize is the block size requested by the host, as in adb's
+// SIDELOAD_HOST_BLOCK_SIZE.
+const sideloadBlockSize = 64 * 1024
+
+// SideloadProgress reports how far a sideload has got.
+type SideloadProgress struct {
+	// Sent counts the bytes served so far, Total the size of the package. Recovery reads
+	// blocks more than once, so Sent grows past Total.
+	Sent  int64
+	Total int64
+}
+
+// Percent estimates the share of the sideload done, from 0 to 100. Like adb, it expects
+// recovery to read the package about 2.13 times: once to verify it, once to install it, and
+// some more for the zip directory.
+func (p SideloadProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(float64(p.Sent)*47/float64(p.Total), 100)
+}
+
+// Sideload serves the OTA package pkg of size bytes to a device in sideload mode, as
+// `adb sideload` does, until recovery has installed it, reports failure with
+// ErrSideloadFailed, or ctx is done. Recovery requests blocks of the package as it needs
+// them over the sideload-host: service. progress, if not nil, is called after each block.
+// Reboot with RebootSideload gets a device into sideload mode.
+func (d Device) Sideload(ctx context.Context, pkg io.ReaderAt, size int64, progress func(SideloadProgress)) (err error) {
+	if size <= 0 {
+		return errors.New("adb sideload: empty package")
+	}
+
+	var tp transport
+	if tp, err = d.createDeviceTransport(); err != nil {
+		return err
+	}
+	defer func() { _ = tp.Close() }()
+	if err = tp.Send(fmt.Sprintf("sideload-host:%d:%d", size, sideloadBlockSize)); err != nil {
+		return err
+	}
+	if err = tp.VerifyResponse(); err != nil {
+		return fmt.Errorf("adb sideload: %w", err)
+	}
+	// Recovery may be busy verifying or installing for minutes between requests.
+	_ = tp.sock.SetReadDeadline(time.Time{})
+	stop := context.AfterFunc(ctx, func() { _ = tp.Close() })
+	defer stop()
+
+	if err = serveSideload(tp.sock, pkg, size, progress); err != nil && ctx.Err() != nil {
+		return fmt.Errorf("adb sideload: %w", ctx.Err())
+	}
+	return err
+}
+
+// serveSideload answers the block requests of recovery on conn: each is a block number as
+// 8 decimal digits, answered with that block of pkg, until recovery sends "DONEDONE" or
+// "FAILFAIL".
+func serveSideload(conn io.ReadWriter, pkg io.ReaderAt, size int64, progress func(SideloadProgress)) error {
+	buf := make([]byte, sideloadBlockSize)
+	var request [8]byte
+	var sent int64
+	for {
+		if _, err := io.ReadFull(conn, request[:]); err != nil {
+			return fmt.Errorf("adb sideload: read request: %w", err)
+		}
+		switch string(request[:]) {
+		case "DONEDONE":
+			return nil
+		case "FAILFAIL":
+			return fmt.Errorf("adb sideload: %w", ErrSideloadFailed)
+		}
+
+		block, err := strconv.ParseInt(string(bytes.Trim(request[:], "\x00 ")), 10, 64)
+		if err != nil {
+			return fmt.Errorf("adb sideload: unexpected request %q", request[:])
+		}
+		offset := block * sideloadBlockSize
+		if block < 0 || offset >= size {
+			return fmt.Errorf("adb sideload: block %d requested past the end of the package", block)
+		}
+		n := min(int64(sideloadBlockSize), size-offset)
+		if m, err := pkg.ReadAt(buf[:n], offset); int64(m) < n {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("adb sideload: read package: %w", err)
+		}
+		if err = _send(conn, buf[:n]); err != nil {
+			return fmt.Errorf("adb sideload: %w", err)
+		}
+		sent += n
+		if progress != nil {
+			progress(SideloadProgress{Sent: sent, Total: size})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gadb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// fakeRecovery requests blocks of a sideloaded package over conn, then sends end.
+func fakeRecovery(conn net.Conn, blocks []string, sizes []int, end string) <-chan []byte {
+	received := make(chan []byte, 1)
+	go func() {
+		defer func() { _ = conn.Close() }()
+		var got []byte
+		for i, block := range blocks {
+			if _, err := conn.Write([]byte(block)); err != nil {
+				break
+			}
+			buf := make([]byte, sizes[i])
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				break
+			}
+			got = append(got, buf...)
+		}
+		if end != "" {
+			_, _ = conn.Write([]byte(end))
+		}
+		received <- got
+	}()
+	return received
+}
+
+func Test_serveSideload(t *testing.T) {
+	pkg := bytes.Repeat([]byte("0123456789abcdef"), sideloadBlockSize/16+1)
+	size := int64(len(pkg))
+
+	host, device := net.Pipe()
+	received := fakeRecovery(device, []string{"00000001", "00000000", "00000001"}, []int{16, sideloadBlockSize, 16}, "DONEDONE")
+	var last SideloadProgress
+	if err := serveSideload(host, bytes.NewReader(pkg), size, func(p SideloadProgress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+	want := append(append(append([]byte{}, pkg[sideloadBlockSize:]...), pkg[:sideloadBlockSize]...), pkg[sideloadBlockSize:]...)
+	if got := <-received; !bytes.Equal(got, want) {
+		t.Fatalf("served %d bytes, want %d", len(got), len(want))
+	}
+	if last.Sent != sideloadBlockSize+32 || last.Total != size || last.Percent() != float64(last.Sent)*47/float64(size) {
+		t.Fatalf("unexpected progress %+v", last)
+	}
+
+	host, device = net.Pipe()
+	fakeRecovery(device, nil, nil, "FAILFAIL")
+	if err := serveSideload(host, bytes.NewReader(pkg), size, nil); !errors.Is(err, ErrSideloadFailed) {
+		t.Fatalf("got %v, want ErrSideloadFailed", err)
+	}
+
+	host, device = net.Pipe()
+	fakeRecovery(device, nil, nil, "00000009")
+	if err := serveSideload(host, bytes.NewReader(pkg), size, nil); err == nil {
+		t.Fatal("expected an error for a block past the end")
+	}
+}
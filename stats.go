@@ -0,0 +1,86 @@
+package gadb
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeviceStats is a snapshot of the activity gadb has performed against a single device
+// since the process started, across all Device values sharing the serial.
+type DeviceStats struct {
+	Commands          uint64
+	CommandErrors     uint64
+	SyncBytesSent     uint64
+	SyncBytesReceived uint64
+	SyncDuration      time.Duration
+}
+
+type deviceCounters struct {
+	commands          atomic.Uint64
+	commandErrors     atomic.Uint64
+	syncBytesSent     atomic.Uint64
+	syncBytesReceived atomic.Uint64
+	syncNanos         atomic.Int64
+}
+
+var deviceStats sync.Map // serial -> *deviceCounters
+
+func countersFor(serial string) *deviceCounters {
+	if c, ok := deviceStats.Load(serial); ok {
+		return c.(*deviceCounters)
+	}
+	c, _ := deviceStats.LoadOrStore(serial, &deviceCounters{})
+	return c.(*deviceCounters)
+}
+
+// StatsFor returns the activity counters recorded for serial.
+func StatsFor(serial string) DeviceStats {
+	c := countersFor(serial)
+	return DeviceStats{
+		Commands:          c.commands.Load(),
+		CommandErrors:     c.commandErrors.Load(),
+		SyncBytesSent:     c.syncBytesSent.Load(),
+		SyncBytesReceived: c.syncBytesReceived.Load(),
+		SyncDuration:      time.Duration(c.syncNanos.Load()),
+	}
+}
+
+func (d Device) recordCommand(err error) {
+	c := countersFor(d.serial)
+	c.commands.Add(1)
+	if err != nil {
+		c.commandErrors.Add(1)
+	}
+}
+
+func (d Device) recordSync(sent, received int64, started time.Time) {
+	c := countersFor(d.serial)
+	c.syncBytesSent.Add(uint64(sent))
+	c.syncBytesReceived.Add(uint64(received))
+	c.syncNanos.Add(int64(time.Since(started)))
+}
+
+// countingReader and countingWriter track how many bytes pass through a sync transfer.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = c.w.Write(p)
+	c.n += int64(n)
+	return
+}
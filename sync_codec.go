@@ -0,0 +1,172 @@
+package gadb
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// SyncCodec implements one of the compression algorithms understood by the sync v2
+// SND2/RCV2 requests. gadb ships no codecs so that it stays dependency-free; register
+// an implementation (e.g. backed by a zstd or lz4 package) to enable compressed transfers.
+type SyncCodec interface {
+	// Name is the codec's adb name: "brotli", "lz4" or "zstd".
+	Name() string
+	// NewWriter returns a compressor writing to w. Close must flush all pending data.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a decompressor reading from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// syncCodecFlags maps codec names to their sync v2 flag bits, in order of preference.
+var syncCodecFlags = []struct {
+	name string
+	flag uint32
+}{
+	{"zstd", 4},
+	{"lz4", 2},
+	{"brotli", 1},
+}
+
+var (
+	syncCodecsMu sync.RWMutex
+	syncCodecs   = map[string]SyncCodec{}
+)
+
+// RegisterSyncCodec makes codec available to Push and Pull. Registering a codec with the
+// same name replaces the previous one. It returns an error for names the protocol doesn't know.
+func RegisterSyncCodec(codec SyncCodec) error {
+	for _, c := range syncCodecFlags {
+		if c.name == codec.Name() {
+			syncCodecsMu.Lock()
+			defer syncCodecsMu.Unlock()
+			syncCodecs[codec.Name()] = codec
+			return nil
+		}
+	}
+	return fmt.Errorf("sync codec: unsupported compression %q", codec.Name())
+}
+
+// UnregisterSyncCodec removes the named codec, so transfers no longer use it.
+func UnregisterSyncCodec(name string) {
+	syncCodecsMu.Lock()
+	defer syncCodecsMu.Unlock()
+	delete(syncCodecs, name)
+}
+
+// syncCodec returns the preferred registered codec the device supports, or nil to use
+// the uncompressed sync v1 requests.
+func (d Device) syncCodec() (codec SyncCodec, flag uint32) {
+	syncCodecsMu.RLock()
+	empty := len(syncCodecs) == 0
+	syncCodecsMu.RUnlock()
+	if empty {
+		return nil, 0
+	}
+
+	features, err := d.Features()
+	if err != nil {
+		return nil, 0
+	}
+	supported := map[string]bool{}
+	for _, f := range features {
+		supported[f] = true
+	}
+	if !supported["sendrecv_v2"] {
+		return nil, 0
+	}
+
+	syncCodecsMu.RLock()
+	defer syncCodecsMu.RUnlock()
+	for _, c := range syncCodecFlags {
+		if codec, ok := syncCodecs[c.name]; ok && supported["sendrecv_v2_"+c.name] {
+			return codec, c.flag
+		}
+	}
+	return nil, 0
+}
+
+// syncChunkWriter splits whatever is written to it into sync DATA chunks.
+type syncChunkWriter struct {
+	sync syncTransport
+}
+
+func (w syncChunkWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p[:min(len(p), syncMaxChunkSize)]
+		if err = w.sync.sendChunk(chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return
+}
+
+// syncChunkReader reads the payload of incoming sync DATA chunks until DONE.
+type syncChunkReader struct {
+	sync syncTransport
+	buf  []byte
+	done bool
+}
+
+func (r *syncChunkReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.buf, err = r.sync.readChunk(); err == syncReadChunkDone {
+			r.done, err = true, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("sync read chunk: %w", err)
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return
+}
+
+// pushCompressed sends source with SND2, compressing it with codec.
+func (sync syncTransport) pushCompressed(source io.Reader, remotePath string, mode uint32, codec SyncCodec, flag uint32) (err error) {
+	if err = sync.Send("SND2", remotePath); err != nil {
+		return err
+	}
+	if err = sync.sendV2Setup("SND2", mode, flag); err != nil {
+		return err
+	}
+
+	var zw io.WriteCloser
+	if zw, err = codec.NewWriter(syncChunkWriter{sync: sync}); err != nil {
+		return fmt.Errorf("sync %s: %w", codec.Name(), err)
+	}
+	if _, err = io.Copy(zw, source); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("sync %s: %w", codec.Name(), err)
+	}
+	return nil
+}
+
+// pullCompressed receives remotePath with RCV2 and decompresses it into dest with codec.
+func (sync syncTransport) pullCompressed(remotePath string, dest io.Writer, codec SyncCodec, flag uint32) (err error) {
+	if err = sync.Send("RCV2", remotePath); err != nil {
+		return err
+	}
+	if err = sync.sendV2Setup("RCV2", 0, flag); err != nil {
+		return err
+	}
+
+	var zr io.ReadCloser
+	if zr, err = codec.NewReader(&syncChunkReader{sync: sync}); err != nil {
+		return fmt.Errorf("sync %s: %w", codec.Name(), err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	if _, err = io.Copy(dest, zr); err != nil {
+		return fmt.Errorf("sync write stream: %w", err)
+	}
+	return nil
+}
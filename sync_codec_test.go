@@ -0,0 +1,56 @@
+package gadb
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+type nopCodec struct{ name string }
+
+func (c nopCodec) Name() string { return c.name }
+
+func (c nopCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return struct {
+		io.Writer
+		io.Closer
+	}{w, io.NopCloser(nil)}, nil
+}
+
+func (c nopCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+
+func TestRegisterSyncCodec(t *testing.T) {
+	if err := RegisterSyncCodec(nopCodec{"gzip"}); err == nil {
+		t.Fatal("expected error for unsupported codec")
+	}
+	if err := RegisterSyncCodec(nopCodec{"zstd"}); err != nil {
+		t.Fatal(err)
+	}
+	UnregisterSyncCodec("zstd")
+	if len(syncCodecs) != 0 {
+		t.Fatalf("expected empty registry, got %v", syncCodecs)
+	}
+}
+
+func Test_syncChunkWriter_syncChunkReader(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	payload := bytes.Repeat([]byte("0123456789"), syncMaxChunkSize/5)
+	go func() {
+		w := newSyncTransport(client, time.Second)
+		_, _ = syncChunkWriter{sync: w}.Write(payload)
+		_ = w.SendStatus("DONE", 0)
+	}()
+
+	got, err := io.ReadAll(&syncChunkReader{sync: newSyncTransport(server, time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("payload mismatch: got %d bytes, want %d", len(got), len(payload))
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"time"
 )
 
 type syncTransport struct {
 	sock        net.Conn
 	readTimeout time.Duration
+	transcript  *transcript
 }
 
 func newSyncTransport(sock net.Conn, readTimeout time.Duration) syncTransport {
@@ -30,11 +32,14 @@ func (sync syncTransport) Send(command, data string) (err error) {
 	msg.WriteString(data)
 
 	debugLog(fmt.Sprintf("--> %s", msg.String()))
+	sync.transcript.record(">", command+" "+data)
 	return _send(sync.sock, msg.Bytes())
 }
 
+// syncMaxChunkSize is the largest payload adbd accepts in a single DATA chunk.
+const syncMaxChunkSize = 64 * 1024
+
 func (sync syncTransport) SendStream(reader io.Reader) (err error) {
-	syncMaxChunkSize := 64 * 1024
 	for err == nil {
 		tmp := make([]byte, syncMaxChunkSize)
 		var n int
@@ -60,6 +65,20 @@ func (sync syncTransport) SendStatus(statusCode string, n uint32) (err error) {
 	return _send(sync.sock, msg.Bytes())
 }
 
+// sendV2Setup sends the setup message following a SND2 (id, mode, flags) or RCV2 (id, flags) request.
+func (sync syncTransport) sendV2Setup(id string, mode, flags uint32) (err error) {
+	msg := bytes.NewBufferString(id)
+	fields := []uint32{mode, flags}
+	if id == "RCV2" {
+		fields = fields[1:]
+	}
+	if err = binary.Write(msg, binary.LittleEndian, fields); err != nil {
+		return fmt.Errorf("sync transport write: %w", err)
+	}
+	debugLog(fmt.Sprintf("--> %s %v", id, fields))
+	return _send(sync.sock, msg.Bytes())
+}
+
 func (sync syncTransport) sendChunk(buffer []byte) (err error) {
 	msg := bytes.NewBufferString("DATA")
 	if err = binary.Write(msg, binary.LittleEndian, int32(len(buffer))); err != nil {
@@ -226,6 +245,107 @@ func (sync syncTransport) ReadDirectoryEntry() (entry DeviceFileInfo, err error)
 	return
 }
 
+func (sync syncTransport) ReadStat() (entry DeviceFileInfo, err error) {
+	var status string
+	if status, err = sync.ReadStringN(4); err != nil {
+		return DeviceFileInfo{}, err
+	}
+	if status != "STAT" {
+		return DeviceFileInfo{}, fmt.Errorf("sync transport read (stat): unexpected status %s", status)
+	}
+
+	if err = binary.Read(sync.sock, binary.LittleEndian, &entry.Mode); err != nil {
+		return DeviceFileInfo{}, fmt.Errorf("sync transport read (mode): %w", err)
+	}
+	if entry.Size, err = sync.ReadUint32(); err != nil {
+		return DeviceFileInfo{}, fmt.Errorf("sync transport read (size): %w", err)
+	}
+	var tmpUint32 uint32
+	if tmpUint32, err = sync.ReadUint32(); err != nil {
+		return DeviceFileInfo{}, fmt.Errorf("sync transport read (time): %w", err)
+	}
+	entry.LastModified = time.Unix(int64(tmpUint32), 0)
+
+	debugLog(fmt.Sprintf("<-- %s\t%s\t%10d\t%s", status, entry.Mode.String(), entry.Size, entry.LastModified.String()))
+	return
+}
+
+// syncStatV2 is the fixed-size body shared by STA2/LST2 responses and DNT2 entries.
+type syncStatV2 struct {
+	Error uint32
+	Dev   uint64
+	Ino   uint64
+	Mode  uint32
+	Nlink uint32
+	UID   uint32
+	GID   uint32
+	Size  uint64
+	Atime int64
+	Mtime int64
+	Ctime int64
+}
+
+func (st syncStatV2) fileInfo(name string) DeviceFileInfoV2 {
+	return DeviceFileInfoV2{
+		Name:         name,
+		Mode:         os.FileMode(st.Mode),
+		Size:         st.Size,
+		UID:          st.UID,
+		GID:          st.GID,
+		Device:       st.Dev,
+		Inode:        st.Ino,
+		Links:        st.Nlink,
+		LastAccessed: time.Unix(st.Atime, 0),
+		LastModified: time.Unix(st.Mtime, 0),
+		LastChanged:  time.Unix(st.Ctime, 0),
+	}
+}
+
+func (sync syncTransport) ReadStatV2() (st syncStatV2, err error) {
+	var status string
+	if status, err = sync.ReadStringN(4); err != nil {
+		return syncStatV2{}, err
+	}
+	if status != "STA2" && status != "LST2" {
+		return syncStatV2{}, fmt.Errorf("sync transport read (stat v2): unexpected status %s", status)
+	}
+	if err = binary.Read(sync.sock, binary.LittleEndian, &st); err != nil {
+		return syncStatV2{}, fmt.Errorf("sync transport read (stat v2): %w", err)
+	}
+	debugLog(fmt.Sprintf("<-- %s\t%d\t%o\t%10d\t%d", status, st.Error, st.Mode, st.Size, st.Mtime))
+	return
+}
+
+// ReadDirectoryEntryV2 reads one LIS2 entry. It returns done once the listing is complete.
+func (sync syncTransport) ReadDirectoryEntryV2() (entry DeviceFileInfoV2, done bool, err error) {
+	var status string
+	if status, err = sync.ReadStringN(4); err != nil {
+		return DeviceFileInfoV2{}, false, err
+	}
+	if status == "DONE" {
+		debugLog(fmt.Sprintf("<-- %s", status))
+		return DeviceFileInfoV2{}, true, nil
+	}
+	if status != "DNT2" {
+		return DeviceFileInfoV2{}, false, fmt.Errorf("sync transport read (list v2): unexpected status %s", status)
+	}
+
+	var st syncStatV2
+	if err = binary.Read(sync.sock, binary.LittleEndian, &st); err != nil {
+		return DeviceFileInfoV2{}, false, fmt.Errorf("sync transport read (list v2): %w", err)
+	}
+	var nameLen uint32
+	if nameLen, err = sync.ReadUint32(); err != nil {
+		return DeviceFileInfoV2{}, false, fmt.Errorf("sync transport read (file name length): %w", err)
+	}
+	var name string
+	if name, err = sync.ReadStringN(int(nameLen)); err != nil {
+		return DeviceFileInfoV2{}, false, fmt.Errorf("sync transport read (file name): %w", err)
+	}
+	debugLog(fmt.Sprintf("<-- %s\t%o\t%10d\t%d\t%s", status, st.Mode, st.Size, st.Mtime, name))
+	return st.fileInfo(name), false, nil
+}
+
 func (sync syncTransport) ReadUint32() (n uint32, err error) {
 	err = binary.Read(sync.sock, binary.LittleEndian, &n)
 	return
@@ -0,0 +1,159 @@
+package gadb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ThermalSeverity is a thermal status, as in android.os.PowerManager's THERMAL_STATUS_*.
+type ThermalSeverity int
+
+const (
+	ThermalNone ThermalSeverity = iota
+	ThermalLight
+	ThermalModerate
+	ThermalSevere
+	ThermalCritical
+	ThermalEmergency
+	ThermalShutdown
+)
+
+var thermalSeverityNames = []string{"none", "light", "moderate", "severe", "critical", "emergency", "shutdown"}
+
+// String returns a name such as "moderate".
+func (s ThermalSeverity) String() string {
+	if s < ThermalNone || s > ThermalShutdown {
+		return strconv.Itoa(int(s))
+	}
+	return thermalSeverityNames[s]
+}
+
+// TemperatureType is the kind of a temperature sensor, as in android.os.Temperature's TYPE_*.
+type TemperatureType int
+
+const (
+	TemperatureUnknown TemperatureType = -1 + iota
+	TemperatureCPU
+	TemperatureGPU
+	TemperatureBattery
+	TemperatureSkin
+	TemperatureUSBPort
+	TemperaturePowerAmplifier
+	TemperatureBCLVoltage
+	TemperatureBCLCurrent
+	TemperatureBCLPercentage
+	TemperatureNPU
+)
+
+var temperatureTypeNames = []string{"unknown", "cpu", "gpu", "battery", "skin", "usb port", "power amplifier", "bcl voltage", "bcl current", "bcl percentage", "npu"}
+
+// String returns a name such as "skin".
+func (t TemperatureType) String() string {
+	if t < TemperatureUnknown || t > TemperatureNPU {
+		return strconv.Itoa(int(t))
+	}
+	return temperatureTypeNames[t+1]
+}
+
+// Temperature is the reading of one temperature sensor.
+type Temperature struct {
+	Name    string
+	Type    TemperatureType
+	Celsius float64
+	// Status is the severity the thermal HAL assigns to the reading.
+	Status ThermalSeverity
+}
+
+// ThermalStatus is the thermal state of the device.
+type ThermalStatus struct {
+	// Status is the overall status, which apps see through PowerManager.getCurrentThermalStatus.
+	Status       ThermalSeverity
+	Temperatures []Temperature
+}
+
+// Throttled reports whether the device is throttling, however lightly; performance
+// measurements taken meanwhile aren't comparable with others.
+func (s ThermalStatus) Throttled() bool {
+	return s.Status >= ThermalLight
+}
+
+// ThermalStatus returns the thermal status and the current temperatures reported by the
+// thermal HAL, from `dumpsys thermalservice`. Releases before Android 10 have no thermal
+// service; there it fails with ErrServiceNotFound.
+func (d Device) ThermalStatus() (ThermalStatus, error) {
+	output, err := d.Dumpsys("thermalservice")
+	if err != nil {
+		return ThermalStatus{}, err
+	}
+	return parseThermalStatus(output)
+}
+
+// parseThermalStatus parses dumpsys thermalservice output:
+//
+//	Thermal Status: 1
+//	Cached temperatures:
+//		Temperature{mValue=38.5, mType=0, mName=cpu0, mStatus=0}
+//	HAL Ready: true
+//	Current temperatures from HAL:
+//		Temperature{mValue=39.0, mType=0, mName=cpu0, mStatus=1}
+//
+// The current temperatures are preferred over the cached ones, which are only those that
+// were reported with a status change.
+func parseThermalStatus(output string) (status ThermalStatus, err error) {
+	found := false
+	var cached, current []Temperature
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Thermal Status: "):
+			n, err := strconv.Atoi(strings.TrimPrefix(line, "Thermal Status: "))
+			if err != nil {
+				return ThermalStatus{}, errors.New("adb dumpsys thermalservice: unexpected status " + line)
+			}
+			status.Status, found = ThermalSeverity(n), true
+		case strings.HasSuffix(line, ":"):
+			section = line
+		case strings.HasPrefix(line, "Temperature{"):
+			t := parseTemperature(strings.TrimSuffix(strings.TrimPrefix(line, "Temperature{"), "}"))
+			switch section {
+			case "Cached temperatures:":
+				cached = append(cached, t)
+			case "Current temperatures from HAL:":
+				current = append(current, t)
+			}
+		}
+	}
+	if !found {
+		return ThermalStatus{}, errors.New("adb dumpsys thermalservice: thermal status not found")
+	}
+	status.Temperatures = current
+	if len(current) == 0 {
+		status.Temperatures = cached
+	}
+	return status, nil
+}
+
+// parseTemperature parses the fields of android.os.Temperature.toString, such as
+// "mValue=38.5, mType=0, mName=cpu0, mStatus=0".
+func parseTemperature(fields string) (t Temperature) {
+	t.Type = TemperatureUnknown
+	for _, field := range strings.Split(fields, ", ") {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "mValue":
+			t.Celsius, _ = strconv.ParseFloat(value, 64)
+		case "mType":
+			if n, err := strconv.Atoi(value); err == nil {
+				t.Type = TemperatureType(n)
+			}
+		case "mName":
+			t.Name = value
+		case "mStatus":
+			n, _ := strconv.Atoi(value)
+			t.Status = ThermalSeverity(n)
+		}
+	}
+	return t
+}
@@ -0,0 +1,51 @@
+package gadb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseThermalStatus(t *testing.T) {
+	output := `IsStatusOverride: false
+ThermalEventListeners:
+	callbacks: 1
+	killed: false
+Thermal Status: 2
+Cached temperatures:
+	Temperature{mValue=45.0, mType=3, mName=skin, mStatus=2}
+HAL Ready: true
+HAL connection:
+	ThermalHAL 2.0 connected: yes
+Current temperatures from HAL:
+	Temperature{mValue=46.5, mType=3, mName=skin, mStatus=2}
+	Temperature{mValue=31.2, mType=2, mName=battery, mStatus=0}
+Current cooling devices from HAL:
+	CoolingDevice{mValue=0, mType=0, mName=fan}
+`
+	status, err := parseThermalStatus(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ThermalStatus{
+		Status: ThermalModerate,
+		Temperatures: []Temperature{
+			{Name: "skin", Type: TemperatureSkin, Celsius: 46.5, Status: ThermalModerate},
+			{Name: "battery", Type: TemperatureBattery, Celsius: 31.2, Status: ThermalNone},
+		},
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Fatalf("got %+v, want %+v", status, want)
+	}
+	if !status.Throttled() || status.Status.String() != "moderate" || TemperatureUnknown.String() != "unknown" {
+		t.Fatal("unexpected status helpers")
+	}
+
+	status, err = parseThermalStatus("Thermal Status: 0\nCached temperatures:\n\tTemperature{mValue=30.0, mType=0, mName=cpu0, mStatus=0}\nHAL Ready: false\n")
+	if err != nil || status.Throttled() || len(status.Temperatures) != 1 || status.Temperatures[0].Type != TemperatureCPU {
+		t.Fatalf("unexpected status %+v, %v", status, err)
+	}
+
+	if _, err = parseThermalStatus("HAL Ready: false\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,52 @@
+package gadb
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// transcriptMaxPayload bounds how much of each request a transcript line shows.
+const transcriptMaxPayload = 512
+
+// transcript writes one line per request gadb sends on behalf of a device.
+type transcript struct {
+	mu     sync.Mutex
+	w      io.Writer
+	serial string
+}
+
+// WithTranscript returns a copy of the device that writes a human-readable line to w for
+// every adb service request it issues, shell and sync commands included, and for every
+// failure the server reports:
+//
+//	2026-01-02T15:04:05.123Z emulator-5554 > "host:transport:emulator-5554"
+//	2026-01-02T15:04:05.125Z emulator-5554 > "shell,v2,raw:pm list packages -3"
+//
+// Payloads longer than 512 bytes are truncated. Lines from this device are written one at a
+// time; a w shared between devices must be safe for concurrent use. A nil w disables the
+// transcript.
+func (d Device) WithTranscript(w io.Writer) Device {
+	if w == nil {
+		d.adbClient.transcript = nil
+		return d
+	}
+	d.adbClient.transcript = &transcript{w: w, serial: d.serial}
+	return d
+}
+
+func (t *transcript) record(direction, msg string) {
+	if t == nil {
+		return
+	}
+	payload := strconv.Quote(msg)
+	if len(msg) > transcriptMaxPayload {
+		payload = fmt.Sprintf("%s... (%d bytes)", strconv.Quote(msg[:transcriptMaxPayload]), len(msg))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = fmt.Fprintf(t.w, "%s %s %s %s\n", time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"), t.serial, direction, payload)
+}
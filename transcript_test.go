@@ -0,0 +1,41 @@
+package gadb
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func Test_transcript(t *testing.T) {
+	var buf bytes.Buffer
+	d := Device{serial: "emulator-5554"}.WithTranscript(&buf)
+
+	client, peer := net.Pipe()
+	defer client.Close()
+	go func() { _, _ = io.Copy(io.Discard, peer) }()
+
+	tp := transport{sock: client, transcript: d.adbClient.transcript}
+	if err := tp.Send("shell:echo 'hi'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.Send("shell:" + strings.Repeat("x", 600)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected transcript: %q", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], ` emulator-5554 > "shell:echo 'hi'"`) {
+		t.Fatalf("unexpected line: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], `... (606 bytes)`) {
+		t.Fatalf("expected a truncated line, got: %s", lines[1])
+	}
+
+	if d.WithTranscript(nil).adbClient.transcript != nil {
+		t.Fatal("expected the transcript to be disabled")
+	}
+}
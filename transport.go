@@ -17,6 +17,7 @@ var DefaultAdbReadTimeout time.Duration = 60 * time.Second
 type transport struct {
 	sock        net.Conn
 	readTimeout time.Duration
+	transcript  *transcript
 }
 
 func newTransport(address string, readTimeout ...time.Duration) (tp transport, err error) {
@@ -33,28 +34,82 @@ func newTransport(address string, readTimeout ...time.Duration) (tp transport, e
 func (t transport) Send(command string) (err error) {
 	msg := fmt.Sprintf("%04x%s", len(command), command)
 	debugLog(fmt.Sprintf("--> %s", command))
+	t.transcript.record(">", command)
 	return _send(t.sock, []byte(msg))
 }
 
+// ProtocolError reports data from the adb server that does not follow the host protocol,
+// such as an unknown status word, a malformed length prefix or a truncated message.
+type ProtocolError struct {
+	// Op is the part of the response being read: "status", "length" or "message".
+	Op string
+	// Data holds the bytes received for that part, possibly partial.
+	Data []byte
+	// Err is the underlying read error, if any.
+	Err error
+}
+
+func (e *ProtocolError) Error() string {
+	msg := fmt.Sprintf("adb protocol: malformed %s %q", e.Op, e.Data)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ProtocolError) Unwrap() error { return e.Err }
+
+// VerifyResponse reads the OKAY/FAIL status of the previous request. Every read is bounded by
+// the transport's read timeout, and a FAIL message is at most 0xffff bytes because of its
+// 4-digit length prefix, so a misbehaving server can neither hang nor exhaust the client.
 func (t transport) VerifyResponse() (err error) {
-	var status string
-	if status, err = t.ReadStringN(4); err != nil {
-		return err
+	var status []byte
+	if status, err = t.readPartial(4); err != nil {
+		if len(status) == 0 {
+			return err
+		}
+		return &ProtocolError{Op: "status", Data: status, Err: err}
 	}
-	if status == "OKAY" {
+	switch string(status) {
+	case "OKAY":
 		debugLog(fmt.Sprintf("<-- %s", status))
 		return nil
+	case "FAIL":
+	default:
+		return &ProtocolError{Op: "status", Data: status}
 	}
 
-	var sError string
-	if sError, err = t.UnpackString(); err != nil {
+	var sError []byte
+	if sError, err = t.UnpackBytes(); err != nil {
 		return err
 	}
 	err = fmt.Errorf("command failed: %s", sError)
 	debugLog(fmt.Sprintf("<-- %s %s", status, sError))
+	t.transcript.record("<", fmt.Sprintf("FAIL %s", sError))
 	return
 }
 
+// readPartial reads exactly size bytes under the read deadline. On failure it returns
+// whatever was received before the error.
+func (t transport) readPartial(size int) (raw []byte, err error) {
+	t.setReadDeadline()
+	raw = make([]byte, size)
+	var n int
+	if n, err = io.ReadFull(t.sock, raw); err != nil {
+		return raw[:n], err
+	}
+	return raw, nil
+}
+
+// setReadDeadline arms the read timeout; a non-positive timeout disables it.
+func (t transport) setReadDeadline() {
+	if t.readTimeout > 0 {
+		_ = t.sock.SetReadDeadline(time.Now().Add(t.readTimeout))
+	} else {
+		_ = t.sock.SetReadDeadline(time.Time{})
+	}
+}
+
 func (t transport) ReadStringAll() (s string, err error) {
 	var raw []byte
 	raw, err = t.ReadBytesAll()
@@ -74,16 +129,18 @@ func (t transport) UnpackString() (s string, err error) {
 }
 
 func (t transport) UnpackBytes() (raw []byte, err error) {
-	var length string
-	if length, err = t.ReadStringN(4); err != nil {
-		return nil, err
+	var length []byte
+	if length, err = t.readPartial(4); err != nil {
+		return nil, &ProtocolError{Op: "length", Data: length, Err: err}
 	}
-	var size int64
-	if size, err = strconv.ParseInt(length, 16, 64); err != nil {
-		return nil, err
+	var size uint64
+	if size, err = strconv.ParseUint(string(length), 16, 16); err != nil {
+		return nil, &ProtocolError{Op: "length", Data: length}
 	}
 
-	raw, err = t.ReadBytesN(int(size))
+	if raw, err = t.readPartial(int(size)); err != nil {
+		return nil, &ProtocolError{Op: "message", Data: raw, Err: err}
+	}
 	debugLog(fmt.Sprintf("\r%s", raw))
 	return
 }
@@ -97,7 +154,7 @@ func (t transport) ReadStringN(size int) (s string, err error) {
 }
 
 func (t transport) ReadBytesN(size int) (raw []byte, err error) {
-	_ = t.sock.SetReadDeadline(time.Now().Add(t.readTimeout))
+	t.setReadDeadline()
 	return _readN(t.sock, size)
 }
 
@@ -116,6 +173,7 @@ func (t transport) CreateSyncTransport() (sTp syncTransport, err error) {
 		return syncTransport{}, err
 	}
 	sTp = newSyncTransport(t.sock, t.readTimeout)
+	sTp.transcript = t.transcript
 	return
 }
 
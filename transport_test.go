@@ -1,7 +1,10 @@
 package gadb
 
 import (
+	"errors"
+	"net"
 	"testing"
+	"time"
 )
 
 func Test_transport_VerifyResponse(t *testing.T) {
@@ -24,3 +27,55 @@ func Test_transport_VerifyResponse(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// pipeTransport returns a transport whose peer writes server and then closes.
+func pipeTransport(server string, timeout time.Duration) transport {
+	client, peer := net.Pipe()
+	go func() {
+		_, _ = peer.Write([]byte(server))
+		if timeout == 0 {
+			_ = peer.Close()
+		}
+	}()
+	return transport{sock: client, readTimeout: timeout}
+}
+
+func Test_transport_VerifyResponse_malformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		op     string
+	}{
+		{"unknown status", "OKEY", "status"},
+		{"truncated status", "OK", "status"},
+		{"bad length", "FAIL-001", "length"},
+		{"truncated message", "FAIL000bdevice", "message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := pipeTransport(tt.server, 0)
+			defer tp.Close()
+
+			var protoErr *ProtocolError
+			if err := tp.VerifyResponse(); !errors.As(err, &protoErr) || protoErr.Op != tt.op {
+				t.Fatalf("expected ProtocolError for %s, got %v", tt.op, err)
+			}
+		})
+	}
+
+	tp := pipeTransport("FAIL0006denied", 0)
+	if err := tp.VerifyResponse(); err == nil || err.Error() != "command failed: denied" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func Test_transport_VerifyResponse_timeout(t *testing.T) {
+	tp := pipeTransport("FAIL00ff", 50*time.Millisecond)
+	defer tp.Close()
+
+	err := tp.VerifyResponse()
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package gadb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// AndroidVersion is the Android release a device runs, from the ro.build.version.*
+// properties.
+type AndroidVersion struct {
+	// Release is the user-visible version, such as "14" or "8.1.0".
+	Release string
+	// SDK is the API level.
+	SDK int
+	// Codename is "REL" for releases, and the name of the next release, such as
+	// "VanillaIceCream", for previews.
+	Codename string
+	// PreviewSDK is the preview revision, 0 for releases.
+	PreviewSDK int
+	// SecurityPatch is the security patch level, such as "2024-05-05".
+	SecurityPatch string
+}
+
+// Preview reports whether the device runs a preview of the next release.
+func (v AndroidVersion) Preview() bool {
+	return v.Codename != "" && v.Codename != "REL"
+}
+
+// FeatureLevel is the API level whose features the device has: SDK, or for previews, which
+// report the API level of the release they are based on, the next one.
+func (v AndroidVersion) FeatureLevel() int {
+	if v.Preview() {
+		return v.SDK + 1
+	}
+	return v.SDK
+}
+
+// String returns a description such as "Android 14 (API 34)" or
+// "Android VanillaIceCream preview 1 (API 34)".
+func (v AndroidVersion) String() string {
+	if v.Preview() {
+		return "Android " + v.Codename + " preview " + strconv.Itoa(v.PreviewSDK) + " (API " + strconv.Itoa(v.SDK) + ")"
+	}
+	return "Android " + v.Release + " (API " + strconv.Itoa(v.SDK) + ")"
+}
+
+// versionProps are the properties read by AndroidVersion, in the order of parseVersion.
+var versionProps = []string{
+	"ro.build.version.release",
+	"ro.build.version.sdk",
+	"ro.build.version.codename",
+	"ro.build.version.preview_sdk",
+	"ro.build.version.security_patch",
+}
+
+// AndroidVersion returns the Android release of the device, read in one shell call. With
+// WithCache it is read once per CacheVersion lifetime.
+func (d Device) AndroidVersion() (AndroidVersion, error) {
+	return cached(d, CacheVersion, func() (AndroidVersion, error) {
+		cmds := make([]string, len(versionProps))
+		for i, prop := range versionProps {
+			cmds[i] = "getprop " + prop
+		}
+		output, err := d.RunShellCommand(strings.Join(cmds, "; "))
+		if err != nil {
+			return AndroidVersion{}, err
+		}
+		return parseVersion(output)
+	})
+}
+
+// SdkVersion returns the API level of the device, ro.build.version.sdk. Previews report the
+// level of the release they are based on; see AndroidVersion.FeatureLevel.
+func (d Device) SdkVersion() (int, error) {
+	v, err := d.AndroidVersion()
+	return v.SDK, err
+}
+
+// parseVersion parses the values of versionProps, one per line.
+func parseVersion(output string) (AndroidVersion, error) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if len(lines) < len(versionProps) {
+		return AndroidVersion{}, errors.New("adb: unexpected ro.build.version output " + strings.TrimSpace(output))
+	}
+	sdk, err := strconv.Atoi(strings.TrimSpace(lines[1]))
+	if err != nil || sdk == 0 {
+		return AndroidVersion{}, errors.New("adb: can't read ro.build.version.sdk")
+	}
+	v := AndroidVersion{
+		Release:       strings.TrimSpace(lines[0]),
+		SDK:           sdk,
+		Codename:      strings.TrimSpace(lines[2]),
+		SecurityPatch: strings.TrimSpace(lines[4]),
+	}
+	v.PreviewSDK, _ = strconv.Atoi(strings.TrimSpace(lines[3]))
+	return v, nil
+}
@@ -0,0 +1,37 @@
+package gadb
+
+import "testing"
+
+func Test_parseVersion(t *testing.T) {
+	v, err := parseVersion("14\r\n34\r\nREL\r\n0\r\n2024-05-05\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AndroidVersion{Release: "14", SDK: 34, Codename: "REL", SecurityPatch: "2024-05-05"}
+	if v != want {
+		t.Fatalf("got %+v, want %+v", v, want)
+	}
+	if v.Preview() || v.FeatureLevel() != 34 || v.String() != "Android 14 (API 34)" {
+		t.Fatalf("unexpected release %v, feature level %d", v, v.FeatureLevel())
+	}
+
+	v, err = parseVersion("VanillaIceCream\n34\nVanillaIceCream\n1\n2024-03-05\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Preview() || v.FeatureLevel() != 35 || v.String() != "Android VanillaIceCream preview 1 (API 34)" {
+		t.Fatalf("unexpected preview %v, feature level %d", v, v.FeatureLevel())
+	}
+
+	// Old releases have no preview_sdk or security_patch.
+	v, err = parseVersion("5.1.1\n22\nREL\n\n\n")
+	if err != nil || v.SDK != 22 || v.PreviewSDK != 0 {
+		t.Fatalf("unexpected version %+v, %v", v, err)
+	}
+
+	for _, output := range []string{"", "14\n\nREL\n0\n\n", "/system/bin/sh: getprop: not found\n"} {
+		if _, err := parseVersion(output); err == nil {
+			t.Fatalf("%q: expected an error", output)
+		}
+	}
+}
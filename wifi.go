@@ -0,0 +1,181 @@
+package gadb
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WifiSecurity is the security type of a Wi-Fi network, as accepted by `cmd wifi connect-network`.
+type WifiSecurity string
+
+const (
+	WifiOpen WifiSecurity = "open"
+	WifiOWE  WifiSecurity = "owe"
+	WifiWPA2 WifiSecurity = "wpa2"
+	WifiWPA3 WifiSecurity = "wpa3"
+)
+
+// sdkCmdWifi is the first API level (Android 11) with `cmd wifi connect-network`.
+const sdkCmdWifi = 30
+
+// wifiInterface is the Wi-Fi interface of nearly every device.
+const wifiInterface = "wlan0"
+
+// Wifi controls the device's Wi-Fi. Obtain one with Device.Wifi.
+type Wifi struct {
+	d Device
+}
+
+// Wifi returns the Wi-Fi controls of the device.
+func (d Device) Wifi() Wifi {
+	return Wifi{d: d}
+}
+
+// WifiInfo is the state of the device's Wi-Fi.
+type WifiInfo struct {
+	Enabled bool
+	// Connected reports whether the device is associated with a network; the other fields
+	// are only set then.
+	Connected bool
+	SSID      string
+	BSSID     string
+	// RSSI is the signal strength in dBm.
+	RSSI int
+	// IP is the IPv4 address of the Wi-Fi interface, if it has one.
+	IP netip.Addr
+}
+
+// Enable turns Wi-Fi on with `svc wifi enable`. It returns before the device has connected.
+func (w Wifi) Enable() error {
+	return w.svc("enable")
+}
+
+// Disable turns Wi-Fi off with `svc wifi disable`.
+func (w Wifi) Disable() error {
+	return w.svc("disable")
+}
+
+func (w Wifi) svc(cmd string) error {
+	output, err := w.d.RunShellCommand("svc wifi " + cmd)
+	if err != nil {
+		return err
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return fmt.Errorf("adb svc wifi %s: %s", cmd, output)
+	}
+	return nil
+}
+
+// Info returns whether Wi-Fi is on, and the network it is connected to, from
+// `dumpsys wifi`. The IP address is taken from the wlan0 interface when dumpsys doesn't
+// report it.
+func (w Wifi) Info() (WifiInfo, error) {
+	output, err := w.d.Dumpsys("wifi")
+	if err != nil {
+		return WifiInfo{}, err
+	}
+	info, err := parseWifiInfo(output)
+	if err != nil || !info.Connected || info.IP.IsValid() {
+		return info, err
+	}
+	interfaces, err := w.d.NetworkInterfaces()
+	if err != nil {
+		return WifiInfo{}, err
+	}
+	for _, iface := range interfaces {
+		if iface.Name != wifiInterface {
+			continue
+		}
+		for _, prefix := range iface.Addresses {
+			if prefix.Addr().Is4() {
+				info.IP = prefix.Addr()
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// Connect connects to the network ssid, saving it, with `cmd wifi connect-network`.
+// passphrase is ignored for open and OWE networks. It returns once the connection is
+// initiated; poll Info to wait for it. It needs Android 11; earlier releases have no
+// shell command to add a network.
+func (w Wifi) Connect(ssid string, security WifiSecurity, passphrase string) error {
+	sdk, err := w.d.SdkVersion()
+	if err != nil {
+		return err
+	}
+	if sdk < sdkCmdWifi {
+		return fmt.Errorf("adb wifi connect: requires Android 11, device has API level %d", sdk)
+	}
+	cmd := "cmd wifi connect-network " + shellQuote(ssid) + " " + shellQuote(string(security))
+	if security != WifiOpen && security != WifiOWE {
+		cmd += " " + shellQuote(passphrase)
+	}
+	output, err := w.d.RunShellCommand(cmd)
+	if err != nil {
+		return err
+	}
+	// cmd wifi reports "Connection initiated" on success, and a usage or error message
+	// otherwise.
+	if output = strings.TrimSpace(output); output != "" && !strings.HasPrefix(output, "Connection initiated") {
+		return fmt.Errorf("adb wifi connect %s: %s", ssid, output)
+	}
+	return nil
+}
+
+// wifiInfoFieldRe matches the fields after the SSID of a WifiInfo dump; the SSID itself may
+// contain anything.
+var wifiInfoFieldRe = regexp.MustCompile(`, (BSSID|IP|RSSI|Supplicant state): ([^,]*)`)
+
+// parseWifiInfo parses dumpsys wifi output:
+//
+//	Wi-Fi is enabled
+//	...
+//	mWifiInfo SSID: "Office", BSSID: aa:bb:cc:dd:ee:ff, MAC: 02:00:00:00:00:00, IP: /192.168.1.23, Supplicant state: COMPLETED, RSSI: -55, Link speed: 433Mbps, ...
+//
+// Releases before Android 10 print the SSID without quotes, and some omit the IP.
+func parseWifiInfo(output string) (WifiInfo, error) {
+	var info WifiInfo
+	found, seenInfo := false, false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "Wi-Fi is enabled":
+			info.Enabled, found = true, true
+		case line == "Wi-Fi is disabled":
+			found = true
+		case strings.HasPrefix(line, "mWifiInfo SSID: ") && !seenInfo:
+			// Later mWifiInfo lines are of other client modes or history.
+			seenInfo = true
+			ssid, fields, _ := strings.Cut(strings.TrimPrefix(line, "mWifiInfo SSID: "), ", BSSID: ")
+			state := ""
+			for _, m := range wifiInfoFieldRe.FindAllStringSubmatch(", BSSID: "+fields, -1) {
+				switch m[1] {
+				case "BSSID":
+					info.BSSID = m[2]
+				case "IP":
+					info.IP, _ = netip.ParseAddr(strings.TrimPrefix(m[2], "/"))
+				case "RSSI":
+					info.RSSI, _ = strconv.Atoi(m[2])
+				case "Supplicant state":
+					state = m[2]
+				}
+			}
+			if ssid == "<unknown ssid>" || state != "COMPLETED" {
+				info = WifiInfo{Enabled: info.Enabled}
+				break
+			}
+			info.Connected = true
+			info.SSID = strings.TrimSuffix(strings.TrimPrefix(ssid, `"`), `"`)
+		}
+	}
+	if !found {
+		return WifiInfo{}, errors.New("adb dumpsys wifi: Wi-Fi state not found")
+	}
+	return info, nil
+}
@@ -0,0 +1,42 @@
+package gadb
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func Test_parseWifiInfo(t *testing.T) {
+	output := `Wi-Fi is enabled
+Verbose logging is off
+mWifiInfo SSID: "Office, 2nd floor", BSSID: aa:bb:cc:dd:ee:ff, MAC: 02:00:00:00:00:00, IP: /192.168.1.23, Security type: 2, Supplicant state: COMPLETED, Wi-Fi standard: 11ac, RSSI: -55, Link speed: 433Mbps, Frequency: 5180MHz
+mWifiInfo SSID: <unknown ssid>, BSSID: <none>, MAC: 02:00:00:00:00:00, Supplicant state: DISCONNECTED, RSSI: -127
+`
+	info, err := parseWifiInfo(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WifiInfo{Enabled: true, Connected: true, SSID: "Office, 2nd floor", BSSID: "aa:bb:cc:dd:ee:ff", RSSI: -55, IP: netip.MustParseAddr("192.168.1.23")}
+	if info != want {
+		t.Fatalf("got %+v, want %+v", info, want)
+	}
+
+	// Android 9 prints the SSID without quotes and no IP.
+	info, err = parseWifiInfo("Wi-Fi is enabled\nmWifiInfo SSID: Home, BSSID: 11:22:33:44:55:66, MAC: 02:00:00:00:00:00, Supplicant state: COMPLETED, RSSI: -61, Link speed: 72Mbps\n")
+	if err != nil || !info.Connected || info.SSID != "Home" || info.RSSI != -61 || info.IP.IsValid() {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+
+	info, err = parseWifiInfo("Wi-Fi is enabled\nmWifiInfo SSID: <unknown ssid>, BSSID: <none>, MAC: 02:00:00:00:00:00, Supplicant state: SCANNING, RSSI: -127\n")
+	if err != nil || info != (WifiInfo{Enabled: true}) {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+
+	info, err = parseWifiInfo("Wi-Fi is disabled\n")
+	if err != nil || info != (WifiInfo{}) {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+
+	if _, err = parseWifiInfo("Permission Denial\n"); err == nil {
+		t.Fatal("expected an error")
+	}
+}